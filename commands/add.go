@@ -2,18 +2,18 @@ package commands
 
 import (
 	"context"
-	"net"
+	"errors"
 	"time"
 
-	samqp "github.com/streadway/amqp"
-
-	"github.com/ipfs-search/ipfs-search/components/queue/amqp"
+	"github.com/ipfs-search/ipfs-search/components/queue"
+	"github.com/ipfs-search/ipfs-search/components/queue/factory"
 	"github.com/ipfs-search/ipfs-search/config"
 	"github.com/ipfs-search/ipfs-search/instr"
 	t "github.com/ipfs-search/ipfs-search/types"
-	"github.com/ipfs-search/ipfs-search/utils"
 )
 
+var errMemoryBackend = errors.New("in-memory queues are not shared between processes; adding requires the amqp or redis backend")
+
 // AddHash queues a single IPFS hash for indexing
 func AddHash(ctx context.Context, cfg *config.Config, hash string) error {
 	instFlusher, err := instr.Install(cfg.InstrConfig(), "ipfs-crawler add")
@@ -22,29 +22,18 @@ func AddHash(ctx context.Context, cfg *config.Config, hash string) error {
 	}
 	defer instFlusher()
 
-	i := instr.New()
-
-	dialer := &utils.RetryingDialer{
-		Dialer: net.Dialer{
-			Timeout:   30 * time.Second,
-			KeepAlive: 30 * time.Second,
-			DualStack: false,
-		},
-		Context: ctx,
+	if cfg.Queues.Backend == queue.MemoryBackend {
+		return errMemoryBackend
 	}
 
-	amqpConfig := &samqp.Config{
-		Dial: dialer.Dial,
-	}
+	i := instr.New()
 
-	f := amqp.PublisherFactory{
-		Config:          cfg.AMQPConfig(),
-		Queue:           "hashes",
-		AMQPConfig:      amqpConfig,
-		Instrumentation: i,
+	f, err := factory.NewHashesPublisherFactory(ctx, cfg, i)
+	if err != nil {
+		return err
 	}
 
-	queue, err := f.NewPublisher(ctx)
+	publisher, err := f.NewPublisher(ctx)
 	if err != nil {
 		return err
 	}
@@ -60,5 +49,5 @@ func AddHash(ctx context.Context, cfg *config.Config, hash string) error {
 	}
 
 	// Add with highest priority, as this is supposed to be available
-	return queue.Publish(ctx, provider, 9)
+	return publisher.Publish(ctx, provider, 9)
 }
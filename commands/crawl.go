@@ -10,8 +10,9 @@ import (
 	"log"
 )
 
-// Crawl configures and initializes crawling
-func Crawl(ctx context.Context, cfg *config.Config) error {
+// Crawl configures and initializes crawling. With withSniffer, the sniffer and its DHT node are run in the same
+// process, allowing for a standalone deployment with the memory queue backend.
+func Crawl(ctx context.Context, cfg *config.Config, withSniffer bool) error {
 	instFlusher, err := instr.Install(cfg.InstrConfig(), "ipfs-crawler")
 	if err != nil {
 		log.Fatal(err)
@@ -30,6 +31,11 @@ func Crawl(ctx context.Context, cfg *config.Config) error {
 
 	c.Start(ctx)
 
+	if withSniffer {
+		// Context closure, panic or sniffer failure are the only ways to stop crawling
+		return sniff(ctx, cfg, i, "")
+	}
+
 	// Context closure or panic is the only way to stop crawling
 	<-ctx.Done()
 
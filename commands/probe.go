@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/olivere/elastic/v7"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+	"github.com/ipfs-search/ipfs-search/components/index/elasticsearch"
+	"github.com/ipfs-search/ipfs-search/components/prober"
+	"github.com/ipfs-search/ipfs-search/components/protocol/ipfs"
+	"github.com/ipfs-search/ipfs-search/config"
+	"github.com/ipfs-search/ipfs-search/instr"
+	"github.com/ipfs-search/ipfs-search/utils"
+)
+
+// Probe configures and initializes periodic probing of the availability of indexed documents.
+func Probe(ctx context.Context, cfg *config.Config) error {
+	instFlusher, err := instr.Install(cfg.InstrConfig(), "ipfs-search probe")
+	if err != nil {
+		return err
+	}
+	defer instFlusher()
+
+	i := instr.New()
+
+	ctx, span := i.Tracer.Start(ctx, "commands.Probe")
+	defer span.End()
+
+	dialer := &utils.RetryingDialer{
+		Dialer: net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+			DualStack: false,
+		},
+		Context: ctx,
+	}
+
+	esClient, err := elastic.NewClient(
+		elastic.SetSniff(false),
+		elastic.SetURL(cfg.ElasticSearch.URL),
+		elastic.SetHttpClient(utils.GetHTTPClient(dialer.DialContext, 5)),
+	)
+	if err != nil {
+		return err
+	}
+
+	indexes := []index.ScanIndex{
+		elasticsearch.New(
+			esClient,
+			&elasticsearch.Config{Name: cfg.Indexes.Files.Name},
+			i,
+		),
+		elasticsearch.New(
+			esClient,
+			&elasticsearch.Config{Name: cfg.Indexes.Directories.Name},
+			i,
+		),
+	}
+
+	// One connection per worker; finding providers is slow.
+	ipfsClient := utils.GetHTTPClient(dialer.DialContext, int(cfg.Prober.Workers))
+	protocol := ipfs.New(cfg.IPFSConfig(), ipfsClient, i)
+
+	p := prober.New(cfg.ProberConfig(), indexes, protocol, i)
+
+	// Context closure or panic is the only way to stop probing
+	return p.Probe(ctx)
+}
@@ -0,0 +1,89 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+
+	"github.com/ipfs-search/ipfs-search/components/queue/factory"
+	"github.com/ipfs-search/ipfs-search/components/sniffer"
+	"github.com/ipfs-search/ipfs-search/components/sniffer/node"
+	"github.com/ipfs-search/ipfs-search/components/sniffer/recorder"
+	"github.com/ipfs-search/ipfs-search/config"
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+// newSniffer returns a new Sniffer, publishing to the hashes queue, with an in-memory datastore.
+func newSniffer(ctx context.Context, cfg *config.Config, i *instr.Instrumentation) (*sniffer.Sniffer, error) {
+	f, err := factory.NewHashesPublisherFactory(ctx, cfg, i)
+	if err != nil {
+		return nil, err
+	}
+
+	// Provider records are only of interest while being put; they needn't survive a restart.
+	ds := dssync.MutexWrap(datastore.NewMapDatastore())
+
+	return sniffer.New(cfg.SnifferConfig(), ds, f, i)
+}
+
+// Sniff starts an embedded DHT node and queues the resources provided to it for crawling. When recordPath is not
+// empty, sniffed events are recorded to it.
+func Sniff(ctx context.Context, cfg *config.Config, recordPath string) error {
+	instFlusher, err := instr.Install(cfg.InstrConfig(), "ipfs-search sniff")
+	if err != nil {
+		return err
+	}
+	defer instFlusher()
+
+	return sniff(ctx, cfg, instr.New(), recordPath)
+}
+
+func sniff(ctx context.Context, cfg *config.Config, i *instr.Instrumentation, recordPath string) error {
+	s, err := newSniffer(ctx, cfg, i)
+	if err != nil {
+		return err
+	}
+
+	if recordPath != "" {
+		r, err := recorder.Create(recordPath)
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+
+		s.Record(r)
+	}
+
+	n, err := node.Start(ctx, cfg.SnifferNodeConfig(), s.Batching(), s.ProviderStore)
+	if err != nil {
+		return err
+	}
+	defer n.Close()
+
+	// Context closure is the only way to stop sniffing
+	return s.Sniff(ctx)
+}
+
+// ReplaySniffed feeds the events recorded at recordPath through the sniffer's filters and queues them, at speed times
+// the recorded rate or as fast as possible with a speed of 0.
+func ReplaySniffed(ctx context.Context, cfg *config.Config, recordPath string, speed float64) error {
+	instFlusher, err := instr.Install(cfg.InstrConfig(), "ipfs-search sniffer replay")
+	if err != nil {
+		return err
+	}
+	defer instFlusher()
+
+	s, err := newSniffer(ctx, cfg, instr.New())
+	if err != nil {
+		return err
+	}
+
+	r, err := recorder.Open(recordPath)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	return s.Replay(ctx, r, speed)
+}
@@ -11,6 +11,8 @@ type Config struct {
 	StatTimeout        time.Duration // Timeout for Stat() calls.
 	DirEntryTimeout    time.Duration // Timeout *between* directory entries.
 	MaxDirSize         uint          // Maximum number of directory entries
+	CursorInterval     uint          // Number of directory entries in between checkpoints of listing progress.
+	MaxProviders       uint          // Maximum number of providers to store per item.
 }
 
 // DefaultConfig generates a default configuration for a Crawler.
@@ -21,5 +23,7 @@ func DefaultConfig() *Config {
 		StatTimeout:        60 * time.Second,
 		DirEntryTimeout:    60 * time.Second,
 		MaxDirSize:         32768,
+		CursorInterval:     1024,
+		MaxProviders:       16,
 	}
 }
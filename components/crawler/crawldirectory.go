@@ -11,13 +11,11 @@ import (
 	"go.opentelemetry.io/otel/codes"
 
 	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+	"github.com/ipfs-search/ipfs-search/components/queue"
 	t "github.com/ipfs-search/ipfs-search/types"
 )
 
 var (
-	// ErrDirectoryTooLarge is returned by Ls() when a directory is larger `Config.MaxDirSize`.
-	ErrDirectoryTooLarge = t.WrappedError{Err: t.ErrInvalidResource, Msg: "directory too large"}
-
 	// errEndOfLs is an internal error to communicate the end of hte list from processNextDirEntry to processDirEntries.
 	errEndOfLs = errors.New("end of list")
 )
@@ -26,20 +24,43 @@ func (c *Crawler) crawlDir(ctx context.Context, r *t.AnnotatedResource, properti
 	ctx, span := c.Tracer.Start(ctx, "crawler.crawlDir")
 	defer span.End()
 
+	cursor, err := c.getCursor(ctx, r)
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return err
+	}
+
 	entries := make(chan *t.AnnotatedResource, c.config.DirEntryBufferSize)
 
-	wg, ctx := errgroup.WithContext(ctx)
+	wg, wgCtx := errgroup.WithContext(ctx)
+
+	// Listings are streamed; the latency of the backend is the time until the first entry.
+	ls := newStreamingCall()
 
 	wg.Go(func() error {
-		return c.processDirEntries(ctx, entries, properties)
+		return c.processDirEntries(wgCtx, entries, c.newLinkPager(r, properties), cursor, ls)
 	})
 
 	wg.Go(func() error {
 		defer close(entries)
-		return c.protocol.Ls(ctx, r, entries)
+
+		err := c.protocol.Ls(wgCtx, r, entries)
+		ls.observe(wgCtx, err)
+
+		return err
 	})
 
-	return wg.Wait()
+	if err := wg.Wait(); err != nil {
+		return err
+	}
+
+	// All entries have been processed; progress no longer needs to be tracked.
+	if err := cursor.clear(ctx); err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return err
+	}
+
+	return nil
 }
 
 func resourceToLinkType(r *t.AnnotatedResource) indexTypes.LinkType {
@@ -52,28 +73,30 @@ func resourceToLinkType(r *t.AnnotatedResource) indexTypes.LinkType {
 		return indexTypes.UnknownLinkType
 	case t.UnsupportedType:
 		return indexTypes.UnsupportedLinkType
+	case t.SymlinkType:
+		return indexTypes.SymlinkLinkType
 	default:
 		panic("unexpected type")
 	}
 }
 
-func addLink(e *t.AnnotatedResource, properties *indexTypes.Directory) {
-	properties.Links = append(properties.Links, indexTypes.Link{
-		Hash: e.ID,
-		Name: e.Reference.Name,
-		Size: e.Size,
-		Type: resourceToLinkType(e),
-	})
+func makeLink(e *t.AnnotatedResource) indexTypes.Link {
+	return indexTypes.Link{
+		Hash:   e.ID,
+		Name:   e.Reference.Name,
+		Size:   e.Size,
+		Type:   resourceToLinkType(e),
+		Target: e.Reference.Target,
+	}
 }
 
-func (c *Crawler) processDirEntries(ctx context.Context, entries <-chan *t.AnnotatedResource, properties *indexTypes.Directory) error {
+func (c *Crawler) processDirEntries(ctx context.Context, entries <-chan *t.AnnotatedResource, links *linkPager, cursor *dirCursor, ls *streamingCall) error {
 	ctx, span := c.Tracer.Start(ctx, "crawler.processDirEntries")
 	defer span.End()
 
-	var (
-		dirCnt  uint = 0
-		isLarge bool = false
-	)
+	var dirCnt uint = 0
+
+	pending := new(confirmations)
 
 	// Question: do we need a maximum entry cutoff point? E.g. 10^6 entries or something?
 	processNextDirEntry := func() error {
@@ -89,23 +112,38 @@ func (c *Crawler) processDirEntries(ctx context.Context, entries <-chan *t.Annot
 				return errEndOfLs
 			}
 
+			ls.observe(ctx, nil)
+
 			if dirCnt > 0 && dirCnt%1024 == 0 {
 				log.Printf("Processed %d directory entries in %v.", dirCnt, entry.Parent)
 				log.Printf("Latest entry: %v", entry)
 			}
 
-			// Only add to properties up to limit (preventing oversized directory entries) - but queue entries nonetheless.
 			if dirCnt == c.config.MaxDirSize {
 				span.AddEvent(ctx, "large-directory")
-				log.Printf("Directory %v is large, crawling entries but not directory itself.", entry.Parent)
-				isLarge = true
 			}
 
-			if !isLarge {
-				addLink(entry, properties)
+			if err := links.add(ctx, entry); err != nil {
+				return err
 			}
 
-			return c.queueDirEntry(ctx, entry)
+			if cursor.isProcessed(dirCnt) {
+				// Queued before by an interrupted listing.
+				return nil
+			}
+
+			if err := c.queueDirEntry(ctx, entry, pending); err != nil {
+				return err
+			}
+
+			if cursor.isCheckpoint(dirCnt) {
+				// Only record progress once the entries have been queued.
+				if err := pending.wait(ctx); err != nil {
+					return err
+				}
+			}
+
+			return cursor.advance(ctx, dirCnt)
 		}
 	}
 
@@ -118,11 +156,10 @@ func (c *Crawler) processDirEntries(ctx context.Context, entries <-chan *t.Annot
 	}
 
 	if errors.Is(err, errEndOfLs) {
-		// Normal exit of loop, reset error condition
-		err = nil
-
-		if isLarge {
-			err = ErrDirectoryTooLarge
+		// Normal exit of loop, index remaining links.
+		err = pending.wait(ctx)
+		if err == nil {
+			err = links.flush(ctx)
 		}
 	} else {
 		// Unknown error situation: fail hard
@@ -137,7 +174,41 @@ func (c *Crawler) processDirEntries(ctx context.Context, entries <-chan *t.Annot
 	return err
 }
 
-func (c *Crawler) queueDirEntry(ctx context.Context, r *t.AnnotatedResource) error {
+// confirmations collects publishes awaiting confirmation, so that queueing directory entries doesn't wait for every
+// single entry to be confirmed.
+type confirmations []func(context.Context) error
+
+// publish publishes r to q, asynchronously when supported.
+func (c *confirmations) publish(ctx context.Context, q queue.Publisher, r *t.AnnotatedResource, priority uint8) error {
+	a, ok := q.(queue.AsyncPublisher)
+	if !ok {
+		return q.Publish(ctx, r, priority)
+	}
+
+	wait, err := a.PublishAsync(ctx, r, priority)
+	if err != nil {
+		return err
+	}
+
+	*c = append(*c, wait)
+
+	return nil
+}
+
+// wait waits for all pending publishes to be confirmed.
+func (c *confirmations) wait(ctx context.Context) error {
+	for _, wait := range *c {
+		if err := wait(ctx); err != nil {
+			return err
+		}
+	}
+
+	*c = (*c)[:0]
+
+	return nil
+}
+
+func (c *Crawler) queueDirEntry(ctx context.Context, r *t.AnnotatedResource, pending *confirmations) error {
 	// Generate random lower priority for items in this directory
 	// Rationale; directories might have different availability but
 	// within a directory, items are likely to have similar availability.
@@ -147,16 +218,19 @@ func (c *Crawler) queueDirEntry(ctx context.Context, r *t.AnnotatedResource) err
 
 	switch r.Type {
 	case t.UndefinedType:
-		return c.queues.Hashes.Publish(ctx, r, priority)
+		return pending.publish(ctx, c.queues.Hashes, r, priority)
 	case t.FileType:
-		return c.queues.Files.Publish(ctx, r, priority)
+		return pending.publish(ctx, c.queues.Files, r, priority)
 	case t.DirectoryType:
-		return c.queues.Directories.Publish(ctx, r, priority)
+		return pending.publish(ctx, c.queues.Directories, r, priority)
 	case t.UnsupportedType:
 		// Index right away as invalid.
 		// Rationale: as no additional protocol request is required and queue'ing returns
 		// similarly fast as indexing.
 		return c.indexInvalid(ctx, r, t.ErrUnsupportedType)
+	case t.SymlinkType:
+		// Symlinks have no content of their own; they are indexed as links of their directory.
+		return nil
 	default:
 		panic("unexpected type")
 	}
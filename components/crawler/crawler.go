@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"log"
+	"time"
 
 	"go.opentelemetry.io/otel/api/trace"
 	"go.opentelemetry.io/otel/codes"
@@ -115,7 +116,10 @@ func (c *Crawler) ensureType(ctx context.Context, r *t.AnnotatedResource) error
 		ctx, cancel := context.WithTimeout(ctx, c.config.StatTimeout)
 		defer cancel()
 
+		start := time.Now()
 		err = c.protocol.Stat(ctx, r)
+		observe(ctx, start, err)
+
 		if err != nil {
 			span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
 		}
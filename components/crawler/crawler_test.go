@@ -17,6 +17,7 @@ import (
 
 	"github.com/ipfs-search/ipfs-search/instr"
 	t "github.com/ipfs-search/ipfs-search/types"
+	"github.com/ipfs-search/ipfs-search/utils/limit"
 )
 
 type CrawlerTestSuite struct {
@@ -34,7 +35,9 @@ type CrawlerTestSuite struct {
 
 	fileIdx    *index.Mock
 	dirIdx     *index.Mock
+	linkIdx    *index.Mock
 	invalidIdx *index.Mock
+	cursorIdx  *index.Mock
 
 	dirQ  *queue.Mock
 	fileQ *queue.Mock
@@ -45,12 +48,15 @@ func (s *CrawlerTestSuite) SetupTest() {
 	s.ctx = context.Background()
 
 	// Creat a crawler with mocked dependencies
-	s.fileIdx, s.dirIdx, s.invalidIdx = &index.Mock{}, &index.Mock{}, &index.Mock{}
+	s.fileIdx, s.dirIdx, s.linkIdx = &index.Mock{}, &index.Mock{}, &index.Mock{}
+	s.invalidIdx, s.cursorIdx = &index.Mock{}, &index.Mock{}
 
 	s.indexes = &Indexes{
-		Files:       s.fileIdx,
-		Directories: s.dirIdx,
-		Invalids:    s.invalidIdx,
+		Files:          s.fileIdx,
+		Directories:    s.dirIdx,
+		DirectoryLinks: s.linkIdx,
+		Invalids:       s.invalidIdx,
+		Cursors:        s.cursorIdx,
 	}
 
 	s.fileQ, s.dirQ, s.hashQ = &queue.Mock{}, &queue.Mock{}, &queue.Mock{}
@@ -74,7 +80,9 @@ func (s *CrawlerTestSuite) assertExpectations() {
 	mock.AssertExpectationsForObjects(s.T(),
 		s.fileIdx,
 		s.dirIdx,
+		s.linkIdx,
 		s.invalidIdx,
+		s.cursorIdx,
 		s.fileQ,
 		s.dirQ,
 		s.hashQ,
@@ -85,17 +93,24 @@ func (s *CrawlerTestSuite) assertExpectations() {
 
 func (s *CrawlerTestSuite) assertNotExists(rID string) {
 	s.fileIdx.
-		On("Get", mock.Anything, rID, &indexTypes.Update{}, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, rID, &indexTypes.Update{}, []string{"references", "last-seen", "providers"}).
 		Return(false, nil).
 		Once()
 
 	s.dirIdx.
-		On("Get", mock.Anything, rID, &indexTypes.Update{}, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, rID, &indexTypes.Update{}, []string{"references", "last-seen", "providers"}).
 		Return(false, nil).
 		Once()
 
 	s.invalidIdx.
-		On("Get", mock.Anything, rID, &indexTypes.Update{}, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, rID, &indexTypes.Update{}, []string{"references", "last-seen", "providers"}).
+		Return(false, nil).
+		Once()
+}
+
+func (s *CrawlerTestSuite) assertNoCursor(rID string) {
+	s.cursorIdx.
+		On("Get", mock.Anything, rID, &indexTypes.Cursor{}, []string{"entries", "last-seen"}).
 		Return(false, nil).
 		Once()
 }
@@ -288,6 +303,220 @@ func (s *CrawlerTestSuite) TestCrawlPartialType() {
 	s.assertExpectations()
 }
 
+func (s *CrawlerTestSuite) TestCrawlSymlinkType() {
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Stat: t.Stat{
+			Type: t.UndefinedType,
+		},
+	}
+
+	s.protocol.
+		On("Stat", mock.Anything, r).
+		Run(func(args mock.Arguments) {
+			r := args.Get(1).(*t.AnnotatedResource)
+			r.Stat = t.Stat{
+				Type: t.SymlinkType,
+			}
+		}).
+		Return(nil).
+		Once()
+
+	s.invalidIdx.
+		On("Index", mock.Anything, r.Resource.ID, &indexTypes.Invalid{
+			Error: t.ErrSymlink.Error(),
+		}).
+		Return(nil).
+		Once()
+
+	s.assertNotExists(r.Resource.ID)
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Unreferenced symlinks should be recorded as invalid, preventing repeated Stat calls.
+	s.NoError(err)
+	s.assertExpectations()
+}
+
+func (s *CrawlerTestSuite) symlinkResource() *t.AnnotatedResource {
+	return &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv",
+		},
+		Reference: t.Reference{
+			Parent: &t.Resource{
+				Protocol: t.IPFSProtocol,
+				ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+			},
+			Name: "index.html",
+		},
+		Stat: t.Stat{
+			Type: t.UndefinedType,
+		},
+	}
+}
+
+func (s *CrawlerTestSuite) expectSymlinkStat(r *t.AnnotatedResource, target string) {
+	s.protocol.
+		On("Stat", mock.Anything, r).
+		Run(func(args mock.Arguments) {
+			r := args.Get(1).(*t.AnnotatedResource)
+			r.Stat = t.Stat{
+				Type: t.SymlinkType,
+				Size: 9,
+			}
+			r.Reference.Target = target
+		}).
+		Return(nil).
+		Once()
+
+	s.invalidIdx.
+		On("Index", mock.Anything, r.Resource.ID, &indexTypes.Invalid{
+			Error: t.ErrSymlink.Error(),
+		}).
+		Return(nil).
+		Once()
+
+	s.assertNotExists(r.Resource.ID)
+}
+
+func (s *CrawlerTestSuite) TestCrawlReferencedSymlink() {
+	r := s.symlinkResource()
+	parentID := r.Reference.Parent.ID
+	target := "/ipfs/" + parentID + "/home.html"
+
+	other := indexTypes.Link{
+		Hash: "QmbWqxBEKC3P8tqsKc98xmWNzrzDtRLMiMPL8wBuTGsMnR",
+		Name: "home.html",
+		Size: 12,
+		Type: indexTypes.FileLinkType,
+	}
+
+	s.expectSymlinkStat(r, target)
+
+	s.dirIdx.
+		On("Get", mock.Anything, parentID, &linksUpdate{}, []string{"links", "link_pages"}).
+		Run(func(args mock.Arguments) {
+			u := args.Get(2).(*linksUpdate)
+			u.Links = indexTypes.Links{
+				other,
+				{
+					Hash: r.ID,
+					Name: r.Reference.Name,
+					Size: 9,
+					Type: indexTypes.UnknownLinkType,
+				},
+			}
+		}).
+		Return(true, nil).
+		Once()
+
+	s.dirIdx.
+		On("Update", mock.Anything, parentID, &linksUpdate{
+			Links: indexTypes.Links{
+				other,
+				{
+					Hash:   r.ID,
+					Name:   r.Reference.Name,
+					Size:   9,
+					Type:   indexTypes.SymlinkLinkType,
+					Target: target,
+				},
+			},
+		}).
+		Return(nil).
+		Once()
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// The link in the parent should be updated and the symlink recorded as invalid.
+	s.NoError(err)
+	s.assertExpectations()
+}
+
+func (s *CrawlerTestSuite) TestCrawlPagedSymlink() {
+	r := s.symlinkResource()
+	parentID := r.Reference.Parent.ID
+	target := "/ipfs/" + parentID + "/home.html"
+
+	s.expectSymlinkStat(r, target)
+
+	s.dirIdx.
+		On("Get", mock.Anything, parentID, &linksUpdate{}, []string{"links", "link_pages"}).
+		Run(func(args mock.Arguments) {
+			u := args.Get(2).(*linksUpdate)
+			u.LinkPages = 2
+		}).
+		Return(true, nil).
+		Once()
+
+	s.linkIdx.
+		On("Get", mock.Anything, pageID(parentID, 1), &linksUpdate{}, []string{"links"}).
+		Return(true, nil).
+		Once()
+
+	s.linkIdx.
+		On("Get", mock.Anything, pageID(parentID, 2), &linksUpdate{}, []string{"links"}).
+		Run(func(args mock.Arguments) {
+			u := args.Get(2).(*linksUpdate)
+			u.Links = indexTypes.Links{
+				{
+					Hash: r.ID,
+					Name: r.Reference.Name,
+					Type: indexTypes.UnknownLinkType,
+				},
+			}
+		}).
+		Return(true, nil).
+		Once()
+
+	s.linkIdx.
+		On("Update", mock.Anything, pageID(parentID, 2), &linksUpdate{
+			Links: indexTypes.Links{
+				{
+					Hash:   r.ID,
+					Name:   r.Reference.Name,
+					Type:   indexTypes.SymlinkLinkType,
+					Target: target,
+				},
+			},
+		}).
+		Return(nil).
+		Once()
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Links in pages should be updated as well.
+	s.NoError(err)
+	s.assertExpectations()
+}
+
+func (s *CrawlerTestSuite) TestCrawlSymlinkParentNotFound() {
+	r := s.symlinkResource()
+
+	s.expectSymlinkStat(r, "/ipfs/"+r.Reference.Parent.ID+"/home.html")
+
+	s.dirIdx.
+		On("Get", mock.Anything, r.Reference.Parent.ID, &linksUpdate{}, []string{"links", "link_pages"}).
+		Return(false, nil).
+		Once()
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Without a parent, the symlink is still recorded.
+	s.NoError(err)
+	s.assertExpectations()
+}
+
 func (s *CrawlerTestSuite) TestCrawlFileType() {
 	// Prepare resource
 	r := &t.AnnotatedResource{
@@ -333,6 +562,53 @@ func (s *CrawlerTestSuite) TestCrawlFileType() {
 	s.assertExpectations()
 }
 
+func (s *CrawlerTestSuite) TestCrawlFileProvider() {
+	provided := time.Date(2020, 10, 1, 12, 30, 15, 500, time.UTC)
+
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Stat: t.Stat{
+			Type: t.FileType,
+			Size: 15,
+		},
+		Source: t.Source{
+			Provider:     "QmeTtFXm42Jb2todcKR538j6qHYxXt6suUzpF3rtT9FPSd",
+			LastProvided: provided,
+		},
+	}
+
+	// Mock assertions
+	s.extractor.
+		On("Extract", mock.Anything, r, mock.Anything).
+		Return(nil).
+		Once()
+
+	s.fileIdx.
+		On("Index", mock.Anything, r.Resource.ID, mock.MatchedBy(func(f *indexTypes.File) bool {
+			return s.Equal(indexTypes.Providers{
+				{
+					PeerID:   "QmeTtFXm42Jb2todcKR538j6qHYxXt6suUzpF3rtT9FPSd",
+					LastSeen: provided.Truncate(time.Second),
+				},
+			}, f.Providers)
+		})).
+		Return(nil).
+		Once()
+
+	s.assertNotExists(r.Resource.ID)
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects
+	s.NoError(err)
+	s.assertExpectations()
+}
+
 func (s *CrawlerTestSuite) TestCrawlLargeFile() {
 	// Prepare resource
 	r := &t.AnnotatedResource{
@@ -389,13 +665,40 @@ func (s *CrawlerTestSuite) TestCrawlStatTimeout() {
 
 	s.assertNotExists(r.Resource.ID)
 
+	o := new(observer)
+
 	// Crawl
-	err := s.c.Crawl(s.ctx, r)
+	err := s.c.Crawl(limit.WithObserver(s.ctx, o), r)
 
 	s.Equal(err, context.DeadlineExceeded)
+	s.Equal([]error{context.DeadlineExceeded}, o.errs)
 	s.assertExpectations()
 }
 
+// observer records the errors of observed backend calls.
+type observer struct {
+	errs []error
+}
+
+func (o *observer) Observe(latency time.Duration, err error) {
+	o.errs = append(o.errs, err)
+}
+
+// TestObserve tests whether calls failing on the resource, rather than the backend, are not observed.
+func (s *CrawlerTestSuite) TestObserve() {
+	o := new(observer)
+	ctx := limit.WithObserver(s.ctx, o)
+	backendErr := errors.New("backend")
+
+	observe(ctx, time.Now(), nil)
+	observe(ctx, time.Now(), fmt.Errorf("%w: not found", t.ErrInvalidResource))
+	observe(ctx, time.Now(), extractor.ErrFileTooLarge)
+	observe(ctx, time.Now(), context.Canceled)
+	observe(ctx, time.Now(), backendErr)
+
+	s.Equal([]error{nil, backendErr}, o.errs)
+}
+
 func (s *CrawlerTestSuite) TestCrawlReferencedFile() {
 	// Prepare resource
 	r := &t.AnnotatedResource{
@@ -480,6 +783,7 @@ func (s *CrawlerTestSuite) TestCrawlReferencedDirectory() {
 		Once()
 
 	s.assertNotExists(r.Resource.ID)
+	s.assertNoCursor(r.Resource.ID)
 
 	// Crawl
 	err := s.c.Crawl(s.ctx, r)
@@ -634,6 +938,7 @@ func (s *CrawlerTestSuite) TestCrawlDirectoryType() {
 		Once()
 
 	s.assertNotExists(r.Resource.ID)
+	s.assertNoCursor(r.Resource.ID)
 
 	// Crawl
 	err := s.c.Crawl(s.ctx, r)
@@ -680,11 +985,76 @@ func (s *CrawlerTestSuite) TestCrawlDirectoryUnexpectedType() {
 	s.Panics(func() { _ = s.c.Crawl(s.ctx, r) })
 }
 
+func (s *CrawlerTestSuite) TestCrawlDirectorySymlink() {
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Stat: t.Stat{
+			Type: t.DirectoryType,
+			Size: 23,
+		},
+	}
+
+	symlinkEntry := t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv",
+		},
+		Reference: t.Reference{
+			Parent: r.Resource,
+			Name:   "index.html",
+			Target: "/ipfs/QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp/home.html",
+		},
+		Stat: t.Stat{
+			Type: t.SymlinkType,
+			Size: 9,
+		},
+	}
+
+	s.protocol.
+		On("Ls", mock.Anything, r, mock.AnythingOfType("chan<- *types.AnnotatedResource")).
+		Run(func(args mock.Arguments) {
+			entryChan := args.Get(2).(chan<- *t.AnnotatedResource)
+			entryChan <- &symlinkEntry
+		}).
+		Return(nil).
+		Once()
+
+	// Note how symlinks are neither queued nor indexed as invalid.
+	s.dirIdx.
+		On("Index", mock.Anything, r.Resource.ID, mock.MatchedBy(func(f *indexTypes.Directory) bool {
+			return s.Equal(f.Links, indexTypes.Links{
+				indexTypes.Link{
+					Hash:   symlinkEntry.ID,
+					Name:   symlinkEntry.Reference.Name,
+					Size:   symlinkEntry.Size,
+					Type:   indexTypes.SymlinkLinkType,
+					Target: symlinkEntry.Reference.Target,
+				},
+			})
+		})).
+		Return(nil).
+		Once()
+
+	s.assertNotExists(r.Resource.ID)
+	s.assertNoCursor(r.Resource.ID)
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects
+	s.NoError(err)
+	s.assertExpectations()
+}
+
 func (s *CrawlerTestSuite) TestCrawlLargeDirectory() {
 	s.cfg = DefaultConfig()
 
 	// Override MaxDirSize
-	s.cfg.MaxDirSize = 3
+	s.cfg.MaxDirSize = 2
 
 	s.c = New(s.cfg, s.indexes, s.queues, s.protocol, s.extractor, s.instr)
 
@@ -733,13 +1103,41 @@ func (s *CrawlerTestSuite) TestCrawlLargeDirectory() {
 		Return(nil).
 		Once()
 
-	s.invalidIdx.
-		On("Index", mock.Anything, r.Resource.ID, mock.MatchedBy(func(f *indexTypes.Invalid) bool {
-			return s.Equal("directory too large", f.Error)
+	fileLink := indexTypes.Link{
+		Hash: fileEntry.ID,
+		Name: fileEntry.Reference.Name,
+		Size: fileEntry.Size,
+		Type: indexTypes.FileLinkType,
+	}
+
+	// First page of links is stored in the directory itself.
+	s.dirIdx.
+		On("Index", mock.Anything, r.Resource.ID, mock.MatchedBy(func(f *indexTypes.Directory) bool {
+			return s.Equal(indexTypes.Links{fileLink, fileLink}, f.Links) &&
+				s.Equal(uint(2), f.LinkPages)
 		})).
 		Return(nil).
 		Once()
 
+	// Remaining links are indexed in pages.
+	s.linkIdx.
+		On("Index", mock.Anything, r.Resource.ID+"-1", &indexTypes.LinkPage{
+			ParentHash: r.Resource.ID,
+			Page:       1,
+			Links:      indexTypes.Links{fileLink, fileLink},
+		}).
+		Return(nil).
+		Once()
+
+	s.linkIdx.
+		On("Index", mock.Anything, r.Resource.ID+"-2", &indexTypes.LinkPage{
+			ParentHash: r.Resource.ID,
+			Page:       2,
+			Links:      indexTypes.Links{fileLink},
+		}).
+		Return(nil).
+		Once()
+
 	s.fileQ.
 		On("Publish", mock.Anything, mock.MatchedBy(func(f *t.AnnotatedResource) bool {
 			return s.Equal(fileEntry, *f)
@@ -748,6 +1146,7 @@ func (s *CrawlerTestSuite) TestCrawlLargeDirectory() {
 		Times(5)
 
 	s.assertNotExists(r.Resource.ID)
+	s.assertNoCursor(r.Resource.ID)
 
 	// Crawl
 	err := s.c.Crawl(s.ctx, r)
@@ -833,6 +1232,7 @@ func (s *CrawlerTestSuite) TestCrawlDirEntryTimeout() {
 		Once()
 
 	s.assertNotExists(r.Resource.ID)
+	s.assertNoCursor(r.Resource.ID)
 
 	// Crawl
 	err := s.c.Crawl(s.ctx, r)
@@ -841,7 +1241,274 @@ func (s *CrawlerTestSuite) TestCrawlDirEntryTimeout() {
 	s.assertExpectations()
 }
 
-func (s *CrawlerTestSuite) TestCrawlUpdateLastSeen() {
+func (s *CrawlerTestSuite) TestCrawlDirectoryResume() {
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Stat: t.Stat{
+			Type: t.DirectoryType,
+		},
+	}
+
+	queuedEntry := t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmafrLBfzRLV4XSH1XcaMMeaXEUhDJjmtDfsYU95TrWG87",
+		},
+		Reference: t.Reference{
+			Parent: r.Resource,
+			Name:   "fileName.pdf",
+		},
+		Stat: t.Stat{
+			Type: t.FileType,
+			Size: 3431,
+		},
+	}
+
+	newEntry := t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv",
+		},
+		Reference: t.Reference{
+			Parent: r.Resource,
+			Name:   "dirName",
+		},
+		Stat: t.Stat{
+			Type: t.DirectoryType,
+			Size: 4534543,
+		},
+	}
+
+	// Previous listing got interrupted after queueing the first entry.
+	s.cursorIdx.
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Cursor{}, []string{"entries", "last-seen"}).
+		Run(func(args mock.Arguments) {
+			c := args.Get(2).(*indexTypes.Cursor)
+			c.Entries = 1
+		}).
+		Return(true, nil).
+		Once()
+
+	s.protocol.
+		On("Ls", mock.Anything, r, mock.AnythingOfType("chan<- *types.AnnotatedResource")).
+		Run(func(args mock.Arguments) {
+			entryChan := args.Get(2).(chan<- *t.AnnotatedResource)
+			entryChan <- &queuedEntry
+			entryChan <- &newEntry
+		}).
+		Return(nil).
+		Once()
+
+	// Only the new entry is queued, but both are linked.
+	s.dirQ.
+		On("Publish", mock.Anything, mock.MatchedBy(func(f *t.AnnotatedResource) bool {
+			return s.Equal(newEntry, *f)
+		}), mock.AnythingOfType("uint8")).
+		Return(nil).
+		Once()
+
+	s.dirIdx.
+		On("Index", mock.Anything, r.Resource.ID, mock.MatchedBy(func(f *indexTypes.Directory) bool {
+			return s.Len(f.Links, 2)
+		})).
+		Return(nil).
+		Once()
+
+	// Cursor is removed after completion.
+	s.cursorIdx.
+		On("Delete", mock.Anything, r.Resource.ID).
+		Return(nil).
+		Once()
+
+	s.assertNotExists(r.Resource.ID)
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects
+	s.NoError(err)
+	s.assertExpectations()
+}
+
+func (s *CrawlerTestSuite) TestCrawlDirectoryCheckpoint() {
+	s.cfg = DefaultConfig()
+
+	// Override CursorInterval
+	s.cfg.CursorInterval = 2
+
+	s.c = New(s.cfg, s.indexes, s.queues, s.protocol, s.extractor, s.instr)
+
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Stat: t.Stat{
+			Type: t.DirectoryType,
+		},
+	}
+
+	fileEntry := t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmafrLBfzRLV4XSH1XcaMMeaXEUhDJjmtDfsYU95TrWG87",
+		},
+		Reference: t.Reference{
+			Parent: r.Resource,
+			Name:   "fileName.pdf",
+		},
+		Stat: t.Stat{
+			Type: t.FileType,
+			Size: 3431,
+		},
+	}
+
+	s.protocol.
+		On("Ls", mock.Anything, r, mock.AnythingOfType("chan<- *types.AnnotatedResource")).
+		Run(func(args mock.Arguments) {
+			entryChan := args.Get(2).(chan<- *t.AnnotatedResource)
+			entryChan <- &fileEntry
+			entryChan <- &fileEntry
+			entryChan <- &fileEntry
+		}).
+		Return(nil).
+		Once()
+
+	s.fileQ.
+		On("Publish", mock.Anything, mock.MatchedBy(func(f *t.AnnotatedResource) bool {
+			return s.Equal(fileEntry, *f)
+		}), mock.AnythingOfType("uint8")).
+		Return(nil).
+		Times(3)
+
+	s.dirIdx.
+		On("Index", mock.Anything, r.Resource.ID, mock.IsType(&indexTypes.Directory{})).
+		Return(nil).
+		Once()
+
+	// Progress is stored after every 2 entries.
+	s.cursorIdx.
+		On("Index", mock.Anything, r.Resource.ID, mock.MatchedBy(func(c *indexTypes.Cursor) bool {
+			return s.Equal(uint(2), c.Entries)
+		})).
+		Return(nil).
+		Once()
+
+	s.cursorIdx.
+		On("Delete", mock.Anything, r.Resource.ID).
+		Return(nil).
+		Once()
+
+	s.assertNotExists(r.Resource.ID)
+	s.assertNoCursor(r.Resource.ID)
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects
+	s.NoError(err)
+	s.assertExpectations()
+}
+
+// asyncQueue is a queue which publishes asynchronously, counting publishes and confirmations.
+type asyncQueue struct {
+	queue.Mock
+	published, confirmed int
+}
+
+func (q *asyncQueue) PublishAsync(ctx context.Context, params interface{}, priority uint8) (func(context.Context) error, error) {
+	q.published++
+
+	return func(context.Context) error {
+		q.confirmed++
+		return nil
+	}, nil
+}
+
+func (s *CrawlerTestSuite) TestCrawlDirectoryAsyncPublish() {
+	s.cfg.CursorInterval = 2
+
+	fileQ := &asyncQueue{}
+	s.queues.Files = fileQ
+
+	s.c = New(s.cfg, s.indexes, s.queues, s.protocol, s.extractor, s.instr)
+
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Stat: t.Stat{
+			Type: t.DirectoryType,
+		},
+	}
+
+	fileEntry := t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmafrLBfzRLV4XSH1XcaMMeaXEUhDJjmtDfsYU95TrWG87",
+		},
+		Reference: t.Reference{
+			Parent: r.Resource,
+			Name:   "fileName.pdf",
+		},
+		Stat: t.Stat{
+			Type: t.FileType,
+			Size: 3431,
+		},
+	}
+
+	s.protocol.
+		On("Ls", mock.Anything, r, mock.AnythingOfType("chan<- *types.AnnotatedResource")).
+		Run(func(args mock.Arguments) {
+			entryChan := args.Get(2).(chan<- *t.AnnotatedResource)
+			entryChan <- &fileEntry
+			entryChan <- &fileEntry
+			entryChan <- &fileEntry
+		}).
+		Return(nil).
+		Once()
+
+	// Progress is only stored after the publishes have been confirmed.
+	s.cursorIdx.
+		On("Index", mock.Anything, r.Resource.ID, mock.MatchedBy(func(c *indexTypes.Cursor) bool {
+			return c.Entries == 2 && fileQ.confirmed == 2
+		})).
+		Return(nil).
+		Once()
+
+	// All publishes are confirmed before the directory is indexed.
+	s.dirIdx.
+		On("Index", mock.Anything, r.Resource.ID, mock.MatchedBy(func(*indexTypes.Directory) bool {
+			return fileQ.confirmed == 3
+		})).
+		Return(nil).
+		Once()
+
+	s.cursorIdx.
+		On("Delete", mock.Anything, r.Resource.ID).
+		Return(nil).
+		Once()
+
+	s.assertNotExists(r.Resource.ID)
+	s.assertNoCursor(r.Resource.ID)
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects
+	s.NoError(err)
+	s.Equal(3, fileQ.published)
+	s.assertExpectations()
+}
+
+func (s *CrawlerTestSuite) TestCrawlUpdateLastSeen() {
 	// Prepare resource
 	r := &t.AnnotatedResource{
 		Resource: &t.Resource{
@@ -852,7 +1519,7 @@ func (s *CrawlerTestSuite) TestCrawlUpdateLastSeen() {
 
 	// File is found, last seen 1 hour
 	s.fileIdx.
-		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen", "providers"}).
 		Run(func(args mock.Arguments) {
 			u := args.Get(2).(*indexTypes.Update)
 			u.LastSeen = time.Now().Add(-2 * time.Hour)
@@ -861,12 +1528,12 @@ func (s *CrawlerTestSuite) TestCrawlUpdateLastSeen() {
 		Once()
 
 	s.dirIdx.
-		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen", "providers"}).
 		Return(false, nil).
 		Maybe()
 
 	s.invalidIdx.
-		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen", "providers"}).
 		Return(false, nil).
 		Maybe()
 
@@ -897,17 +1564,17 @@ func (s *CrawlerTestSuite) TestCrawlNotUpdateInvalid() {
 
 	// File is found, last seen 1 hour
 	s.fileIdx.
-		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen", "providers"}).
 		Return(false, nil).
 		Once()
 
 	s.dirIdx.
-		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen", "providers"}).
 		Return(false, nil).
 		Maybe()
 
 	s.invalidIdx.
-		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen", "providers"}).
 		Return(true, nil).
 		Maybe()
 
@@ -937,7 +1604,7 @@ func (s *CrawlerTestSuite) TestCrawlAddReference() {
 
 	// File is found, very recently, but a new reference is found.
 	s.fileIdx.
-		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen", "providers"}).
 		Run(func(args mock.Arguments) {
 			u := args.Get(2).(*indexTypes.Update)
 			u.LastSeen = time.Now()
@@ -952,12 +1619,12 @@ func (s *CrawlerTestSuite) TestCrawlAddReference() {
 		Once()
 
 	s.dirIdx.
-		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen", "providers"}).
 		Return(false, nil).
 		Maybe()
 
 	s.invalidIdx.
-		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen", "providers"}).
 		Return(false, nil).
 		Maybe()
 
@@ -1005,17 +1672,17 @@ func (s *CrawlerTestSuite) TestCrawlUpdateGetError() {
 	testErr := errors.New("test")
 
 	s.fileIdx.
-		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen", "providers"}).
 		Return(false, testErr).
 		Maybe()
 
 	s.dirIdx.
-		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen", "providers"}).
 		Return(false, nil).
 		Maybe()
 
 	s.invalidIdx.
-		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen", "providers"}).
 		Return(false, nil).
 		Maybe()
 
@@ -1045,7 +1712,7 @@ func (s *CrawlerTestSuite) TestCrawlUpdateUpdateError() {
 
 	// File is found, very recently, but a new reference is found.
 	s.fileIdx.
-		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen", "providers"}).
 		Run(func(args mock.Arguments) {
 			u := args.Get(2).(*indexTypes.Update)
 			u.LastSeen = time.Now()
@@ -1062,12 +1729,12 @@ func (s *CrawlerTestSuite) TestCrawlUpdateUpdateError() {
 	testErr := errors.New("test")
 
 	s.dirIdx.
-		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen", "providers"}).
 		Return(false, nil).
 		Maybe()
 
 	s.invalidIdx.
-		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen", "providers"}).
 		Return(false, nil).
 		Maybe()
 
@@ -1114,7 +1781,7 @@ func (s *CrawlerTestSuite) TestCrawlSameReference() {
 
 	// File is found, very recently, but a new reference is found.
 	s.fileIdx.
-		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen", "providers"}).
 		Run(func(args mock.Arguments) {
 			u := args.Get(2).(*indexTypes.Update)
 			u.LastSeen = time.Now()
@@ -1129,12 +1796,118 @@ func (s *CrawlerTestSuite) TestCrawlSameReference() {
 		Once()
 
 	s.dirIdx.
-		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen", "providers"}).
+		Return(false, nil).
+		Maybe()
+
+	s.invalidIdx.
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen", "providers"}).
+		Return(false, nil).
+		Maybe()
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects
+	s.NoError(err)
+	s.assertExpectations()
+}
+
+func (s *CrawlerTestSuite) TestCrawlAddProvider() {
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Source: t.Source{
+			Provider:     "QmNewProvider",
+			LastProvided: time.Now(),
+		},
+	}
+
+	// Override MaxProviders
+	s.cfg.MaxProviders = 2
+	s.c = New(s.cfg, s.indexes, s.queues, s.protocol, s.extractor, s.instr)
+
+	recent := time.Now().Add(-time.Minute).UTC().Truncate(time.Second)
+	old := recent.Add(-time.Hour)
+
+	// File is found, very recently, but a new provider is found.
+	s.fileIdx.
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen", "providers"}).
+		Run(func(args mock.Arguments) {
+			u := args.Get(2).(*indexTypes.Update)
+			u.LastSeen = time.Now()
+			u.Providers = indexTypes.Providers{
+				{PeerID: "QmRecentProvider", LastSeen: recent},
+				{PeerID: "QmOldProvider", LastSeen: old},
+			}
+		}).
+		Return(true, nil).
+		Once()
+
+	s.dirIdx.
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen", "providers"}).
+		Return(false, nil).
+		Maybe()
+
+	s.invalidIdx.
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen", "providers"}).
+		Return(false, nil).
+		Maybe()
+
+	// The least recently seen provider is dropped.
+	s.fileIdx.
+		On("Update", mock.Anything, r.Resource.ID, mock.MatchedBy(func(u *indexTypes.Update) bool {
+			return s.Len(u.Providers, 2) &&
+				s.Equal("QmNewProvider", u.Providers[0].PeerID) &&
+				s.Equal(indexTypes.Provider{PeerID: "QmRecentProvider", LastSeen: recent}, u.Providers[1])
+		})).
+		Return(nil).
+		Once()
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects
+	s.NoError(err)
+	s.assertExpectations()
+}
+
+func (s *CrawlerTestSuite) TestCrawlKnownProvider() {
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Source: t.Source{
+			Provider:     "QmKnownProvider",
+			LastProvided: time.Now(),
+		},
+	}
+
+	// File is found, very recently, with the same provider.
+	s.fileIdx.
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen", "providers"}).
+		Run(func(args mock.Arguments) {
+			u := args.Get(2).(*indexTypes.Update)
+			u.LastSeen = time.Now()
+			u.Providers = indexTypes.Providers{
+				{PeerID: "QmKnownProvider", LastSeen: time.Now().Add(-time.Minute)},
+			}
+		}).
+		Return(true, nil).
+		Once()
+
+	s.dirIdx.
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen", "providers"}).
 		Return(false, nil).
 		Maybe()
 
 	s.invalidIdx.
-		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen", "providers"}).
 		Return(false, nil).
 		Maybe()
 
@@ -1164,7 +1937,7 @@ func (s *CrawlerTestSuite) TestCrawlSameReferenceDifferentName() {
 
 	// File is found, very recently, but a new reference is found.
 	s.fileIdx.
-		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen", "providers"}).
 		Run(func(args mock.Arguments) {
 			u := args.Get(2).(*indexTypes.Update)
 			u.LastSeen = time.Now()
@@ -1179,12 +1952,12 @@ func (s *CrawlerTestSuite) TestCrawlSameReferenceDifferentName() {
 		Once()
 
 	s.dirIdx.
-		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen", "providers"}).
 		Return(false, nil).
 		Maybe()
 
 	s.invalidIdx.
-		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen", "providers"}).
 		Return(false, nil).
 		Maybe()
 
@@ -1213,6 +1986,33 @@ func (s *CrawlerTestSuite) TestCrawlSameReferenceDifferentName() {
 	s.assertExpectations()
 }
 
+// TestUpdateNotExisting tests whether duplicates of resources which have not been indexed yet are not crawled.
+func (s *CrawlerTestSuite) TestUpdateNotExisting() {
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Reference: t.Reference{
+			Parent: &t.Resource{
+				Protocol: t.IPFSProtocol,
+				ID:       "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
+			},
+			Name: "NewReference.pdf",
+		},
+	}
+
+	s.assertNotExists(r.Resource.ID)
+
+	// Update
+	err := s.c.Update(s.ctx, r)
+
+	// Test result, side effects
+	s.NoError(err)
+	s.assertExpectations()
+}
+
 func TestCrawlerTestSuite(t *testing.T) {
 	suite.Run(t, new(CrawlerTestSuite))
 }
@@ -0,0 +1,105 @@
+package crawler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// dirCursor keeps track of the progress of listing a directory, allowing interrupted listings
+// to resume where they stopped. As directories are immutable, listings are deterministic.
+type dirCursor struct {
+	indexTypes.Cursor
+
+	id       string
+	stored   bool // Whether a cursor is present in the index.
+	interval uint
+	c        *Crawler
+}
+
+// getCursor returns the cursor for a directory, starting at 0 when none is stored.
+func (c *Crawler) getCursor(ctx context.Context, r *t.AnnotatedResource) (*dirCursor, error) {
+	ctx, span := c.Tracer.Start(ctx, "crawler.getCursor")
+	defer span.End()
+
+	cursor := &dirCursor{
+		id:       r.ID,
+		interval: c.config.CursorInterval,
+		c:        c,
+	}
+
+	found, err := c.indexes.Cursors.Get(ctx, r.ID, &cursor.Cursor, "entries", "last-seen")
+	if err != nil {
+		return nil, err
+	}
+
+	if found {
+		log.Printf("Resuming listing of %v after %d entries", r, cursor.Entries)
+		span.AddEvent(ctx, "resuming")
+		cursor.stored = true
+	}
+
+	return cursor, nil
+}
+
+// isProcessed returns true when the entry with index i has been processed before.
+func (d *dirCursor) isProcessed(i uint) bool {
+	return i < d.Entries
+}
+
+// isCheckpoint returns true when advancing past entry i checkpoints progress.
+func (d *dirCursor) isCheckpoint(i uint) bool {
+	return !d.isProcessed(i) && (i+1)%d.interval == 0
+}
+
+// advance records that entry i has been processed, checkpointing progress every interval entries.
+func (d *dirCursor) advance(ctx context.Context, i uint) error {
+	if d.isProcessed(i) {
+		return nil
+	}
+
+	checkpoint := d.isCheckpoint(i)
+
+	d.Entries = i + 1
+
+	if !checkpoint {
+		return nil
+	}
+
+	return d.checkpoint(ctx)
+}
+
+// checkpoint writes the cursor to the index.
+func (d *dirCursor) checkpoint(ctx context.Context) error {
+	ctx, span := d.c.Tracer.Start(ctx, "crawler.dirCursor.checkpoint")
+	defer span.End()
+
+	// Strip milliseconds to cater to legacy ES index format.
+	d.LastSeen = time.Now().UTC().Truncate(time.Second)
+
+	// Index a copy, as the cursor keeps advancing while the index might retain properties.
+	cursor := d.Cursor
+
+	if err := d.c.indexes.Cursors.Index(ctx, d.id, &cursor); err != nil {
+		return err
+	}
+
+	d.stored = true
+
+	return nil
+}
+
+// clear removes a stored cursor after a directory has been listed completely.
+func (d *dirCursor) clear(ctx context.Context) error {
+	if !d.stored {
+		return nil
+	}
+
+	ctx, span := d.c.Tracer.Start(ctx, "crawler.dirCursor.clear")
+	defer span.End()
+
+	return d.c.indexes.Cursors.Delete(ctx, d.id)
+}
@@ -19,7 +19,7 @@ func (c *Crawler) getExistingItem(ctx context.Context, r *t.AnnotatedResource) (
 
 	update := new(index_types.Update)
 
-	index, err := index.MultiGet(ctx, indexes, r.ID, update, "references", "last-seen")
+	index, err := index.MultiGet(ctx, indexes, r.ID, update, "references", "last-seen", "providers")
 	if err != nil {
 		return nil, err
 	}
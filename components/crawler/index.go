@@ -16,7 +16,7 @@ import (
 	t "github.com/ipfs-search/ipfs-search/types"
 )
 
-func makeDocument(r *t.AnnotatedResource) indexTypes.Document {
+func (c *Crawler) makeDocument(r *t.AnnotatedResource) indexTypes.Document {
 	now := time.Now().UTC()
 
 	// Strip milliseconds to cater to legacy ES index format.
@@ -33,12 +33,15 @@ func makeDocument(r *t.AnnotatedResource) indexTypes.Document {
 		}
 	}
 
+	providers, _ := c.appendProvider(nil, &r.Source)
+
 	// Common Document properties
 	return indexTypes.Document{
 		FirstSeen:  now,
 		LastSeen:   now,
 		References: references,
 		Size:       r.Size,
+		Providers:  providers,
 	}
 }
 
@@ -64,9 +67,12 @@ func (c *Crawler) index(ctx context.Context, r *t.AnnotatedResource) error {
 	switch r.Type {
 	case t.FileType:
 		f := &indexTypes.File{
-			Document: makeDocument(r),
+			Document: c.makeDocument(r),
 		}
+		start := time.Now()
 		err = c.extractor.Extract(ctx, r, f)
+		observe(ctx, start, err)
+
 		if errors.Is(err, extractor.ErrFileTooLarge) {
 			// Interpret files which are too large as invalid resources; prevent repeated attempts.
 			span.RecordError(ctx, err)
@@ -78,7 +84,7 @@ func (c *Crawler) index(ctx context.Context, r *t.AnnotatedResource) error {
 
 	case t.DirectoryType:
 		d := &indexTypes.Directory{
-			Document: makeDocument(r),
+			Document: c.makeDocument(r),
 		}
 		err = c.crawlDir(ctx, r, d)
 
@@ -95,6 +101,15 @@ func (c *Crawler) index(ctx context.Context, r *t.AnnotatedResource) error {
 		span.AddEvent(ctx, "partial")
 		return nil
 
+	case t.SymlinkType:
+		// Symlinks have no content of their own; they are indexed as links of their directory.
+		// Index them as invalid as well, preventing repeated Stat calls.
+		span.AddEvent(ctx, "symlink")
+		err = c.updateSymlink(ctx, r)
+		if err == nil {
+			err = t.ErrSymlink
+		}
+
 	case t.UndefinedType:
 		panic("undefined type after Stat call")
 
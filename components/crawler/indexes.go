@@ -6,7 +6,9 @@ import (
 
 // Indexes used for crawling.
 type Indexes struct {
-	Files       index.Index
-	Directories index.Index
-	Invalids    index.Index
+	Files          index.Index
+	Directories    index.Index
+	DirectoryLinks index.Index // Pages of links for large directories.
+	Invalids       index.Index
+	Cursors        index.Index // Progress of directory listings.
 }
@@ -0,0 +1,79 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// linkPager collects the links of a directory. The first `Config.MaxDirSize` links are stored in the
+// directory itself, subsequent links are indexed in pages of at most `Config.MaxDirSize` links.
+type linkPager struct {
+	c          *Crawler
+	parentID   string
+	properties *indexTypes.Directory
+	links      indexTypes.Links // Links for the current page.
+}
+
+func (c *Crawler) newLinkPager(r *t.AnnotatedResource, properties *indexTypes.Directory) *linkPager {
+	return &linkPager{
+		c:          c,
+		parentID:   r.ID,
+		properties: properties,
+	}
+}
+
+// pageID returns the document id for a page of links.
+func pageID(parentID string, page uint) string {
+	return fmt.Sprintf("%s-%d", parentID, page)
+}
+
+// add adds a link to an entry, indexing a page when it is full.
+func (p *linkPager) add(ctx context.Context, e *t.AnnotatedResource) error {
+	maxLinks := int(p.c.config.MaxDirSize)
+
+	if len(p.properties.Links) < maxLinks {
+		p.properties.Links = append(p.properties.Links, makeLink(e))
+		return nil
+	}
+
+	if p.properties.LinkPages == 0 && len(p.links) == 0 {
+		log.Printf("Directory %v is large, indexing links in pages.", e.Parent)
+	}
+
+	p.links = append(p.links, makeLink(e))
+
+	if len(p.links) == maxLinks {
+		return p.flush(ctx)
+	}
+
+	return nil
+}
+
+// flush indexes the current page of links, if any.
+func (p *linkPager) flush(ctx context.Context) error {
+	if len(p.links) == 0 {
+		return nil
+	}
+
+	ctx, span := p.c.Tracer.Start(ctx, "crawler.linkPager.flush")
+	defer span.End()
+
+	page := &indexTypes.LinkPage{
+		ParentHash: p.parentID,
+		Page:       p.properties.LinkPages + 1,
+		Links:      p.links,
+	}
+
+	if err := p.c.indexes.DirectoryLinks.Index(ctx, pageID(p.parentID, page.Page), page); err != nil {
+		return err
+	}
+
+	p.properties.LinkPages = page.Page
+	p.links = nil
+
+	return nil
+}
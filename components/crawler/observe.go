@@ -0,0 +1,37 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ipfs-search/ipfs-search/components/extractor"
+	t "github.com/ipfs-search/ipfs-search/types"
+	"github.com/ipfs-search/ipfs-search/utils/limit"
+)
+
+// observe passes the latency and error of a backend call to the Observer of ctx. Calls which failed on the resource,
+// e.g. as it is not found, or which were cancelled say nothing about the load on the backend and are skipped.
+func observe(ctx context.Context, start time.Time, err error) {
+	if errors.Is(err, t.ErrInvalidResource) || errors.Is(err, extractor.ErrFileTooLarge) ||
+		errors.Is(err, context.Canceled) {
+		return
+	}
+
+	limit.Observe(ctx, start, err)
+}
+
+// streamingCall observes a streaming backend call once, at its first result.
+type streamingCall struct {
+	start time.Time
+	once  sync.Once
+}
+
+func newStreamingCall() *streamingCall {
+	return &streamingCall{start: time.Now()}
+}
+
+func (c *streamingCall) observe(ctx context.Context, err error) {
+	c.once.Do(func() { observe(ctx, c.start, err) })
+}
@@ -0,0 +1,84 @@
+package crawler
+
+import (
+	"context"
+	"log"
+
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// linksUpdate represents the links of a Directory or LinkPage.
+type linksUpdate struct {
+	Links     indexTypes.Links `json:"links"`
+	LinkPages uint             `json:"link_pages,omitempty"`
+}
+
+// setSymlink sets type and target of the link to symlink `r`, returning whether it was found in `links`.
+func setSymlink(links indexTypes.Links, r *t.AnnotatedResource) bool {
+	for i := range links {
+		if links[i].Hash == r.ID && links[i].Name == r.Reference.Name {
+			links[i].Type = indexTypes.SymlinkLinkType
+			links[i].Target = r.Reference.Target
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// updateSymlink updates the link to a symlink in its parent directory, which is listed with an unknown type
+// as symlinks in normal directories are only recognised on Stat.
+func (c *Crawler) updateSymlink(ctx context.Context, r *t.AnnotatedResource) error {
+	if r.Reference.Parent == nil {
+		// No parent to update.
+		return nil
+	}
+
+	ctx, span := c.Tracer.Start(ctx, "crawler.updateSymlink")
+	defer span.End()
+
+	parentID := r.Reference.Parent.ID
+
+	dir := new(linksUpdate)
+	found, err := c.indexes.Directories.Get(ctx, parentID, dir, "links", "link_pages")
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return err
+	}
+
+	if !found {
+		// Directories are indexed after their entries have been queued, their listing might not be finished.
+		log.Printf("Parent of symlink %v not indexed, not updating link.", r)
+		span.AddEvent(ctx, "parent-not-found")
+		return nil
+	}
+
+	if setSymlink(dir.Links, r) {
+		return c.indexes.Directories.Update(ctx, parentID, &linksUpdate{Links: dir.Links})
+	}
+
+	for page := uint(1); page <= dir.LinkPages; page++ {
+		id := pageID(parentID, page)
+
+		p := new(linksUpdate)
+		found, err := c.indexes.DirectoryLinks.Get(ctx, id, p, "links")
+		if err != nil {
+			span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+			return err
+		}
+
+		if found && setSymlink(p.Links, r) {
+			return c.indexes.DirectoryLinks.Update(ctx, id, &linksUpdate{Links: p.Links})
+		}
+	}
+
+	log.Printf("Link to symlink %v not found in parent, not updating.", r)
+	span.AddEvent(ctx, "link-not-found")
+
+	return nil
+}
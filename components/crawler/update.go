@@ -4,6 +4,8 @@ import (
 	"context"
 	"time"
 
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/label"
 
 	index_types "github.com/ipfs-search/ipfs-search/components/index/types"
@@ -29,12 +31,31 @@ func appendReference(refs index_types.References, r *t.Reference) (index_types.R
 	}), true
 }
 
+// appendProvider adds the Source of a resource to providers, returning whether a new provider was added.
+func (c *Crawler) appendProvider(providers index_types.Providers, s *t.Source) (index_types.Providers, bool) {
+	if s.Provider == "" {
+		// No known provider, not updating
+		return providers, false
+	}
+
+	lastProvided := s.LastProvided
+	if lastProvided.IsZero() {
+		lastProvided = time.Now()
+	}
+
+	// Strip milliseconds to cater to legacy ES index format.
+	lastProvided = lastProvided.UTC().Truncate(time.Second)
+
+	return providers.Add(s.Provider, lastProvided, c.config.MaxProviders)
+}
+
 // updateExisting updates known existing items.
 func (c *Crawler) updateExisting(ctx context.Context, i *existingItem) error {
 	ctx, span := c.Tracer.Start(ctx, "crawler.updateExisting")
 	defer span.End()
 
 	refs, refsUpdated := appendReference(i.References, &i.AnnotatedResource.Reference)
+	providers, providersUpdated := c.appendProvider(i.Providers, &i.AnnotatedResource.Source)
 
 	now := time.Now()
 
@@ -44,7 +65,7 @@ func (c *Crawler) updateExisting(ctx context.Context, i *existingItem) error {
 
 	isRecent := now.Sub(i.LastSeen) > c.config.MinUpdateAge
 
-	if refsUpdated || isRecent {
+	if refsUpdated || providersUpdated || isRecent {
 		if span.IsRecording() {
 			var reason string
 
@@ -52,6 +73,10 @@ func (c *Crawler) updateExisting(ctx context.Context, i *existingItem) error {
 				reason = "reference-added"
 			}
 
+			if providersUpdated {
+				reason = "provider-added"
+			}
+
 			if isRecent {
 				reason = "is-recent"
 			}
@@ -65,6 +90,7 @@ func (c *Crawler) updateExisting(ctx context.Context, i *existingItem) error {
 		return i.Index.Update(ctx, i.AnnotatedResource.ID, &index_types.Update{
 			LastSeen:   now,
 			References: refs,
+			Providers:  providers,
 		})
 	} else {
 		span.AddEvent(ctx, "Not updating")
@@ -104,3 +130,17 @@ func (c *Crawler) updateMaybeExisting(ctx context.Context, r *t.AnnotatedResourc
 
 	return false, nil
 }
+
+// Update records the reference and provider of a resource which has been indexed before, without crawling it. It
+// satisfies dedup.Updater.
+func (c *Crawler) Update(ctx context.Context, r *t.AnnotatedResource) error {
+	ctx, span := c.Tracer.Start(ctx, "crawler.Update")
+	defer span.End()
+
+	_, err := c.updateMaybeExisting(ctx, r)
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+	}
+
+	return err
+}
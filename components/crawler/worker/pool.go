@@ -2,7 +2,6 @@ package worker
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
 	"net"
@@ -18,12 +17,19 @@ import (
 	"github.com/ipfs-search/ipfs-search/components/extractor/tika"
 	"github.com/ipfs-search/ipfs-search/components/index/elasticsearch"
 	"github.com/ipfs-search/ipfs-search/components/protocol/ipfs"
+	"github.com/ipfs-search/ipfs-search/components/queue"
 	"github.com/ipfs-search/ipfs-search/components/queue/amqp"
+	"github.com/ipfs-search/ipfs-search/components/queue/codec"
+	"github.com/ipfs-search/ipfs-search/components/queue/dedup"
+	"github.com/ipfs-search/ipfs-search/components/queue/memory"
+	"github.com/ipfs-search/ipfs-search/components/queue/redis"
 
 	"github.com/ipfs-search/ipfs-search/config"
 	"github.com/ipfs-search/ipfs-search/instr"
 	t "github.com/ipfs-search/ipfs-search/types"
 	"github.com/ipfs-search/ipfs-search/utils"
+	"github.com/ipfs-search/ipfs-search/utils/limit"
+	redisclient "github.com/ipfs-search/ipfs-search/utils/redis"
 )
 
 // Pool represents a pool of workers.
@@ -31,9 +37,14 @@ type Pool struct {
 	config       *config.Config
 	dialer       *utils.RetryingDialer
 	consumeChans struct {
-		Files       <-chan samqp.Delivery
-		Directories <-chan samqp.Delivery
-		Hashes      <-chan samqp.Delivery
+		Files       <-chan queue.Message
+		Directories <-chan queue.Message
+		Hashes      <-chan queue.Message
+	}
+	limiters struct {
+		Files       *limit.Limiter
+		Directories *limit.Limiter
+		Hashes      *limit.Limiter
 	}
 	crawler *crawler.Crawler
 
@@ -57,21 +68,42 @@ func (w *Pool) makeCrawler(ctx context.Context) error {
 		return err
 	}
 
+	ipfsAPI, err := limit.NewBackend("ipfs_api", w.config.Backends.IPFSAPI.LimitConfig())
+	if err != nil {
+		return err
+	}
+
+	tikaBackend, err := limit.NewBackend("tika", w.config.Backends.Tika.LimitConfig())
+	if err != nil {
+		return err
+	}
+
 	// Many stat/ls connections
-	ipfsClient := utils.GetHTTPClient(w.dialer.DialContext, 1000)
+	ipfsClient := utils.GetHTTPClient(w.dialer.DialContext, w.config.Backends.IPFSAPI.MaxConcurrent, ipfsAPI)
 	protocol := ipfs.New(w.config.IPFSConfig(), ipfsClient, w.Instrumentation)
 
-	// Limited Tika connections (as resources are generally known to be available by now)
-	tikaClient := utils.GetHTTPClient(w.dialer.DialContext, 100)
+	// Limited Tika connections (as resources are generally known to be available by now). Crawlers do not request the
+	// gateway themselves; ipfs-tika fetches every resource from it, which the tika limit bounds only indirectly.
+	tikaClient := utils.GetHTTPClient(w.dialer.DialContext, w.config.Backends.Tika.MaxConcurrent, tikaBackend)
 	extractor := tika.New(w.config.TikaConfig(), tikaClient, protocol, w.Instrumentation)
 
 	w.crawler = crawler.New(w.config.CrawlerConfig(), indexes, queues, protocol, extractor, w.Instrumentation)
 
+	// Record the references of duplicate directory entries right away, rather than crawling them again.
+	if d, ok := queues.Hashes.(*dedup.Queue); ok {
+		d.Updater = w.crawler
+	}
+
 	return nil
 }
 
 func (w *Pool) getElasticClient() (*elastic.Client, error) {
-	httpClient := utils.GetHTTPClient(w.dialer.DialContext, 5)
+	backend, err := limit.NewBackend("elasticsearch", w.config.Backends.ElasticSearch.LimitConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient := utils.GetHTTPClient(w.dialer.DialContext, w.config.Backends.ElasticSearch.MaxConcurrent, backend)
 
 	return elastic.NewClient(
 		elastic.SetSniff(false),
@@ -97,15 +129,113 @@ func (w *Pool) getIndexes(ctx context.Context) (*crawler.Indexes, error) {
 			&elasticsearch.Config{Name: w.config.Indexes.Directories.Name},
 			w.Instrumentation,
 		),
+		DirectoryLinks: elasticsearch.New(
+			esClient,
+			&elasticsearch.Config{Name: w.config.Indexes.DirectoryLinks.Name},
+			w.Instrumentation,
+		),
 		Invalids: elasticsearch.New(
 			esClient,
 			&elasticsearch.Config{Name: w.config.Indexes.Invalids.Name},
 			w.Instrumentation,
 		),
+		Cursors: elasticsearch.New(
+			esClient,
+			&elasticsearch.Config{Name: w.config.Indexes.Cursors.Name},
+			w.Instrumentation,
+		),
 	}, nil
 }
 
 func (w *Pool) getQueues(ctx context.Context) (*crawler.Queues, error) {
+	var (
+		queues *crawler.Queues
+		err    error
+	)
+
+	switch w.config.Queues.Backend {
+	case queue.AMQPBackend:
+		queues, err = w.getAMQPQueues(ctx)
+
+	case queue.MemoryBackend:
+		queues = w.getMemoryQueues()
+
+	case queue.RedisBackend:
+		queues, err = w.getRedisQueues(ctx)
+
+	default:
+		err = fmt.Errorf("%w: %s", queue.ErrUnknownBackend, w.config.Queues.Backend)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return queues, w.dedupHashes(queues)
+}
+
+// dedupHashes deduplicates the hashes queue, unless disabled.
+func (w *Pool) dedupHashes(queues *crawler.Queues) error {
+	name := w.config.Queues.Hashes.Name
+
+	set, err := dedup.NewSet(w.config.DedupConfig(), name)
+	if err != nil || set == nil {
+		return err
+	}
+
+	queues.Hashes = dedup.NewQueue(queues.Hashes, set, dedup.NewStats(name, w.Instrumentation))
+
+	return nil
+}
+
+// getMemoryQueues returns queues shared within the process, e.g. with an embedded sniffer.
+func (w *Pool) getMemoryQueues() *crawler.Queues {
+	b := memory.Default(w.config.MemoryQueueConfig())
+
+	return &crawler.Queues{
+		Files:       b.Queue(w.config.Queues.Files.Name),
+		Directories: b.Queue(w.config.Queues.Directories.Name),
+		Hashes:      b.Queue(w.config.Queues.Hashes.Name),
+	}
+}
+
+// getRedisQueues returns Redis Streams queues, each with its own connection as clients serialize commands.
+func (w *Pool) getRedisQueues(ctx context.Context) (*crawler.Queues, error) {
+	cfg := w.config.RedisQueueConfig()
+
+	newQueue := func(name string) (*redis.Queue, error) {
+		client, err := redisclient.New(cfg.URL, w.dialer.DialContext)
+		if err != nil {
+			return nil, err
+		}
+
+		return redis.New(ctx, client, name, cfg, w.Instrumentation)
+	}
+
+	log.Println("Creating Redis queues.")
+	fq, err := newQueue(w.config.Queues.Files.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	dq, err := newQueue(w.config.Queues.Directories.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	hq, err := newQueue(w.config.Queues.Hashes.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &crawler.Queues{
+		Files:       fq,
+		Directories: dq,
+		Hashes:      hq,
+	}, nil
+}
+
+func (w *Pool) getAMQPQueues(ctx context.Context) (*crawler.Queues, error) {
 	amqpConfig := &samqp.Config{
 		Dial: w.dialer.Dial,
 	}
@@ -117,17 +247,17 @@ func (w *Pool) getQueues(ctx context.Context) (*crawler.Queues, error) {
 	}
 
 	log.Println("Creating AMQP channels.")
-	fq, err := amqpConnection.NewChannelQueue(ctx, w.config.Queues.Files.Name, w.config.Workers.FileWorkers)
+	fq, err := amqpConnection.NewChannelQueue(ctx, w.config.Queues.Files.AMQPConfig(), w.config.Workers.FileWorkers)
 	if err != nil {
 		return nil, err
 	}
 
-	dq, err := amqpConnection.NewChannelQueue(ctx, w.config.Queues.Directories.Name, w.config.Workers.DirectoryWorkers)
+	dq, err := amqpConnection.NewChannelQueue(ctx, w.config.Queues.Directories.AMQPConfig(), w.config.Workers.DirectoryWorkers)
 	if err != nil {
 		return nil, err
 	}
 
-	hq, err := amqpConnection.NewChannelQueue(ctx, w.config.Queues.Hashes.Name, w.config.Workers.HashWorkers)
+	hq, err := amqpConnection.NewChannelQueue(ctx, w.config.Queues.Hashes.AMQPConfig(), w.config.Workers.HashWorkers)
 	if err != nil {
 		return nil, err
 	}
@@ -139,17 +269,17 @@ func (w *Pool) getQueues(ctx context.Context) (*crawler.Queues, error) {
 	}, nil
 }
 
-func (w *Pool) crawlDelivery(ctx context.Context, d samqp.Delivery) error {
-	// TODO: Get SpanContext from Delivery.
+func (w *Pool) crawlMessage(ctx context.Context, m queue.Message) error {
+	// TODO: Get SpanContext from Message.
 	// ctx = trace.ContextWithRemoteSpanContext(ctx, p.SpanContext)
-	ctx, span := w.Tracer.Start(ctx, "crawler.worker.crawlDelivery", trace.WithNewRoot())
+	ctx, span := w.Tracer.Start(ctx, "crawler.worker.crawlMessage", trace.WithNewRoot())
 	defer span.End()
 
 	r := &t.AnnotatedResource{
 		Resource: &t.Resource{},
 	}
 
-	if err := json.Unmarshal(d.Body, r); err != nil {
+	if err := codec.Unmarshal(m.ContentType(), m.Body(), r); err != nil {
 		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
 		return err
 	}
@@ -171,30 +301,50 @@ func (w *Pool) crawlDelivery(ctx context.Context, d samqp.Delivery) error {
 	return err
 }
 
-func (w *Pool) startWorker(ctx context.Context, deliveries <-chan samqp.Delivery, name string) {
+func (w *Pool) startWorker(ctx context.Context, messages <-chan queue.Message, limiter *limit.Limiter, name string) {
 	ctx, span := w.Tracer.Start(ctx, "crawler.worker.startWorker")
 	defer span.End()
 
+	// The limiter adapts to the backend calls made while crawling.
+	ctx = limit.WithObserver(ctx, limiter)
+
 	for {
+		// Workers beyond the limit wait here, without taking messages.
+		if err := limiter.Acquire(ctx); err != nil {
+			return
+		}
+
 		select {
 		case <-ctx.Done():
+			limiter.Release()
 			return
-		case d, ok := <-deliveries:
+		case m, ok := <-messages:
 			if !ok {
-				// This is a fatal error; it should never happen - crash the program!
+				limiter.Release()
+
+				if ctx.Err() != nil {
+					// Consuming stopped as the context closed.
+					return
+				}
+
+				// Consumers resubscribe after reconnecting; closing means giving up - crash the program!
 				panic("unexpected channel close")
 			}
-			if err := w.crawlDelivery(ctx, d); err != nil {
+
+			err := w.crawlMessage(ctx, m)
+			limiter.Release()
+
+			if err != nil {
 				// By default, do not retry.
 				shouldRetry := false
 
 				span.RecordError(ctx, err)
 
-				if err := d.Reject(shouldRetry); err != nil {
+				if err := m.Nack(shouldRetry); err != nil {
 					span.RecordError(ctx, err)
 				}
 			} else {
-				if err := d.Ack(false); err != nil {
+				if err := m.Ack(); err != nil {
 					span.RecordError(ctx, err)
 				}
 			}
@@ -202,13 +352,13 @@ func (w *Pool) startWorker(ctx context.Context, deliveries <-chan samqp.Delivery
 	}
 }
 
-func (w *Pool) startPool(ctx context.Context, deliveries <-chan samqp.Delivery, workers int, poolName string) {
+func (w *Pool) startPool(ctx context.Context, messages <-chan queue.Message, limiter *limit.Limiter, workers int, poolName string) {
 	ctx, span := w.Tracer.Start(ctx, "crawler.worker.startPool")
 	defer span.End()
 
 	for i := 0; i < workers; i++ {
 		name := fmt.Sprintf("%s-%d", poolName, i)
-		go w.startWorker(ctx, deliveries, name)
+		go w.startWorker(ctx, messages, limiter, name)
 	}
 }
 
@@ -218,13 +368,13 @@ func (w *Pool) Start(ctx context.Context) {
 	defer span.End()
 
 	log.Printf("Starting %d workers for files", w.config.Workers.FileWorkers)
-	w.startPool(ctx, w.consumeChans.Files, w.config.Workers.FileWorkers, "files")
+	w.startPool(ctx, w.consumeChans.Files, w.limiters.Files, w.config.Workers.FileWorkers, "files")
 
 	log.Printf("Starting %d workers for hashes", w.config.Workers.HashWorkers)
-	w.startPool(ctx, w.consumeChans.Hashes, w.config.Workers.HashWorkers, "hashes")
+	w.startPool(ctx, w.consumeChans.Hashes, w.limiters.Hashes, w.config.Workers.HashWorkers, "hashes")
 
 	log.Printf("Starting %d workers for directories", w.config.Workers.DirectoryWorkers)
-	w.startPool(ctx, w.consumeChans.Directories, w.config.Workers.DirectoryWorkers, "directories")
+	w.startPool(ctx, w.consumeChans.Directories, w.limiters.Directories, w.config.Workers.DirectoryWorkers, "directories")
 }
 
 func (w *Pool) makeConsumeChans(ctx context.Context) error {
@@ -249,6 +399,41 @@ func (w *Pool) makeConsumeChans(ctx context.Context) error {
 		return err
 	}
 
+	return w.makeLimiters(ctx, queues)
+}
+
+// newLimiter returns a Limiter for a pool of workers consuming from c, adjusting its prefetch along when supported.
+func (w *Pool) newLimiter(ctx context.Context, c queue.Consumer, workers int, poolName string) (*limit.Limiter, error) {
+	p, _ := c.(queue.Prefetcher)
+
+	return limit.New(w.config.ConcurrencyConfig(), workers, func(n int) {
+		log.Printf("Adjusting active workers for %s to %d", poolName, n)
+
+		if p == nil {
+			return
+		}
+
+		if err := p.SetPrefetch(ctx, n); err != nil {
+			log.Printf("Error adjusting prefetch for %s: %v", poolName, err)
+		}
+	})
+}
+
+func (w *Pool) makeLimiters(ctx context.Context, queues *crawler.Queues) error {
+	var err error
+
+	if w.limiters.Files, err = w.newLimiter(ctx, queues.Files, w.config.Workers.FileWorkers, "files"); err != nil {
+		return err
+	}
+
+	if w.limiters.Directories, err = w.newLimiter(ctx, queues.Directories, w.config.Workers.DirectoryWorkers, "directories"); err != nil {
+		return err
+	}
+
+	if w.limiters.Hashes, err = w.newLimiter(ctx, queues.Hashes, w.config.Workers.HashWorkers, "hashes"); err != nil {
+		return err
+	}
+
 	return nil
 }
 
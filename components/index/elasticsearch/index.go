@@ -3,6 +3,9 @@ package elasticsearch
 import (
 	"context"
 	"encoding/json"
+	"io"
+	"time"
+
 	"github.com/olivere/elastic/v7"
 
 	"go.opentelemetry.io/otel/api/trace"
@@ -12,6 +15,15 @@ import (
 	"github.com/ipfs-search/ipfs-search/instr"
 )
 
+const (
+	// scanBatchSize is the number of documents retrieved at once when scanning.
+	scanBatchSize = 1000
+
+	// scanKeepAlive is the time the scroll context is kept between batches. Consumers might take long
+	// to process a batch; e.g. probing 1000 documents for up to 30s each with 32 workers takes ~16m.
+	scanKeepAlive = "1h"
+)
+
 // Index wraps an Elasticsearch index to store documents
 type Index struct {
 	es  *elastic.Client
@@ -21,7 +33,7 @@ type Index struct {
 }
 
 // New returns a new index.
-func New(es *elastic.Client, cfg *Config, i *instr.Instrumentation) index.Index {
+func New(es *elastic.Client, cfg *Config, i *instr.Instrumentation) index.ScanIndex {
 	return &Index{
 		es:              es,
 		cfg:             cfg,
@@ -115,5 +127,73 @@ func (i *Index) Get(ctx context.Context, id string, dst interface{}, fields ...s
 	}
 }
 
+// Delete removes the document with `id` from the index. Deleting non-existing documents is not an error.
+func (i *Index) Delete(ctx context.Context, id string) error {
+	ctx, span := i.Tracer.Start(ctx, "index.elasticsearch.Delete")
+	defer span.End()
+
+	_, err := i.es.Delete().
+		Index(i.cfg.Name).
+		Id(id).
+		Do(ctx)
+
+	if elastic.IsNotFound(err) {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Ok))
+		return nil
+	}
+
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+	}
+
+	return err
+}
+
+// ScanBefore sends the id's of documents where the date in `field` is absent or before `before` to `ids`.
+func (i *Index) ScanBefore(ctx context.Context, field string, before time.Time, ids chan<- string) error {
+	ctx, span := i.Tracer.Start(ctx, "index.elasticsearch.ScanBefore")
+	defer span.End()
+
+	query := elastic.NewBoolQuery().
+		Should(
+			elastic.NewBoolQuery().MustNot(elastic.NewExistsQuery(field)),
+			elastic.NewRangeQuery(field).
+				Lt(before.UTC().Format(time.RFC3339)).
+				Format("strict_date_optional_time"),
+		).
+		MinimumNumberShouldMatch(1)
+
+	scroll := i.es.Scroll(i.cfg.Name).
+		Query(query).
+		FetchSource(false).
+		Size(scanBatchSize).
+		KeepAlive(scanKeepAlive)
+
+	// Release the scroll context, even when ctx has been cancelled.
+	defer scroll.Clear(context.Background())
+
+	for {
+		result, err := scroll.Do(ctx)
+
+		if err == io.EOF {
+			// No more results
+			return nil
+		}
+
+		if err != nil {
+			span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+			return err
+		}
+
+		for _, hit := range result.Hits.Hits {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case ids <- hit.Id:
+			}
+		}
+	}
+}
+
 // Compile-time assurance that implementation satisfies interface.
-var _ index.Index = &Index{}
+var _ index.ScanIndex = &Index{}
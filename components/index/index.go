@@ -10,4 +10,5 @@ type Index interface {
 	Index(ctx context.Context, id string, properties interface{}) error
 	Update(ctx context.Context, id string, properties interface{}) error
 	Get(ctx context.Context, id string, dst interface{}, fields ...string) (bool, error)
+	Delete(ctx context.Context, id string) error
 }
@@ -3,6 +3,7 @@ package index
 import (
 	"context"
 	"github.com/stretchr/testify/mock"
+	"time"
 )
 
 // Mock mocks the Index interface.
@@ -28,5 +29,17 @@ func (m *Mock) Get(ctx context.Context, id string, dst interface{}, fields ...st
 	return args.Bool(0), args.Error(1)
 }
 
+// Delete mocks the Delete method on the Index interface.
+func (m *Mock) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+// ScanBefore mocks the ScanBefore method on the Scanner interface.
+func (m *Mock) ScanBefore(ctx context.Context, field string, before time.Time, ids chan<- string) error {
+	args := m.Called(ctx, field, before, ids)
+	return args.Error(0)
+}
+
 // Compile-time assurance that implementation satisfies interface.
-var _ Index = &Mock{}
+var _ ScanIndex = &Mock{}
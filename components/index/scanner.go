@@ -0,0 +1,18 @@
+package index
+
+import (
+	"context"
+	"time"
+)
+
+// Scanner allows iterating over the documents in an index.
+type Scanner interface {
+	// ScanBefore sends the id's of documents where the date in `field` is absent or before `before` to `ids`.
+	ScanBefore(ctx context.Context, field string, before time.Time, ids chan<- string) error
+}
+
+// ScanIndex is an Index which allows scanning.
+type ScanIndex interface {
+	Index
+	Scanner
+}
@@ -0,0 +1,12 @@
+package types
+
+import (
+	"time"
+)
+
+// Availability represents the outcome of probing the providers of a Document.
+type Availability struct {
+	Providers     Providers `json:"providers"`
+	ProviderCount int       `json:"provider_count"` // Number of providers found during the last probe.
+	LastProbed    time.Time `json:"last-probed"`
+}
@@ -0,0 +1,11 @@
+package types
+
+import (
+	"time"
+)
+
+// Cursor represents the progress of listing a directory, allowing interrupted listings to be resumed.
+type Cursor struct {
+	Entries  uint      `json:"entries"`   // Number of directory entries processed so far.
+	LastSeen time.Time `json:"last-seen"` // Time of the last checkpoint.
+}
@@ -9,14 +9,16 @@ const (
 	FileLinkType        LinkType = "File"
 	UnknownLinkType     LinkType = "Unknown"
 	UnsupportedLinkType LinkType = "Unsupported"
+	SymlinkLinkType     LinkType = "Symlink"
 )
 
 // Link from a Document to other Documents.
 type Link struct {
-	Hash string   `json:"Hash"`
-	Name string   `json:"Name"`
-	Size uint64   `json:"Size"`
-	Type LinkType `json:"Type"`
+	Hash   string   `json:"Hash"`
+	Name   string   `json:"Name"`
+	Size   uint64   `json:"Size"`
+	Type   LinkType `json:"Type"`
+	Target string   `json:"Target,omitempty"` // Target path for SymlinkLinkType.
 }
 
 // Links is a collection of links to other Documents.
@@ -26,5 +28,13 @@ type Links []Link
 type Directory struct {
 	Document
 
-	Links Links `json:"links"`
+	Links     Links `json:"links"`
+	LinkPages uint  `json:"link_pages,omitempty"` // Number of additional LinkPages for large directories.
+}
+
+// LinkPage represents a page of links of a large Directory, beyond those contained in the Directory itself.
+type LinkPage struct {
+	ParentHash string `json:"parent_hash"`
+	Page       uint   `json:"page"` // Page number, starting at 1.
+	Links      Links  `json:"links"`
 }
@@ -19,4 +19,5 @@ type Document struct {
 	LastSeen   time.Time  `json:"last-seen"`
 	References References `json:"references"`
 	Size       uint64     `json:"size"`
+	Providers  Providers  `json:"providers,omitempty"`
 }
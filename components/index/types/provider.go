@@ -0,0 +1,58 @@
+package types
+
+import (
+	"sort"
+	"time"
+)
+
+// Provider represents a peer providing a Document.
+type Provider struct {
+	PeerID   string    `json:"peer_id"`
+	LastSeen time.Time `json:"last-seen"`
+}
+
+// Providers is a collection of providers of a Document, most recently seen first.
+type Providers []Provider
+
+// Add adds or refreshes the provider with `peerID`, keeping at most `max` of the most recently seen providers.
+// It returns the updated Providers and whether or not a previously unknown provider was retained.
+func (p Providers) Add(peerID string, lastSeen time.Time, max uint) (Providers, bool) {
+	added := true
+
+	result := make(Providers, 0, len(p)+1)
+	for _, provider := range p {
+		if provider.PeerID == peerID {
+			added = false
+
+			if provider.LastSeen.After(lastSeen) {
+				lastSeen = provider.LastSeen
+			}
+
+			continue
+		}
+
+		result = append(result, provider)
+	}
+
+	result = append(result, Provider{
+		PeerID:   peerID,
+		LastSeen: lastSeen,
+	})
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].LastSeen.After(result[j].LastSeen)
+	})
+
+	if uint(len(result)) > max {
+		// Providers beyond the limit are the least recently seen; check whether the new one survives.
+		for _, provider := range result[max:] {
+			if provider.PeerID == peerID {
+				added = false
+			}
+		}
+
+		result = result[:max]
+	}
+
+	return result, added
+}
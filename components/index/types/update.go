@@ -8,4 +8,5 @@ import (
 type Update struct {
 	LastSeen   time.Time  `json:"last-seen"`
 	References References `json:"references,omitempty"`
+	Providers  Providers  `json:"providers,omitempty"`
 }
@@ -0,0 +1,25 @@
+package prober
+
+import (
+	"time"
+)
+
+// Config contains configuration for a Prober.
+type Config struct {
+	Interval     time.Duration // Minimum time between probes of a document.
+	ScanInterval time.Duration // Time to wait between scans for documents to probe.
+	ProbeTimeout time.Duration // Timeout for finding the providers of a document.
+	MaxProviders uint          // Maximum number of providers to find and store per document.
+	Workers      uint          // Number of documents to probe concurrently.
+}
+
+// DefaultConfig generates a default configuration for a Prober.
+func DefaultConfig() *Config {
+	return &Config{
+		Interval:     24 * time.Hour,
+		ScanInterval: time.Hour,
+		ProbeTimeout: 30 * time.Second,
+		MaxProviders: 16,
+		Workers:      32,
+	}
+}
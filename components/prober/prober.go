@@ -0,0 +1,179 @@
+// Package prober is grouped around the Prober component, periodically probing the availability of indexed documents.
+package prober
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// probedField is the document field holding the time of the last probe.
+const probedField = "last-probed"
+
+// ProviderFinder finds the providers of resources.
+type ProviderFinder interface {
+	FindProviders(context.Context, *t.AnnotatedResource, uint) ([]string, error)
+}
+
+// Prober periodically probes the providers of documents, recording how many peers currently provide them.
+type Prober struct {
+	config  *Config
+	indexes []index.ScanIndex
+	finder  ProviderFinder
+
+	*instr.Instrumentation
+}
+
+// New instantiates a Prober.
+func New(config *Config, indexes []index.ScanIndex, finder ProviderFinder, i *instr.Instrumentation) *Prober {
+	return &Prober{
+		config,
+		indexes,
+		finder,
+		i,
+	}
+}
+
+// Probe repeatedly probes documents which have not been probed within `Config.Interval`, until the context is done.
+func (p *Prober) Probe(ctx context.Context) error {
+	for {
+		for _, idx := range p.indexes {
+			if err := p.probeIndex(ctx, idx); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(p.config.ScanInterval):
+		}
+	}
+}
+
+func (p *Prober) probeIndex(ctx context.Context, idx index.ScanIndex) error {
+	ctx, span := p.Tracer.Start(ctx, "prober.probeIndex")
+	defer span.End()
+
+	ids := make(chan string)
+	before := time.Now().Add(-p.config.Interval)
+
+	wg, wgCtx := errgroup.WithContext(ctx)
+
+	wg.Go(func() error {
+		defer close(ids)
+		return idx.ScanBefore(wgCtx, probedField, before, ids)
+	})
+
+	for i := uint(0); i < p.config.Workers; i++ {
+		wg.Go(func() error {
+			for id := range ids {
+				if err := p.probe(wgCtx, idx, id); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+	}
+
+	err := wg.Wait()
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+	}
+
+	return err
+}
+
+// findProviders returns the providers found for a resource within `Config.ProbeTimeout`.
+func (p *Prober) findProviders(ctx context.Context, r *t.AnnotatedResource) ([]string, error) {
+	findCtx, cancel := context.WithTimeout(ctx, p.config.ProbeTimeout)
+	defer cancel()
+
+	providers, err := p.finder.FindProviders(findCtx, r, p.config.MaxProviders)
+
+	if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+		// Timeout of the probe itself; use the providers found so far.
+		return providers, nil
+	}
+
+	return providers, err
+}
+
+func (p *Prober) probe(ctx context.Context, idx index.Index, id string) error {
+	ctx, span := p.Tracer.Start(ctx, "prober.probe",
+		trace.WithAttributes(label.String("cid", id)),
+	)
+	defer span.End()
+
+	existing := new(indexTypes.Availability)
+
+	found, err := idx.Get(ctx, id, existing, "providers")
+	if err != nil {
+		return p.handleErr(ctx, span, id, err)
+	}
+
+	if !found {
+		// Removed since scanning; nothing to update.
+		span.AddEvent(ctx, "not-found")
+		return nil
+	}
+
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       id,
+		},
+	}
+
+	peers, err := p.findProviders(ctx, r)
+	if err != nil {
+		return p.handleErr(ctx, span, id, err)
+	}
+
+	// Strip milliseconds to cater to legacy ES index format.
+	now := time.Now().UTC().Truncate(time.Second)
+
+	providers := existing.Providers
+	for _, peer := range peers {
+		providers, _ = providers.Add(peer, now, p.config.MaxProviders)
+	}
+
+	span.SetAttributes(label.Int("providers", len(peers)))
+
+	err = idx.Update(ctx, id, &indexTypes.Availability{
+		Providers:     providers,
+		ProviderCount: len(peers),
+		LastProbed:    now,
+	})
+	if err != nil {
+		return p.handleErr(ctx, span, id, err)
+	}
+
+	return nil
+}
+
+// handleErr logs and records errors probing a single document, which should not stop probing of
+// others. Only errors of the context itself are returned.
+func (p *Prober) handleErr(ctx context.Context, span trace.Span, id string, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+
+	log.Printf("Error probing %s: %v", id, err)
+	span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+
+	return nil
+}
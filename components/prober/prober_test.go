@@ -0,0 +1,253 @@
+package prober
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+const testID = "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp"
+
+type mockFinder struct {
+	mock.Mock
+}
+
+func (m *mockFinder) FindProviders(ctx context.Context, r *t.AnnotatedResource, max uint) ([]string, error) {
+	args := m.Called(ctx, r, max)
+	providers, _ := args.Get(0).([]string)
+	return providers, args.Error(1)
+}
+
+type ProberTestSuite struct {
+	suite.Suite
+
+	ctx    context.Context
+	cfg    *Config
+	idx    *index.Mock
+	finder *mockFinder
+	p      *Prober
+}
+
+func (s *ProberTestSuite) SetupTest() {
+	s.ctx = context.Background()
+
+	s.cfg = DefaultConfig()
+	s.cfg.Workers = 1
+
+	s.idx = &index.Mock{}
+	s.idx.Test(s.T())
+
+	s.finder = &mockFinder{}
+	s.finder.Test(s.T())
+
+	s.p = New(s.cfg, []index.ScanIndex{s.idx}, s.finder, instr.New())
+}
+
+func (s *ProberTestSuite) assertExpectations() {
+	s.idx.AssertExpectations(s.T())
+	s.finder.AssertExpectations(s.T())
+}
+
+// mockScan mocks a scan yielding testID.
+func (s *ProberTestSuite) mockScan() {
+	s.idx.
+		On("ScanBefore", mock.Anything, "last-probed", mock.MatchedBy(func(before time.Time) bool {
+			return s.WithinDuration(time.Now().Add(-s.cfg.Interval), before, time.Second)
+		}), mock.AnythingOfType("chan<- string")).
+		Run(func(args mock.Arguments) {
+			ids := args.Get(3).(chan<- string)
+			ids <- testID
+		}).
+		Return(nil).
+		Once()
+}
+
+// mockExisting mocks an indexed document with given providers.
+func (s *ProberTestSuite) mockExisting(providers indexTypes.Providers) {
+	s.idx.
+		On("Get", mock.Anything, testID, &indexTypes.Availability{}, []string{"providers"}).
+		Run(func(args mock.Arguments) {
+			a := args.Get(2).(*indexTypes.Availability)
+			a.Providers = providers
+		}).
+		Return(true, nil).
+		Once()
+}
+
+func (s *ProberTestSuite) matchResource() interface{} {
+	return mock.MatchedBy(func(r *t.AnnotatedResource) bool {
+		return r.ID == testID && r.Protocol == t.IPFSProtocol
+	})
+}
+
+func (s *ProberTestSuite) TestProbe() {
+	old := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+
+	s.mockScan()
+	s.mockExisting(indexTypes.Providers{
+		{PeerID: "QmOldProvider", LastSeen: old},
+	})
+
+	s.finder.
+		On("FindProviders", mock.Anything, s.matchResource(), s.cfg.MaxProviders).
+		Return([]string{"QmNewProvider"}, nil).
+		Once()
+
+	s.idx.
+		On("Update", mock.Anything, testID, mock.MatchedBy(func(a *indexTypes.Availability) bool {
+			return s.Equal(1, a.ProviderCount) &&
+				s.WithinDuration(time.Now(), a.LastProbed, 2*time.Second) &&
+				s.Equal(indexTypes.Providers{
+					{PeerID: "QmNewProvider", LastSeen: a.LastProbed},
+					{PeerID: "QmOldProvider", LastSeen: old},
+				}, a.Providers)
+		})).
+		Return(nil).
+		Once()
+
+	err := s.p.probeIndex(s.ctx, s.idx)
+
+	s.NoError(err)
+	s.assertExpectations()
+}
+
+func (s *ProberTestSuite) TestProbeVanished() {
+	old := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	existing := indexTypes.Providers{
+		{PeerID: "QmOldProvider", LastSeen: old},
+	}
+
+	s.mockScan()
+	s.mockExisting(existing)
+
+	s.finder.
+		On("FindProviders", mock.Anything, s.matchResource(), s.cfg.MaxProviders).
+		Return(nil, nil).
+		Once()
+
+	// Previously seen providers are retained, provider count drops to 0.
+	s.idx.
+		On("Update", mock.Anything, testID, mock.MatchedBy(func(a *indexTypes.Availability) bool {
+			return s.Equal(0, a.ProviderCount) &&
+				s.Equal(existing, a.Providers)
+		})).
+		Return(nil).
+		Once()
+
+	err := s.p.probeIndex(s.ctx, s.idx)
+
+	s.NoError(err)
+	s.assertExpectations()
+}
+
+func (s *ProberTestSuite) TestProbeTimeout() {
+	s.cfg.ProbeTimeout = time.Millisecond
+
+	s.mockScan()
+	s.mockExisting(nil)
+
+	s.finder.
+		On("FindProviders", mock.Anything, s.matchResource(), s.cfg.MaxProviders).
+		Run(func(args mock.Arguments) {
+			<-args.Get(0).(context.Context).Done()
+		}).
+		Return([]string{"QmProvider"}, context.DeadlineExceeded).
+		Once()
+
+	// Providers found before the timeout are recorded.
+	s.idx.
+		On("Update", mock.Anything, testID, mock.MatchedBy(func(a *indexTypes.Availability) bool {
+			return s.Equal(1, a.ProviderCount) &&
+				s.Len(a.Providers, 1)
+		})).
+		Return(nil).
+		Once()
+
+	err := s.p.probeIndex(s.ctx, s.idx)
+
+	s.NoError(err)
+	s.assertExpectations()
+}
+
+func (s *ProberTestSuite) TestProbeFindError() {
+	s.mockScan()
+	s.mockExisting(nil)
+
+	s.finder.
+		On("FindProviders", mock.Anything, s.matchResource(), s.cfg.MaxProviders).
+		Return(nil, errors.New("mock")).
+		Once()
+
+	// Errors finding providers for individual documents are not propagated.
+	err := s.p.probeIndex(s.ctx, s.idx)
+
+	s.NoError(err)
+	s.idx.AssertNotCalled(s.T(), "Update", mock.Anything, mock.Anything, mock.Anything)
+	s.assertExpectations()
+}
+
+func (s *ProberTestSuite) TestProbeNotFound() {
+	s.mockScan()
+
+	s.idx.
+		On("Get", mock.Anything, testID, &indexTypes.Availability{}, []string{"providers"}).
+		Return(false, nil).
+		Once()
+
+	err := s.p.probeIndex(s.ctx, s.idx)
+
+	s.NoError(err)
+	s.finder.AssertNotCalled(s.T(), "FindProviders", mock.Anything, mock.Anything, mock.Anything)
+	s.assertExpectations()
+}
+
+func (s *ProberTestSuite) TestProbeUpdateError() {
+	s.mockScan()
+	s.mockExisting(nil)
+
+	s.finder.
+		On("FindProviders", mock.Anything, s.matchResource(), s.cfg.MaxProviders).
+		Return([]string{"QmProvider"}, nil).
+		Once()
+
+	s.idx.
+		On("Update", mock.Anything, testID, mock.Anything).
+		Return(errors.New("mock")).
+		Once()
+
+	// Errors updating individual documents are not propagated.
+	err := s.p.probeIndex(s.ctx, s.idx)
+
+	s.NoError(err)
+	s.assertExpectations()
+}
+
+func (s *ProberTestSuite) TestProbeGetError() {
+	s.mockScan()
+
+	s.idx.
+		On("Get", mock.Anything, testID, &indexTypes.Availability{}, []string{"providers"}).
+		Return(false, errors.New("mock")).
+		Once()
+
+	// Errors getting individual documents are not propagated.
+	err := s.p.probeIndex(s.ctx, s.idx)
+
+	s.NoError(err)
+	s.finder.AssertNotCalled(s.T(), "FindProviders", mock.Anything, mock.Anything, mock.Anything)
+	s.assertExpectations()
+}
+
+func TestProberTestSuite(t *testing.T) {
+	suite.Run(t, new(ProberTestSuite))
+}
@@ -0,0 +1,92 @@
+package ipfs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// providerEventType is the type of routing query events containing providers.
+// Ref: https://github.com/libp2p/go-libp2p-core/blob/master/routing/query.go
+const providerEventType = 4
+
+type findProvsResponse struct {
+	ID string
+}
+
+type findProvsEvent struct {
+	Type      int
+	Responses []findProvsResponse
+}
+
+// FindProviders returns the peer ID's of up to `max` providers of a resource. When the context is cancelled
+// or expires, the providers found so far are returned along with the context's error.
+func (i *IPFS) FindProviders(ctx context.Context, r *t.AnnotatedResource, max uint) ([]string, error) {
+	ctx, span := i.Tracer.Start(ctx, "protocol.ipfs.FindProviders")
+	defer span.End()
+
+	resp, err := i.shell.Request("dht/findprovs", r.ID).
+		Option("num-providers", max).
+		Send(ctx)
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return nil, err
+	}
+
+	// If err == nil, response might be nil and cannot be closed.
+	defer resp.Close()
+
+	if resp.Error != nil {
+		err := wrapErr(resp.Error)
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return nil, err
+	}
+
+	var (
+		providers []string
+		seen      = make(map[string]bool)
+		dec       = json.NewDecoder(resp.Output)
+	)
+
+	for {
+		var e findProvsEvent
+
+		if err := dec.Decode(&e); err != nil {
+			if errors.Is(err, io.EOF) {
+				// EOF means we're done, hence err is cleared
+				err = nil
+			}
+
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				// Reading was interrupted by the context, not by the response.
+				err = ctxErr
+			}
+
+			if err != nil {
+				span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+			}
+
+			span.SetAttributes(label.Int("providers", len(providers)))
+
+			return providers, err
+		}
+
+		if e.Type != providerEventType {
+			continue
+		}
+
+		for _, p := range e.Responses {
+			if !seen[p.ID] {
+				seen[p.ID] = true
+				providers = append(providers, p.ID)
+			}
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package ipfs
+
+import (
+	"context"
+	"fmt"
+	"github.com/dankinder/httpmock"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"testing"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+type FindProvidersTestSuite struct {
+	suite.Suite
+
+	ctx  context.Context
+	ipfs *IPFS
+
+	mockAPIHandler *httpmock.MockHandler
+	mockAPIServer  *httpmock.Server
+	responseHeader http.Header
+}
+
+func (s *FindProvidersTestSuite) SetupTest() {
+	s.ctx = context.Background()
+
+	s.mockAPIHandler = &httpmock.MockHandler{}
+	s.mockAPIServer = httpmock.NewServer(s.mockAPIHandler)
+	s.responseHeader = http.Header{
+		"Content-Type": []string{"application/json"},
+	}
+
+	cfg := DefaultConfig()
+	cfg.APIURL = s.mockAPIServer.URL()
+
+	s.ipfs = New(cfg, http.DefaultClient, instr.New())
+}
+
+func (s *FindProvidersTestSuite) TearDownTest() {
+	s.mockAPIServer.Close()
+}
+
+func (s *FindProvidersTestSuite) TestFindProviders() {
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+	}
+
+	rURL := fmt.Sprintf("/api/v0/dht/findprovs?arg=%s&num-providers=3", r.ID)
+
+	// Provider events interleaved with other query events, including a duplicate.
+	body := `{"Extra":"","ID":"QmPeerA","Responses":null,"Type":0}
+{"Extra":"","ID":"","Responses":[{"Addrs":null,"ID":"QmProviderA"}],"Type":4}
+{"Extra":"","ID":"QmPeerB","Responses":[{"Addrs":null,"ID":"QmPeerC"}],"Type":1}
+{"Extra":"","ID":"","Responses":[{"Addrs":null,"ID":"QmProviderB"}],"Type":4}
+{"Extra":"","ID":"","Responses":[{"Addrs":null,"ID":"QmProviderA"}],"Type":4}
+`
+
+	s.mockAPIHandler.
+		On("Handle", "POST", rURL, mock.Anything).
+		Return(httpmock.Response{
+			Body:   []byte(body),
+			Header: s.responseHeader,
+		}).
+		Once()
+
+	providers, err := s.ipfs.FindProviders(s.ctx, r, 3)
+
+	s.NoError(err)
+	s.Equal([]string{"QmProviderA", "QmProviderB"}, providers)
+	s.mockAPIHandler.AssertExpectations(s.T())
+}
+
+func (s *FindProvidersTestSuite) TestFindProvidersNone() {
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+	}
+
+	rURL := fmt.Sprintf("/api/v0/dht/findprovs?arg=%s&num-providers=3", r.ID)
+
+	s.mockAPIHandler.
+		On("Handle", "POST", rURL, mock.Anything).
+		Return(httpmock.Response{
+			Body:   []byte(``),
+			Header: s.responseHeader,
+		}).
+		Once()
+
+	providers, err := s.ipfs.FindProviders(s.ctx, r, 3)
+
+	s.NoError(err)
+	s.Empty(providers)
+	s.mockAPIHandler.AssertExpectations(s.T())
+}
+
+func TestFindProvidersTestSuite(t *testing.T) {
+	suite.Run(t, new(FindProvidersTestSuite))
+}
@@ -19,6 +19,7 @@ type IPFS struct {
 
 	gatewayURL *url.URL
 	shell      *ipfs.Shell
+	partials   *partialCache
 
 	*instr.Instrumentation
 }
@@ -47,6 +48,7 @@ func New(config *Config, client *http.Client, instr *instr.Instrumentation) *IPF
 		config,
 		gatewayURL,
 		shell,
+		newPartialCache(config.PartialCacheSize),
 		instr,
 	}
 }
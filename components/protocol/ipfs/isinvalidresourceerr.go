@@ -3,8 +3,11 @@ package ipfs
 import (
 	"context"
 	"errors"
+	"fmt"
 	ipfs "github.com/ipfs/go-ipfs-api"
 	"log"
+
+	t "github.com/ipfs-search/ipfs-search/types"
 )
 
 // isInvalidResourceErr determines whether an error returned by protocol methods represents invalid content.
@@ -37,3 +40,12 @@ func isInvalidResourceErr(err error) bool {
 
 	return false
 }
+
+// wrapErr wraps errors representing invalid content with ErrInvalidResource.
+func wrapErr(err error) error {
+	if isInvalidResourceErr(err) {
+		return fmt.Errorf("%w: %v", t.ErrInvalidResource, err)
+	}
+
+	return err
+}
@@ -98,6 +98,8 @@ func typeFromPb(pbType unixfs_pb.Data_DataType) t.ResourceType {
 		return t.FileType
 	case unixfs.THAMTShard, unixfs.TDirectory, unixfs.TMetadata:
 		return t.DirectoryType
+	case unixfs.TSymlink:
+		return t.SymlinkType
 	default:
 		return t.UnsupportedType
 	}
@@ -190,6 +192,10 @@ func (i *IPFS) Ls(ctx context.Context, r *t.AnnotatedResource, out chan<- *t.Ann
 			},
 		}
 
+		if refR.Type == t.SymlinkType {
+			refR.Reference.Target = resolveTarget(r.ID, link.Target)
+		}
+
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
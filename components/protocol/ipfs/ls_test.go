@@ -87,12 +87,13 @@ func (s *LsTestSuite) TestLsHAMTDirectory() {
 				{"Objects":[{"Hash":"/ipfs/QmehSxmTPRCr85Xjgzjut6uWQihoTfqg9VVihJ892bmZCp","Links":[{"Name":"Back_of_the_moon.html","Hash":"bafkreidnsi74hf7n2dtidxnqjdyr6lxidnsikdgwxktd7m3duwkuwl2u5u","Size":5169,"Type":2,"Target":""}]}]}
 				{"Objects":[{"Hash":"/ipfs/QmehSxmTPRCr85Xjgzjut6uWQihoTfqg9VVihJ892bmZCp","Links":[{"Name":"Munchh..html","Hash":"bafkreice7raasrty3makrm3gyg7sjqimdhhx6pdezh2noh3jlzwmvdcooy","Size":4986,"Type":2,"Target":""}]}]}
 				{"Objects":[{"Hash":"/ipfs/QmehSxmTPRCr85Xjgzjut6uWQihoTfqg9VVihJ892bmZCp","Links":[{"Name":"directory","Hash":"bafkreice7raasrty3makrm3gyg7sjqimdhhx6pdezh2noh3jlzwmvdcooy","Size":4986,"Type":1,"Target":""}]}]}
-				{"Objects":[{"Hash":"/ipfs/QmehSxmTPRCr85Xjgzjut6uWQihoTfqg9VVihJ892bmZCp","Links":[{"Name":"unsupported","Hash":"bafkreice7raasrty3makrm3gyg7sjqimdhhx6pdezh2noh3jlzwmvdcooy","Size":4986,"Type":4,"Target":""}]}]}
+				{"Objects":[{"Hash":"/ipfs/QmehSxmTPRCr85Xjgzjut6uWQihoTfqg9VVihJ892bmZCp","Links":[{"Name":"unsupported","Hash":"bafkreice7raasrty3makrm3gyg7sjqimdhhx6pdezh2noh3jlzwmvdcooy","Size":4986,"Type":6,"Target":""}]}]}
+				{"Objects":[{"Hash":"/ipfs/QmehSxmTPRCr85Xjgzjut6uWQihoTfqg9VVihJ892bmZCp","Links":[{"Name":"symlink","Hash":"QmZTR5bcpQD7cFgTorqxZDYaew1Wqgfbd2ud9QqGPAkK2V","Size":9,"Type":4,"Target":"directory"}]}]}
 			`),
 		}).
 		Once()
 
-	resultChan := make(chan *t.AnnotatedResource, 4)
+	resultChan := make(chan *t.AnnotatedResource, 5)
 	err := s.ipfs.Ls(s.ctx, r, resultChan)
 
 	s.NoError(err)
@@ -163,6 +164,24 @@ func (s *LsTestSuite) TestLsHAMTDirectory() {
 			Size: 4986,
 		},
 	})
+
+	lsRes = <-resultChan
+	s.Equal(lsRes, &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmZTR5bcpQD7cFgTorqxZDYaew1Wqgfbd2ud9QqGPAkK2V",
+		},
+		Reference: t.Reference{
+			Parent: r.Resource,
+			Name:   "symlink",
+			// Relative targets are resolved against the parent directory.
+			Target: "/ipfs/QmehSxmTPRCr85Xjgzjut6uWQihoTfqg9VVihJ892bmZCp/directory",
+		},
+		Stat: t.Stat{
+			Type: t.SymlinkType,
+			Size: 9,
+		},
+	})
 }
 
 func (s *LsTestSuite) TestNormalDirectory() {
@@ -0,0 +1,130 @@
+package ipfs
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+
+	"github.com/ipfs/go-cid"
+	unixfs "github.com/ipfs/go-unixfs"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+type objectLinksResult struct {
+	Hash  string
+	Links []struct {
+		Hash string
+	}
+}
+
+// recordChildren remembers the children of a multi-block file as partials.
+// Ref: http://docs.ipfs.io.ipns.localhost:8080/reference/http/api/#api-v0-object-links
+func (i *IPFS) recordChildren(ctx context.Context, r *t.AnnotatedResource) error {
+	ctx, span := i.Tracer.Start(ctx, "protocol.ipfs.recordChildren")
+	defer span.End()
+
+	result := new(objectLinksResult)
+
+	if err := i.shell.Request("object/links", absolutePath(r)).Exec(ctx, result); err != nil {
+		return wrapErr(err)
+	}
+
+	for _, l := range result.Links {
+		i.partials.Add(l.Hash, r.ID)
+	}
+
+	return nil
+}
+
+// objectData returns the UnixFS data of a (dag-pb) node.
+// Ref: http://docs.ipfs.io.ipns.localhost:8080/reference/http/api/#api-v0-object-data
+func (i *IPFS) objectData(ctx context.Context, r *t.AnnotatedResource) (*unixfs.FSNode, error) {
+	resp, err := i.shell.Request("object/data", absolutePath(r)).Send(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// If err == nil, response might be nil and cannot be closed.
+	defer resp.Close()
+
+	if resp.Error != nil {
+		return nil, wrapErr(resp.Error)
+	}
+
+	data, err := ioutil.ReadAll(resp.Output)
+	if err != nil {
+		return nil, err
+	}
+
+	node, err := unixfs.FSNodeFromBytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", t.ErrInvalidResource, err)
+	}
+
+	return node, nil
+}
+
+// isRawNode returns true when the UnixFS data of a (dag-pb) node has type Raw. Importers use this
+// type for the leaves of multi-block files, whereas single-block files have type File.
+func (i *IPFS) isRawNode(ctx context.Context, r *t.AnnotatedResource) (bool, error) {
+	ctx, span := i.Tracer.Start(ctx, "protocol.ipfs.isRawNode")
+	defer span.End()
+
+	node, err := i.objectData(ctx, r)
+	if err != nil {
+		return false, err
+	}
+
+	return node.Type() == unixfs.TRaw, nil
+}
+
+// detectPartial inspects the DAG structure of a file, overriding the type of *unreferenced* chunks of
+// other files with PartialType. Children of multi-block files are recorded, so that they can be
+// recognised as partials regardless of the chunker used.
+func (i *IPFS) detectPartial(ctx context.Context, r *t.AnnotatedResource, links int) error {
+	if links > 0 {
+		// Root (or intermediate node) of a multi-block file.
+		if err := i.recordChildren(ctx, r); err != nil {
+			// Failing to record children only affects partial detection; the file itself is fine.
+			log.Printf("Error recording children of %v: %v", r, err)
+		}
+	}
+
+	if r.Reference.Parent != nil {
+		// Referenced items are never partials.
+		return nil
+	}
+
+	if _, known := i.partials.Parent(r.ID); known {
+		r.Stat.Type = t.PartialType
+		return nil
+	}
+
+	if links > 0 {
+		return nil
+	}
+
+	c, err := cid.Decode(r.ID)
+	if err != nil {
+		return fmt.Errorf("%w: %v", t.ErrInvalidResource, err)
+	}
+
+	if c.Type() == cid.Raw {
+		// Raw leaves are either single-block files or chunks of larger ones; their block size always equals
+		// their file size. Without a known parent, they are kept as files.
+		return nil
+	}
+
+	isRaw, err := i.isRawNode(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	if isRaw {
+		r.Stat.Type = t.PartialType
+	}
+
+	return nil
+}
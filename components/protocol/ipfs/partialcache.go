@@ -0,0 +1,52 @@
+package ipfs
+
+import (
+	"sync"
+)
+
+// partialCache is a bounded, concurrency-safe set of resource ID's known to be partials (chunks) of
+// other files. When full, the oldest entries are evicted first.
+type partialCache struct {
+	mu    sync.Mutex
+	items map[string]string // Maps partials to their parent.
+	ring  []string
+	next  int
+}
+
+func newPartialCache(size uint) *partialCache {
+	if size == 0 {
+		panic("partial cache size should be larger than 0")
+	}
+
+	return &partialCache{
+		items: make(map[string]string, size),
+		ring:  make([]string, size),
+	}
+}
+
+// Add records id as a partial of parent.
+func (c *partialCache) Add(id string, parent string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.items[id]; ok {
+		return
+	}
+
+	if evicted := c.ring[c.next]; evicted != "" {
+		delete(c.items, evicted)
+	}
+
+	c.items[id] = parent
+	c.ring[c.next] = id
+	c.next = (c.next + 1) % len(c.ring)
+}
+
+// Parent returns the parent of a known partial and whether it was found.
+func (c *partialCache) Parent(id string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	parent, ok := c.items[id]
+	return parent, ok
+}
@@ -0,0 +1,40 @@
+package ipfs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPartialCacheAdd(t *testing.T) {
+	assert := assert.New(t)
+
+	c := newPartialCache(2)
+	c.Add("child", "parent")
+
+	parent, ok := c.Parent("child")
+	assert.True(ok)
+	assert.Equal("parent", parent)
+
+	_, ok = c.Parent("unknown")
+	assert.False(ok)
+}
+
+func TestPartialCacheEvictOldest(t *testing.T) {
+	assert := assert.New(t)
+
+	c := newPartialCache(2)
+	c.Add("a", "parent")
+	c.Add("b", "parent")
+	c.Add("a", "parent") // Existing entries are not re-added.
+	c.Add("c", "parent")
+
+	_, ok := c.Parent("a")
+	assert.False(ok)
+
+	_, ok = c.Parent("b")
+	assert.True(ok)
+
+	_, ok = c.Parent("c")
+	assert.True(ok)
+}
@@ -2,7 +2,6 @@ package ipfs
 
 import (
 	"context"
-	"fmt"
 
 	"go.opentelemetry.io/otel/api/trace"
 	"go.opentelemetry.io/otel/codes"
@@ -14,6 +13,7 @@ type statResult struct {
 	Type           string
 	Size           uint64
 	CumulativeSize uint64
+	Blocks         int // Number of links of the root node.
 }
 
 func typeFromString(strType string) t.ResourceType {
@@ -22,6 +22,8 @@ func typeFromString(strType string) t.ResourceType {
 		return t.FileType
 	case "directory":
 		return t.DirectoryType
+	case "symlink":
+		return t.SymlinkType
 	default:
 		return t.UnsupportedType
 	}
@@ -49,10 +51,7 @@ func (i *IPFS) Stat(ctx context.Context, r *t.AnnotatedResource) error {
 	result := new(statResult)
 
 	if err := req.Exec(ctx, result); err != nil {
-		if isInvalidResourceErr(err) {
-			err = fmt.Errorf("%w: %v", t.ErrInvalidResource, err)
-		}
-
+		err = wrapErr(err)
 		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
 		return err
 	}
@@ -65,9 +64,18 @@ func (i *IPFS) Stat(ctx context.Context, r *t.AnnotatedResource) error {
 		Size: size,
 	}
 
-	// Override type for *unreferenced* partials, based on size
-	if r.Size == uint64(i.config.PartialSize) && r.Reference.Parent == nil {
-		r.Stat.Type = t.PartialType
+	if rType == t.FileType {
+		if err := i.detectPartial(ctx, r, result.Blocks); err != nil {
+			span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+			return err
+		}
+	}
+
+	if rType == t.SymlinkType {
+		if err := i.readTarget(ctx, r); err != nil {
+			span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+			return err
+		}
 	}
 
 	return nil
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"github.com/dankinder/httpmock"
+	unixfs "github.com/ipfs/go-unixfs"
+	unixfs_pb "github.com/ipfs/go-unixfs/pb"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	"net/http"
@@ -44,6 +46,42 @@ func (s *StatTestSuite) TearDownTest() {
 	s.mockAPIServer.Close()
 }
 
+// mockObjectData sets up a mock object/data response with UnixFS data of given type.
+func (s *StatTestSuite) mockObjectData(id string, dataType unixfs_pb.Data_DataType) {
+	node := unixfs.NewFSNode(dataType)
+	node.SetData([]byte("data"))
+
+	data, err := node.GetBytes()
+	s.Require().NoError(err)
+
+	rURL := fmt.Sprintf("/api/v0/object/data?arg=%%2Fipfs%%2F%s", id)
+
+	s.mockAPIHandler.
+		On("Handle", "POST", rURL, mock.Anything).
+		Return(httpmock.Response{
+			Body: data,
+		}).
+		Once()
+}
+
+// mockObjectLinks sets up a mock object/links response.
+func (s *StatTestSuite) mockObjectLinks(id string, links ...string) {
+	rURL := fmt.Sprintf("/api/v0/object/links?arg=%%2Fipfs%%2F%s", id)
+
+	result := objectLinksResult{Hash: id}
+	for _, l := range links {
+		result.Links = append(result.Links, struct{ Hash string }{l})
+	}
+
+	s.mockAPIHandler.
+		On("Handle", "POST", rURL, mock.Anything).
+		Return(httpmock.Response{
+			Header: s.responseHeader,
+			Body:   httpmock.ToJSON(result),
+		}).
+		Once()
+}
+
 func (s *StatTestSuite) TestDirectory() {
 	r := &t.AnnotatedResource{
 		Resource: &t.Resource{
@@ -93,6 +131,8 @@ func (s *StatTestSuite) TestSinglePartFile() {
 		}).
 		Once()
 
+	s.mockObjectData(r.ID, unixfs.TFile)
+
 	err := s.ipfs.Stat(s.ctx, r)
 
 	s.NoError(err)
@@ -123,6 +163,8 @@ func (s *StatTestSuite) TestMultiPartFile() {
 		}).
 		Once()
 
+	s.mockObjectLinks(r.ID, "QmcBLKyRHjbGeLnjnmj74FFJpGJDz4YxFqUDYqMU7Mny1p")
+
 	err := s.ipfs.Stat(s.ctx, r)
 
 	s.NoError(err)
@@ -153,6 +195,8 @@ func (s *StatTestSuite) TestUnreferencedPartial() {
 		}).
 		Once()
 
+	s.mockObjectData(r.ID, unixfs.TRaw)
+
 	err := s.ipfs.Stat(s.ctx, r)
 
 	s.NoError(err)
@@ -201,6 +245,184 @@ func (s *StatTestSuite) TestReferencedPartial() {
 	})
 }
 
+func (s *StatTestSuite) TestKnownPartial() {
+	parent := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "Qmc8mmzycvXnzgwBHokZQd97iWAmtdFMqX4FZUAQ5AQdQi",
+		},
+	}
+
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "bafkreia2whgx2vblgdpwim5ugz7ofhxoo2vtpyart633mj6gbpwsj7yfxq",
+		},
+	}
+
+	parentURL := fmt.Sprintf("/api/v0/files/stat?arg=%%2Fipfs%%2F%s", parent.ID)
+	rURL := fmt.Sprintf("/api/v0/files/stat?arg=%%2Fipfs%%2F%s", r.ID)
+
+	// Setup mock handler
+	s.mockAPIHandler.
+		On("Handle", "POST", parentURL, mock.Anything).
+		Return(httpmock.Response{
+			Header: s.responseHeader,
+			Body:   []byte(`{"Hash":"Qmc8mmzycvXnzgwBHokZQd97iWAmtdFMqX4FZUAQ5AQdQi","Size":4475792,"CumulativeSize":4476917,"Blocks":18,"Type":"file"}`),
+		}).
+		Once()
+
+	s.mockObjectLinks(parent.ID, r.ID)
+
+	s.mockAPIHandler.
+		On("Handle", "POST", rURL, mock.Anything).
+		Return(httpmock.Response{
+			Header: s.responseHeader,
+			Body:   []byte(`{"Hash":"bafkreia2whgx2vblgdpwim5ugz7ofhxoo2vtpyart633mj6gbpwsj7yfxq","Size":1000,"CumulativeSize":1000,"Blocks":0,"Type":"file"}`),
+		}).
+		Once()
+
+	s.NoError(s.ipfs.Stat(s.ctx, parent))
+	s.NoError(s.ipfs.Stat(s.ctx, r))
+
+	s.mockAPIHandler.AssertExpectations(s.T())
+
+	s.Equal(t.FileType, parent.Type)
+
+	// Children of stat'ed multi-block files are partials, regardless of their size.
+	s.Equal(r.Stat, t.Stat{
+		Type: t.PartialType,
+		Size: 1000,
+	})
+}
+
+func (s *StatTestSuite) TestRawLeaf() {
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "bafkreia2whgx2vblgdpwim5ugz7ofhxoo2vtpyart633mj6gbpwsj7yfxq",
+		},
+	}
+
+	rURL := fmt.Sprintf("/api/v0/files/stat?arg=%%2Fipfs%%2F%s", r.ID)
+
+	// Setup mock handler
+	s.mockAPIHandler.
+		On("Handle", "POST", rURL, mock.Anything).
+		Return(httpmock.Response{
+			Header: s.responseHeader,
+			Body:   []byte(`{"Hash":"bafkreia2whgx2vblgdpwim5ugz7ofhxoo2vtpyart633mj6gbpwsj7yfxq","Size":262144,"CumulativeSize":262144,"Blocks":0,"Type":"file"}`),
+		}).
+		Once()
+
+	err := s.ipfs.Stat(s.ctx, r)
+
+	s.NoError(err)
+	s.mockAPIHandler.AssertExpectations(s.T())
+
+	// Raw leaves without known parent can't be told apart from single-block files, even at the chunk size.
+	s.Equal(r.Stat, t.Stat{
+		Type: t.FileType,
+		Size: 262144,
+	})
+}
+
+func (s *StatTestSuite) TestRecordChildrenError() {
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "Qmc8mmzycvXnzgwBHokZQd97iWAmtdFMqX4FZUAQ5AQdQi",
+		},
+	}
+
+	rURL := fmt.Sprintf("/api/v0/files/stat?arg=%%2Fipfs%%2F%s", r.ID)
+	linksURL := fmt.Sprintf("/api/v0/object/links?arg=%%2Fipfs%%2F%s", r.ID)
+
+	// Setup mock handler
+	s.mockAPIHandler.
+		On("Handle", "POST", rURL, mock.Anything).
+		Return(httpmock.Response{
+			Header: s.responseHeader,
+			Body:   []byte(`{"Hash":"Qmc8mmzycvXnzgwBHokZQd97iWAmtdFMqX4FZUAQ5AQdQi","Size":4475792,"CumulativeSize":4476917,"Blocks":18,"Type":"file"}`),
+		}).
+		Once()
+
+	s.mockAPIHandler.
+		On("Handle", "POST", linksURL, mock.Anything).
+		Return(httpmock.Response{
+			Header: s.responseHeader,
+			Status: 500,
+			Body:   []byte(`{"Message":"context deadline exceeded","Code":0,"Type":"error"}`),
+		}).
+		Once()
+
+	err := s.ipfs.Stat(s.ctx, r)
+
+	// Failing to record children should not fail the Stat.
+	s.NoError(err)
+	s.mockAPIHandler.AssertExpectations(s.T())
+
+	s.Equal(r.Stat, t.Stat{
+		Type: t.FileType,
+		Size: 4475792,
+	})
+}
+
+func (s *StatTestSuite) TestSymlink() {
+	// Entry of a normal (non-HAMT) directory; listings don't resolve the type.
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmZTR5bcpQD7cFgTorqxZDYaew1Wqgfbd2ud9QqGPAkK2V",
+		},
+		Reference: t.Reference{
+			Parent: &t.Resource{
+				Protocol: t.IPFSProtocol,
+				ID:       "QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv",
+			},
+			Name: "about",
+		},
+		Stat: t.Stat{
+			Type: t.UndefinedType,
+		},
+	}
+
+	rURL := fmt.Sprintf("/api/v0/files/stat?arg=%%2Fipfs%%2F%s", r.ID)
+
+	// Setup mock handler
+	s.mockAPIHandler.
+		On("Handle", "POST", rURL, mock.Anything).
+		Return(httpmock.Response{
+			Header: s.responseHeader,
+			Body:   []byte(`{"Hash":"QmZTR5bcpQD7cFgTorqxZDYaew1Wqgfbd2ud9QqGPAkK2V","Size":9,"CumulativeSize":17,"Blocks":0,"Type":"symlink"}`),
+		}).
+		Once()
+
+	node := unixfs.NewFSNode(unixfs.TSymlink)
+	node.SetData([]byte("home.html"))
+
+	data, err := node.GetBytes()
+	s.Require().NoError(err)
+
+	s.mockAPIHandler.
+		On("Handle", "POST", fmt.Sprintf("/api/v0/object/data?arg=%%2Fipfs%%2F%s", r.ID), mock.Anything).
+		Return(httpmock.Response{
+			Body: data,
+		}).
+		Once()
+
+	err = s.ipfs.Stat(s.ctx, r)
+
+	s.NoError(err)
+	s.mockAPIHandler.AssertExpectations(s.T())
+
+	s.Equal(r.Stat, t.Stat{
+		Type: t.SymlinkType,
+		Size: 17,
+	})
+	s.Equal("/ipfs/QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv/home.html", r.Reference.Target)
+}
+
 func (s *StatTestSuite) TestUnsupported() {
 	r := &t.AnnotatedResource{
 		Resource: &t.Resource{
@@ -0,0 +1,62 @@
+package ipfs
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	unixfs "github.com/ipfs/go-unixfs"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// resolveTarget resolves the target of a symlink in the directory identified by parentID.
+// Absolute IPFS/IPNS paths are cleaned, relative targets are resolved against the parent
+// directory as long as they do not escape it. Any other target can not be resolved within
+// IPFS and is returned unmodified.
+func resolveTarget(parentID string, target string) string {
+	if path.IsAbs(target) {
+		if strings.HasPrefix(target, "/ipfs/") || strings.HasPrefix(target, "/ipns/") {
+			return path.Clean(target)
+		}
+
+		return target
+	}
+
+	parentPath := "/ipfs/" + parentID
+	resolved := path.Join(parentPath, target)
+
+	if !strings.HasPrefix(resolved, parentPath+"/") {
+		// Escapes the parent directory (e.g. `../file.txt`), we don't know where it ends up.
+		return target
+	}
+
+	return resolved
+}
+
+// readTarget sets the Target of a symlink from its UnixFS data. Listings of HAMT sharded
+// directories include targets, but symlinks in normal directories are only recognised on Stat.
+func (i *IPFS) readTarget(ctx context.Context, r *t.AnnotatedResource) error {
+	ctx, span := i.Tracer.Start(ctx, "protocol.ipfs.readTarget")
+	defer span.End()
+
+	node, err := i.objectData(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	if node.Type() != unixfs.TSymlink {
+		return fmt.Errorf("%w: unexpected UnixFS type %v for symlink", t.ErrInvalidResource, node.Type())
+	}
+
+	target := string(node.Data())
+
+	if r.Reference.Parent != nil {
+		target = resolveTarget(r.Reference.Parent.ID, target)
+	}
+
+	r.Reference.Target = target
+
+	return nil
+}
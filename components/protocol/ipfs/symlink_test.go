@@ -0,0 +1,32 @@
+package ipfs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const symlinkParent = "QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv"
+
+func TestResolveTargetRelative(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("/ipfs/"+symlinkParent+"/about", resolveTarget(symlinkParent, "about"))
+	assert.Equal("/ipfs/"+symlinkParent+"/sub/index.html", resolveTarget(symlinkParent, "./sub/../sub/index.html"))
+}
+
+func TestResolveTargetEscaping(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("../about", resolveTarget(symlinkParent, "../about"))
+	assert.Equal(".", resolveTarget(symlinkParent, "."))
+}
+
+func TestResolveTargetAbsolute(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("/ipfs/QmZTR5bcpQD7cFgTorqxZDYaew1Wqgfbd2ud9QqGPAkK2V/about",
+		resolveTarget(symlinkParent, "/ipfs/QmZTR5bcpQD7cFgTorqxZDYaew1Wqgfbd2ud9QqGPAkK2V//about"))
+	assert.Equal("/ipns/ipfs.io", resolveTarget(symlinkParent, "/ipns/ipfs.io/"))
+	assert.Equal("/etc/passwd", resolveTarget(symlinkParent, "/etc/passwd"))
+}
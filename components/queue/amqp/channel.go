@@ -2,6 +2,10 @@ package amqp
 
 import (
 	"context"
+	"log"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/streadway/amqp"
 	"go.opentelemetry.io/otel/api/trace"
@@ -11,42 +15,287 @@ import (
 	"github.com/ipfs-search/ipfs-search/instr"
 )
 
-// Channel wraps an AMQP channel
+// Channel wraps an AMQP channel, reopening it with the same QoS and queues when it or its connection is lost.
 type Channel struct {
-	ch *amqp.Channel
+	conn *Connection
+
+	mu            sync.Mutex
+	prefetchCount int
+	ch            *amqp.Channel
+	confirms      *confirms               // Publishes awaiting confirmation on ch.
+	opened        chan struct{}           // Closed while open, replaced when the channel is lost.
+	done          chan struct{}           // Closed when the channel is closed.
+	queues        map[string]*QueueConfig // Declared queues, redeclared when reopening.
+
 	*instr.Instrumentation
 }
 
-// Queue creates a named queue on a given chennel
-func (c *Channel) Queue(ctx context.Context, name string) (*Queue, error) {
-	ctx, span := c.Tracer.Start(ctx, "queue.amqp.Channel.Queue", trace.WithAttributes(label.String("queue", name)))
-	defer span.End()
+// open opens the channel on conn in confirm mode, setting QoS and declaring queues.
+func (c *Channel) open(conn *amqp.Connection) error {
+	ch, err := conn.Channel()
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	prefetchCount := c.prefetchCount
+	c.mu.Unlock()
+
+	// Set Qos
+	err = ch.Qos(
+		prefetchCount,
+		0,     // prefetch size
+		false, // global
+	)
+	if err != nil {
+		ch.Close()
+		return err
+	}
+
+	confirms := newConfirms(c.conn.config.MaxUnconfirmed)
+	if err := confirms.listen(ch); err != nil {
+		ch.Close()
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	select {
+	case <-c.done:
+		// Closed while opening.
+		return ch.Close()
+	default:
+	}
+
+	if c.prefetchCount != prefetchCount {
+		// Adjusted while opening.
+		if err := ch.Qos(c.prefetchCount, 0, false); err != nil {
+			ch.Close()
+			return err
+		}
+	}
+
+	for _, cfg := range c.queues {
+		if err := declare(ch, cfg); err != nil {
+			ch.Close()
+			return err
+		}
+	}
+
+	c.ch = ch
+	c.confirms = confirms
+	close(c.opened)
+
+	return nil
+}
+
+// lost marks ch as lost, when it is the current channel and it has not been marked before.
+func (c *Channel) lost(ch *amqp.Channel) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.ch != ch {
+		return
+	}
+
+	select {
+	case <-c.opened:
+		c.opened = make(chan struct{})
+	default:
+	}
+}
+
+// wait returns the current channel and its confirmations, waiting while reopening.
+func (c *Channel) wait(ctx context.Context) (*amqp.Channel, *confirms, error) {
+	c.mu.Lock()
+	ch, confirms, opened := c.ch, c.confirms, c.opened
+	c.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	case <-c.done:
+		return nil, nil, amqp.ErrClosed
+	case <-c.conn.done:
+		return nil, nil, ErrConnectionClosed
+	case <-opened:
+		return ch, confirms, nil
+	}
+}
+
+// current returns the current channel, waiting while reopening.
+func (c *Channel) current(ctx context.Context) (*amqp.Channel, error) {
+	ch, _, err := c.wait(ctx)
+	return ch, err
+}
+
+// publishAsync publishes msg as mandatory to the named queue, returning a function waiting for it to be confirmed by
+// the broker. Messages are published again when the channel is lost before they have been confirmed.
+func (c *Channel) publishAsync(ctx context.Context, queue string, msg amqp.Publishing) (func(context.Context) error, error) {
+	for {
+		ch, confirms, err := c.wait(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		p, err := confirms.publish(ctx, func(tag uint64) error {
+			// Returned messages are correlated by message ID.
+			msg.MessageId = strconv.FormatUint(tag, 10)
+
+			return ch.Publish(
+				"",    // exchange
+				queue, // routing key
+				true,  // mandatory
+				false, // immediate
+				msg)
+		})
+
+		if err == amqp.ErrClosed {
+			c.lost(ch)
+			continue
+		}
 
-	_, err := c.ch.QueueDeclare(
-		name,  // name
-		true,  // durable
-		false, // delete when unused
-		false, // exclusive
-		false, // no-wait
-		amqp.Table{
-			"x-max-priority": 9,                       // Enable all 9 priorities
-			"x-message-ttl":  1000 * 60 * 60 * 24 * 7, // Expire messages after 1 week
-			"x-queue-mode":   "lazy",                  // Allow RabbitMQ to write queue to disk as fast as possible
-		},
+		if err != nil {
+			return nil, err
+		}
+
+		return func(ctx context.Context) error {
+			err := p.wait(ctx, c.conn.config.ConfirmTimeout)
+			if err != amqp.ErrClosed {
+				return err
+			}
+
+			c.lost(ch)
+
+			return c.publish(ctx, queue, msg)
+		}, nil
+	}
+}
+
+// publish publishes msg as mandatory to the named queue, waiting for it to be confirmed by the broker.
+func (c *Channel) publish(ctx context.Context, queue string, msg amqp.Publishing) error {
+	wait, err := c.publishAsync(ctx, queue, msg)
+	if err != nil {
+		return err
+	}
+
+	return wait(ctx)
+}
+
+// monitor reopens the channel when it is lost, until it is closed.
+func (c *Channel) monitor(ctx context.Context) {
+	for {
+		c.mu.Lock()
+		ch := c.ch
+		c.mu.Unlock()
+
+		// The notification channel is closed without error for closed channels, including those closed along with
+		// their connection.
+		closeChan := ch.NotifyClose(make(chan *amqp.Error, 1))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.done:
+			return
+		case err := <-closeChan:
+			select {
+			case <-c.done:
+				return
+			default:
+			}
+
+			log.Printf("AMQP channel lost: %v", err)
+		}
+
+		c.lost(ch)
+
+		for {
+			conn, err := c.conn.current(ctx)
+			if err != nil {
+				return
+			}
+
+			if err = c.open(conn); err == nil {
+				log.Println("AMQP channel reopened")
+				break
+			}
+
+			log.Printf("Error reopening AMQP channel: %v", err)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(c.conn.config.ReconnectTime):
+			}
+		}
+	}
+}
+
+// SetPrefetch sets the amount of messages delivered ahead of being acknowledged, also after reopening the channel.
+func (c *Channel) SetPrefetch(ctx context.Context, count int) error {
+	c.mu.Lock()
+	c.prefetchCount = count
+	c.mu.Unlock()
+
+	ch, err := c.current(ctx)
+	if err != nil {
+		return err
+	}
+
+	return ch.Qos(
+		count,
+		0,     // prefetch size
+		false, // global
 	)
+}
+
+// Queue declares a queue on a given channel, failing with ErrTopologyMismatch when it exists with a different
+// configuration.
+func (c *Channel) Queue(ctx context.Context, cfg *QueueConfig) (*Queue, error) {
+	ctx, span := c.Tracer.Start(ctx, "queue.amqp.Channel.Queue", trace.WithAttributes(label.String("queue", cfg.Name)))
+	defer span.End()
+
+	err := cfg.validate()
+	if err == nil {
+		var ch *amqp.Channel
+		if ch, err = c.current(ctx); err == nil {
+			err = declare(ch, cfg)
+		}
+	}
+
 	if err != nil {
 		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
 		return nil, err
 	}
 
+	c.mu.Lock()
+	c.queues[cfg.Name] = cfg
+	c.mu.Unlock()
+
 	return &Queue{
 		channel:         c,
-		name:            name,
+		name:            cfg.Name,
 		Instrumentation: c.Instrumentation,
 	}, nil
 }
 
-// Close closes a Channel
+// Close closes a Channel; it is not reopened afterwards.
 func (c *Channel) Close() error {
-	return c.ch.Close()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return nil
+	default:
+		close(c.done)
+	}
+
+	if err := c.ch.Close(); err != nil && err != amqp.ErrClosed {
+		return err
+	}
+
+	return nil
 }
@@ -0,0 +1,91 @@
+package amqp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/suite"
+)
+
+type ChannelTestSuite struct {
+	suite.Suite
+	ctx    context.Context
+	cancel func()
+	ch     *amqp.Channel
+	c      *Channel
+}
+
+func (s *ChannelTestSuite) SetupTest() {
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.ch = &amqp.Channel{}
+
+	s.c = &Channel{
+		conn:   &Connection{done: make(chan struct{})},
+		ch:     s.ch,
+		opened: make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	close(s.c.opened)
+}
+
+func (s *ChannelTestSuite) TearDownTest() {
+	s.cancel()
+}
+
+// waiting returns whether current blocks.
+func (s *ChannelTestSuite) waiting() bool {
+	ctx, cancel := context.WithTimeout(s.ctx, 10*time.Millisecond)
+	defer cancel()
+
+	_, err := s.c.current(ctx)
+
+	return err == context.DeadlineExceeded
+}
+
+func (s *ChannelTestSuite) TestCurrent() {
+	ch, err := s.c.current(s.ctx)
+	s.NoError(err)
+	s.Same(s.ch, ch)
+}
+
+// TestLost tests whether current waits for lost channels to be reopened.
+func (s *ChannelTestSuite) TestLost() {
+	s.c.lost(s.ch)
+	s.True(s.waiting())
+
+	// Marking the channel lost again is a no-op.
+	opened := s.c.opened
+	s.c.lost(s.ch)
+	s.Equal(opened, s.c.opened)
+
+	// Mimic open().
+	reopened := &amqp.Channel{}
+	s.c.ch = reopened
+	close(s.c.opened)
+
+	ch, err := s.c.current(s.ctx)
+	s.NoError(err)
+	s.Same(reopened, ch)
+}
+
+// TestLostStale tests whether losing a channel which has already been replaced is ignored.
+func (s *ChannelTestSuite) TestLostStale() {
+	s.c.lost(&amqp.Channel{})
+	s.False(s.waiting())
+}
+
+// TestClosed tests whether waiting stops when the connection is closed.
+func (s *ChannelTestSuite) TestClosed() {
+	s.c.lost(s.ch)
+
+	close(s.c.conn.done)
+
+	_, err := s.c.current(s.ctx)
+	s.Equal(ErrConnectionClosed, err)
+}
+
+func TestChannelTestSuite(t *testing.T) {
+	suite.Run(t, new(ChannelTestSuite))
+}
@@ -0,0 +1,205 @@
+package amqp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+// ErrNacked is returned when the broker failed to handle a published message.
+var ErrNacked = errors.New("amqp publish not acknowledged by broker")
+
+// ErrConfirmTimeout is returned when the broker did not confirm a published message within ConfirmTimeout.
+var ErrConfirmTimeout = errors.New("timeout waiting for amqp publish confirmation")
+
+// ReturnedError is returned when the broker could not route a published message to a queue.
+type ReturnedError struct {
+	Exchange   string
+	RoutingKey string
+	Code       uint16
+	Text       string
+}
+
+func (e *ReturnedError) Error() string {
+	return fmt.Sprintf("amqp publish to '%s' with routing key '%s' returned: %d %s",
+		e.Exchange, e.RoutingKey, e.Code, e.Text)
+}
+
+// confirmation is a publish awaiting confirmation by the broker.
+type confirmation struct {
+	done chan struct{}
+	err  error
+}
+
+// wait waits for the confirmation, returning ErrConfirmTimeout after timeout.
+func (p *confirmation) wait(ctx context.Context, timeout time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(timeout):
+		return ErrConfirmTimeout
+	case <-p.done:
+		return p.err
+	}
+}
+
+// confirms tracks publishes on a channel in confirm mode, correlating returns by message ID. Confirmations are awaited
+// concurrently, so that concurrent publishes are confirmed in batches by the broker.
+type confirms struct {
+	sem chan struct{} // Bounds unconfirmed publishes.
+
+	mu      sync.Mutex
+	tag     uint64 // Delivery tag of the last publish.
+	pending map[uint64]*confirmation
+	closed  bool
+}
+
+func newConfirms(maxUnconfirmed int) *confirms {
+	return &confirms{
+		sem:     make(chan struct{}, maxUnconfirmed),
+		pending: make(map[uint64]*confirmation),
+	}
+}
+
+// listen puts ch in confirm mode and starts tracking its publishes.
+func (c *confirms) listen(ch *amqp.Channel) error {
+	if err := ch.Confirm(false); err != nil {
+		return err
+	}
+
+	acks := ch.NotifyPublish(make(chan amqp.Confirmation, cap(c.sem)))
+	returns := ch.NotifyReturn(make(chan amqp.Return, cap(c.sem)))
+
+	go c.run(acks, returns)
+
+	return nil
+}
+
+// publish calls send with the delivery tag of the next publish, which it should use as message ID, returning the
+// pending confirmation. It blocks while the maximum of unconfirmed publishes is reached.
+func (c *confirms) publish(ctx context.Context, send func(tag uint64) error) (*confirmation, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case c.sem <- struct{}{}:
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		<-c.sem
+		return nil, amqp.ErrClosed
+	}
+
+	// Tags are assigned in order of publishing, which requires holding the lock while sending.
+	if err := send(c.tag + 1); err != nil {
+		<-c.sem
+		return nil, err
+	}
+
+	c.tag++
+
+	p := &confirmation{done: make(chan struct{})}
+	c.pending[c.tag] = p
+
+	return p, nil
+}
+
+// run resolves confirmations until the channel is closed.
+func (c *confirms) run(acks <-chan amqp.Confirmation, returns <-chan amqp.Return) {
+	for {
+		select {
+		case r, ok := <-returns:
+			if !ok {
+				returns = nil
+				continue
+			}
+
+			c.returned(r)
+
+		case a, ok := <-acks:
+			if !ok {
+				c.close()
+				return
+			}
+
+			// The broker sends returns before confirming the returned message.
+		drain:
+			for {
+				select {
+				case r, ok := <-returns:
+					if !ok {
+						returns = nil
+						break drain
+					}
+
+					c.returned(r)
+				default:
+					break drain
+				}
+			}
+
+			c.confirm(a)
+		}
+	}
+}
+
+func (c *confirms) returned(r amqp.Return) {
+	tag, err := strconv.ParseUint(r.MessageId, 10, 64)
+	if err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if p, ok := c.pending[tag]; ok && p.err == nil {
+		p.err = &ReturnedError{
+			Exchange:   r.Exchange,
+			RoutingKey: r.RoutingKey,
+			Code:       r.ReplyCode,
+			Text:       r.ReplyText,
+		}
+	}
+}
+
+func (c *confirms) confirm(a amqp.Confirmation) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	p, ok := c.pending[a.DeliveryTag]
+	if !ok {
+		return
+	}
+
+	delete(c.pending, a.DeliveryTag)
+
+	if !a.Ack && p.err == nil {
+		p.err = ErrNacked
+	}
+
+	close(p.done)
+	<-c.sem
+}
+
+// close fails unconfirmed publishes with amqp.ErrClosed, as their fate is unknown.
+func (c *confirms) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+
+	for tag, p := range c.pending {
+		delete(c.pending, tag)
+
+		p.err = amqp.ErrClosed
+		close(p.done)
+		<-c.sem
+	}
+}
@@ -0,0 +1,155 @@
+package amqp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/suite"
+)
+
+type ConfirmsTestSuite struct {
+	suite.Suite
+	ctx     context.Context
+	cancel  func()
+	c       *confirms
+	acks    chan amqp.Confirmation
+	returns chan amqp.Return
+}
+
+func (s *ConfirmsTestSuite) SetupTest() {
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.c = newConfirms(2)
+	s.acks = make(chan amqp.Confirmation, 2)
+	s.returns = make(chan amqp.Return, 2)
+}
+
+func (s *ConfirmsTestSuite) TearDownTest() {
+	s.cancel()
+}
+
+func (s *ConfirmsTestSuite) run() {
+	go s.c.run(s.acks, s.returns)
+}
+
+// publish publishes successfully, returning the tag and pending confirmation.
+func (s *ConfirmsTestSuite) publish() (uint64, *confirmation) {
+	var sent uint64
+
+	p, err := s.c.publish(s.ctx, func(tag uint64) error {
+		sent = tag
+		return nil
+	})
+	s.Require().NoError(err)
+
+	return sent, p
+}
+
+func (s *ConfirmsTestSuite) wait(p *confirmation) error {
+	return p.wait(s.ctx, time.Second)
+}
+
+func (s *ConfirmsTestSuite) TestAck() {
+	s.run()
+
+	tag1, p1 := s.publish()
+	tag2, p2 := s.publish()
+	s.Equal(uint64(1), tag1)
+	s.Equal(uint64(2), tag2)
+
+	s.acks <- amqp.Confirmation{DeliveryTag: 2, Ack: true}
+	s.acks <- amqp.Confirmation{DeliveryTag: 1, Ack: true}
+
+	s.NoError(s.wait(p1))
+	s.NoError(s.wait(p2))
+}
+
+func (s *ConfirmsTestSuite) TestNack() {
+	s.run()
+
+	tag, p := s.publish()
+	s.acks <- amqp.Confirmation{DeliveryTag: tag, Ack: false}
+
+	s.Equal(ErrNacked, s.wait(p))
+}
+
+// TestReturned tests whether returns are attributed to their publish, regardless of arriving along with the ack.
+func (s *ConfirmsTestSuite) TestReturned() {
+	tag, p := s.publish()
+
+	s.returns <- amqp.Return{
+		ReplyCode:  312,
+		ReplyText:  "NO_ROUTE",
+		RoutingKey: "missing",
+		MessageId:  "1",
+	}
+	s.acks <- amqp.Confirmation{DeliveryTag: tag, Ack: true}
+
+	s.run()
+
+	err := s.wait(p)
+
+	var returned *ReturnedError
+	s.Require().True(errors.As(err, &returned))
+	s.Equal(uint16(312), returned.Code)
+	s.Equal("missing", returned.RoutingKey)
+}
+
+// TestClosed tests whether unconfirmed publishes fail when the channel is closed.
+func (s *ConfirmsTestSuite) TestClosed() {
+	s.run()
+
+	_, p := s.publish()
+	close(s.acks)
+
+	s.Equal(amqp.ErrClosed, s.wait(p))
+
+	_, err := s.c.publish(s.ctx, func(uint64) error {
+		s.Fail("sending on closed channel")
+		return nil
+	})
+	s.Equal(amqp.ErrClosed, err)
+}
+
+// TestSendError tests whether failing sends do not consume a tag.
+func (s *ConfirmsTestSuite) TestSendError() {
+	s.run()
+
+	sendErr := errors.New("send")
+
+	_, err := s.c.publish(s.ctx, func(uint64) error { return sendErr })
+	s.Equal(sendErr, err)
+
+	tag, _ := s.publish()
+	s.Equal(uint64(1), tag)
+}
+
+// TestMaxUnconfirmed tests whether publishing blocks while the maximum of unconfirmed publishes is reached.
+func (s *ConfirmsTestSuite) TestMaxUnconfirmed() {
+	s.run()
+
+	tag, _ := s.publish()
+	s.publish()
+
+	ctx, cancel := context.WithTimeout(s.ctx, 10*time.Millisecond)
+	defer cancel()
+
+	_, err := s.c.publish(ctx, func(uint64) error { return nil })
+	s.Equal(context.DeadlineExceeded, err)
+
+	s.acks <- amqp.Confirmation{DeliveryTag: tag, Ack: true}
+	s.publish()
+}
+
+func (s *ConfirmsTestSuite) TestTimeout() {
+	s.run()
+
+	_, p := s.publish()
+	s.Equal(ErrConfirmTimeout, p.wait(s.ctx, 10*time.Millisecond))
+}
+
+func TestConfirmsTestSuite(t *testing.T) {
+	suite.Run(t, new(ConfirmsTestSuite))
+}
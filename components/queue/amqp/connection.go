@@ -2,7 +2,9 @@ package amqp
 
 import (
 	"context"
+	"errors"
 	"log"
+	"sync"
 	"time"
 
 	"github.com/streadway/amqp"
@@ -13,10 +15,20 @@ import (
 	"github.com/ipfs-search/ipfs-search/instr"
 )
 
-// Connection wraps an AMQP connection
+// ErrConnectionClosed is returned when using a Connection which has been closed, or which failed to reconnect.
+var ErrConnectionClosed = errors.New("amqp connection closed")
+
+// Connection wraps an AMQP connection, transparently reconnecting when the connection is lost. Channels created from
+// it are reopened after reconnecting.
 type Connection struct {
-	config *Config
-	conn   *amqp.Connection
+	config     *Config
+	amqpConfig *amqp.Config
+
+	mu        sync.Mutex
+	conn      *amqp.Connection
+	connected chan struct{} // Closed while connected, replaced when the connection is lost.
+	done      chan struct{} // Closed when the connection is closed or reconnecting failed.
+
 	*instr.Instrumentation
 }
 
@@ -25,6 +37,11 @@ func NewConnection(ctx context.Context, cfg *Config, amqpConfig *amqp.Config, i
 	ctx, span := i.Tracer.Start(ctx, "queue.amqp.NewConnection", trace.WithAttributes(label.String("amqp_url", cfg.URL)))
 	defer span.End()
 
+	if err := cfg.validate(); err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return nil, err
+	}
+
 	amqpConn, err := amqp.DialConfig(cfg.URL, *amqpConfig)
 
 	if err != nil {
@@ -34,23 +51,62 @@ func NewConnection(ctx context.Context, cfg *Config, amqpConfig *amqp.Config, i
 
 	c := &Connection{
 		config:          cfg,
+		amqpConfig:      amqpConfig,
 		conn:            amqpConn,
+		connected:       make(chan struct{}),
+		done:            make(chan struct{}),
 		Instrumentation: i,
 	}
+	close(c.connected)
 
-	blockChan := amqpConn.NotifyBlocked(make(chan amqp.Blocking, 1))
-	closeChan := amqpConn.NotifyClose(make(chan *amqp.Error, 1))
+	go c.monitor(ctx, amqpConn)
 
-	monitorConn := func() {
-		ctx, span := i.Tracer.Start(ctx, "queue.amqp.monitorConn", trace.WithAttributes(label.Stringer("connection", c)))
-		defer span.End()
+	return c, nil
+}
+
+// reconnect dials until connected, giving up after MaxReconnect consecutive errors.
+func (c *Connection) reconnect(ctx context.Context, span trace.Span) (*amqp.Connection, error) {
+	for errCnt := 0; ; errCnt++ {
+		log.Printf("AMQP connection lost, attempting reconnect in %s", c.config.ReconnectTime)
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-c.done:
+			return nil, ErrConnectionClosed
+		case <-time.After(c.config.ReconnectTime):
+		}
+
+		amqpConn, err := amqp.DialConfig(c.config.URL, *c.amqpConfig)
+		if err == nil {
+			return amqpConn, nil
+		}
 
-		errCnt := 0
+		log.Printf("Error connecting to AMQP: %v", err)
+		span.RecordError(ctx, err)
+
+		if errCnt >= c.config.MaxReconnect {
+			return nil, err
+		}
+	}
+}
+
+// monitor logs blocking of the connection and reconnects when it is lost, until it is closed.
+func (c *Connection) monitor(ctx context.Context, amqpConn *amqp.Connection) {
+	ctx, span := c.Tracer.Start(ctx, "queue.amqp.monitorConn", trace.WithAttributes(label.Stringer("connection", c)))
+	defer span.End()
+
+	for {
+		blockChan := amqpConn.NotifyBlocked(make(chan amqp.Blocking, 1))
+		closeChan := amqpConn.NotifyClose(make(chan *amqp.Error, 1))
+
+	monitoring:
 		for {
 			select {
 			case <-ctx.Done():
 				err := ctx.Err()
 				span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+				c.Close()
 				return
 			case b := <-blockChan:
 				if b.Active {
@@ -63,66 +119,101 @@ func NewConnection(ctx context.Context, cfg *Config, amqpConfig *amqp.Config, i
 					log.Println("AMQP connection unblocked")
 				}
 			case err := <-closeChan:
-				span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
-				log.Printf("AMQP connection lost, attempting reconnect in %s", cfg.ReconnectTime)
-				time.Sleep(cfg.ReconnectTime)
-
-				amqpConn, amqpErr := amqp.Dial(cfg.URL)
-				if amqpErr != nil {
-					if errCnt > cfg.MaxReconnect {
-						// TODO: Proper error propagation/recovery
-						span.RecordError(ctx, amqpErr, trace.WithErrorStatus(codes.Error))
-						panic("Repeated AMQP reconnect errors")
-					} else {
-						errCnt++
-						log.Printf("Error connecting to AMQP: %v", amqpErr)
-						span.RecordError(ctx, amqpErr)
-					}
+				select {
+				case <-c.done:
+					// Closed through Close().
+					return
+				default:
+				}
 
+				// Without error when the connection was lost before being monitored.
+				if err != nil {
+					span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
 				}
 
-				// Set new connection
-				c.conn = amqpConn
+				break monitoring
 			}
 		}
+
+		c.mu.Lock()
+		c.connected = make(chan struct{})
+		c.mu.Unlock()
+
+		var err error
+		if amqpConn, err = c.reconnect(ctx, span); err != nil {
+			span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+			log.Printf("Giving up reconnecting to AMQP: %v", err)
+			c.Close()
+			return
+		}
+
+		span.AddEvent(ctx, "amqp-connection-reconnected")
+		log.Println("AMQP connection reestablished")
+
+		c.mu.Lock()
+		select {
+		case <-c.done:
+			// Closed while reconnecting.
+			c.mu.Unlock()
+			amqpConn.Close()
+			return
+		default:
+		}
+
+		c.conn = amqpConn
+		close(c.connected)
+		c.mu.Unlock()
 	}
-	go monitorConn()
+}
 
-	return c, nil
+// current returns the current connection, waiting while reconnecting.
+func (c *Connection) current(ctx context.Context) (*amqp.Connection, error) {
+	c.mu.Lock()
+	conn, connected := c.conn, c.connected
+	c.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-c.done:
+		return nil, ErrConnectionClosed
+	case <-connected:
+		return conn, nil
+	}
 }
 
-// Channel creates an AMQP channel
+// Channel creates an AMQP channel, which is reopened when it or the connection is lost.
 func (c *Connection) Channel(ctx context.Context, prefetchCount int) (*Channel, error) {
 	ctx, span := c.Tracer.Start(ctx, "queue.amqp.Channel")
 	defer span.End()
 
-	// Create channel
-	ch, err := c.conn.Channel()
-	if err != nil {
-		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
-		return nil, err
+	ch := &Channel{
+		conn:            c,
+		prefetchCount:   prefetchCount,
+		opened:          make(chan struct{}),
+		done:            make(chan struct{}),
+		queues:          make(map[string]*QueueConfig),
+		Instrumentation: c.Instrumentation,
+	}
+
+	conn, err := c.current(ctx)
+	if err == nil {
+		err = ch.open(conn)
 	}
 
-	// Set Qos
-	err = ch.Qos(
-		prefetchCount,
-		0,     // prefetch size
-		false, // global
-	)
 	if err != nil {
 		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
 		return nil, err
 	}
 
-	return &Channel{
-		ch:              ch,
-		Instrumentation: c.Instrumentation,
-	}, nil
+	go ch.monitor(ctx)
+
+	return ch, nil
 }
 
-// NewChannelQueue returns a new queue on a new channel
-func (c *Connection) NewChannelQueue(ctx context.Context, name string, prefetchCount int) (*Queue, error) {
-	ctx, span := c.Tracer.Start(ctx, "queue.amqp.NewChannelQueue", trace.WithAttributes(label.String("queue", name)))
+// NewChannelQueue declares a queue on a new channel
+func (c *Connection) NewChannelQueue(ctx context.Context, cfg *QueueConfig, prefetchCount int) (*Queue, error) {
+	ctx, span := c.Tracer.Start(ctx, "queue.amqp.NewChannelQueue", trace.WithAttributes(label.String("queue", cfg.Name)))
 	defer span.End()
 
 	ch, err := c.Channel(ctx, prefetchCount)
@@ -130,14 +221,37 @@ func (c *Connection) NewChannelQueue(ctx context.Context, name string, prefetchC
 		return nil, err
 	}
 
-	return ch.Queue(ctx, name)
+	q, err := ch.Queue(ctx, cfg)
+	if err != nil {
+		ch.Close()
+		return nil, err
+	}
+
+	return q, nil
 }
 
 func (c *Connection) String() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	return c.conn.LocalAddr().String()
 }
 
-// Close closes the channel
+// Close closes the connection; it is not reconnected afterwards.
 func (c *Connection) Close() error {
-	return c.conn.Close()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return nil
+	default:
+		close(c.done)
+	}
+
+	if err := c.conn.Close(); err != nil && err != amqp.ErrClosed {
+		return err
+	}
+
+	return nil
 }
@@ -0,0 +1,45 @@
+package amqp
+
+import (
+	"github.com/streadway/amqp"
+
+	"github.com/ipfs-search/ipfs-search/components/queue"
+)
+
+// Message wraps an AMQP Delivery.
+type Message struct {
+	d amqp.Delivery
+}
+
+// Body returns the message's payload.
+func (m *Message) Body() []byte {
+	return m.d.Body
+}
+
+// ContentType returns the content type of the message's payload.
+func (m *Message) ContentType() string {
+	return m.d.ContentType
+}
+
+// Headers returns the message's headers, or nil when it has none.
+func (m *Message) Headers() map[string]interface{} {
+	return m.d.Headers
+}
+
+// Priority returns the message's priority.
+func (m *Message) Priority() uint8 {
+	return m.d.Priority
+}
+
+// Ack acknowledges the delivery.
+func (m *Message) Ack() error {
+	return m.d.Ack(false)
+}
+
+// Nack rejects the delivery, requeueing it when requeue is true.
+func (m *Message) Nack(requeue bool) error {
+	return m.d.Reject(requeue)
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ queue.Message = &Message{}
@@ -0,0 +1,68 @@
+package amqp
+
+import (
+	"testing"
+
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type acknowledgerMock struct {
+	mock.Mock
+}
+
+func (m *acknowledgerMock) Ack(tag uint64, multiple bool) error {
+	return m.Called(tag, multiple).Error(0)
+}
+
+func (m *acknowledgerMock) Nack(tag uint64, multiple bool, requeue bool) error {
+	return m.Called(tag, multiple, requeue).Error(0)
+}
+
+func (m *acknowledgerMock) Reject(tag uint64, requeue bool) error {
+	return m.Called(tag, requeue).Error(0)
+}
+
+type MessageTestSuite struct {
+	suite.Suite
+	ack *acknowledgerMock
+	msg *Message
+}
+
+func (s *MessageTestSuite) SetupTest() {
+	s.ack = &acknowledgerMock{}
+	s.ack.Test(s.T())
+
+	s.msg = &Message{amqp.Delivery{
+		Acknowledger: s.ack,
+		DeliveryTag:  42,
+		Headers:      amqp.Table{"key": "value"},
+		Priority:     9,
+		Body:         []byte("body"),
+	}}
+}
+
+func (s *MessageTestSuite) TestFields() {
+	s.Equal([]byte("body"), s.msg.Body())
+	s.Equal(map[string]interface{}{"key": "value"}, s.msg.Headers())
+	s.Equal(uint8(9), s.msg.Priority())
+}
+
+func (s *MessageTestSuite) TestAck() {
+	s.ack.On("Ack", uint64(42), false).Return(nil)
+
+	s.NoError(s.msg.Ack())
+	s.ack.AssertExpectations(s.T())
+}
+
+func (s *MessageTestSuite) TestNack() {
+	s.ack.On("Reject", uint64(42), true).Return(nil)
+
+	s.NoError(s.msg.Nack(true))
+	s.ack.AssertExpectations(s.T())
+}
+
+func TestMessageTestSuite(t *testing.T) {
+	suite.Run(t, new(MessageTestSuite))
+}
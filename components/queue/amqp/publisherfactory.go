@@ -17,7 +17,7 @@ import (
 type PublisherFactory struct {
 	*Config
 	AMQPConfig *amqp.Config
-	Queue      string
+	Queue      *QueueConfig
 	*instr.Instrumentation
 }
 
@@ -25,7 +25,7 @@ type PublisherFactory struct {
 func (f PublisherFactory) NewPublisher(ctx context.Context) (queue.Publisher, error) {
 	ctx, span := f.Tracer.Start(ctx, "queue.amqp.NewPublisher",
 		trace.WithAttributes(label.String("amqp_url", f.Config.URL)),
-		trace.WithAttributes(label.String("queue", f.Queue)),
+		trace.WithAttributes(label.String("queue", f.Queue.Name)),
 	)
 	defer span.End()
 
@@ -2,7 +2,8 @@ package amqp
 
 import (
 	"context"
-	"encoding/json"
+	"log"
+	"time"
 
 	"github.com/streadway/amqp"
 	"go.opentelemetry.io/otel/api/trace"
@@ -10,6 +11,7 @@ import (
 	"go.opentelemetry.io/otel/label"
 
 	"github.com/ipfs-search/ipfs-search/components/queue"
+	"github.com/ipfs-search/ipfs-search/components/queue/codec"
 	"github.com/ipfs-search/ipfs-search/instr"
 )
 
@@ -25,10 +27,20 @@ func (q *Queue) String() string {
 	return q.name
 }
 
-// Publish adds a task with specified params to the Queue
+// Publish adds a task with specified params to the Queue, waiting for the broker to confirm it.
 // priority: higher number, higher priority
-// TODO: Add context parameter, allow for timeouts etc
 func (q *Queue) Publish(ctx context.Context, params interface{}, priority uint8) error {
+	wait, err := q.PublishAsync(ctx, params, priority)
+	if err != nil {
+		return err
+	}
+
+	return wait(ctx)
+}
+
+// PublishAsync adds a task with specified params to the Queue, returning a function which waits for the broker to
+// confirm it. It blocks while MaxUnconfirmed publishes on the channel await confirmation.
+func (q *Queue) PublishAsync(ctx context.Context, params interface{}, priority uint8) (func(context.Context) error, error) {
 	ctx, span := q.Tracer.Start(ctx, "queue.amqp.Publish",
 		trace.WithAttributes(label.String("queue", q.name)),
 		trace.WithAttributes(label.Any("params", params)),
@@ -36,37 +48,33 @@ func (q *Queue) Publish(ctx context.Context, params interface{}, priority uint8)
 	)
 	defer span.End()
 
-	body, err := json.Marshal(params)
+	body, contentType, err := codec.Marshal(q.channel.conn.config.Encoding, params)
 	if err != nil {
 		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
-		return err
+		return nil, err
 	}
 
-	err = q.channel.ch.Publish(
-		"",     // exchange
-		q.name, // routing key
-		true,   // mandatory
-		false,  // immediate
-		amqp.Publishing{
-			DeliveryMode: amqp.Transient,
-			ContentType:  "application/json",
-			Body:         body,
-			Priority:     priority,
-		})
+	wait, err := q.channel.publishAsync(ctx, q.name, amqp.Publishing{
+		DeliveryMode: q.channel.conn.config.deliveryMode(),
+		ContentType:  contentType,
+		Body:         body,
+		Priority:     priority,
+	})
 
 	if err != nil {
 		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
 	}
 
-	return err
+	return wait, err
 }
 
-// Consume consumes messages from a queue
-func (q *Queue) Consume(ctx context.Context) (<-chan amqp.Delivery, error) {
-	ctx, span := q.Tracer.Start(ctx, "queue.amqp.Consume")
-	defer span.End()
+// SetPrefetch sets the amount of messages delivered ahead of being acknowledged, for all queues on its channel.
+func (q *Queue) SetPrefetch(ctx context.Context, count int) error {
+	return q.channel.SetPrefetch(ctx, count)
+}
 
-	c, err := q.channel.ch.Consume(
+func (q *Queue) consume(ch *amqp.Channel) (<-chan amqp.Delivery, error) {
+	return ch.Consume(
 		q.name, // queue
 		"",     // consumer
 		false,  // auto-ack
@@ -75,14 +83,73 @@ func (q *Queue) Consume(ctx context.Context) (<-chan amqp.Delivery, error) {
 		false,  // no-wait
 		nil,    // args
 	)
+}
+
+// Consume consumes messages from a queue, until the context is closed. When the channel is lost, consuming resumes
+// after it has been reopened; unacknowledged messages are then redelivered by the broker.
+func (q *Queue) Consume(ctx context.Context) (<-chan queue.Message, error) {
+	ctx, span := q.Tracer.Start(ctx, "queue.amqp.Consume")
+	defer span.End()
 
+	ch, err := q.channel.current(ctx)
 	if err != nil {
 		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
 		return nil, err
 	}
 
-	return c, err
+	c, err := q.consume(ch)
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return nil, err
+	}
+
+	msgs := make(chan queue.Message)
+
+	go func() {
+		defer close(msgs)
+
+		for {
+			for d := range c {
+				select {
+				case <-ctx.Done():
+					return
+				case msgs <- &Message{d}:
+				}
+			}
+
+			// Deliveries are closed along with the channel; resubscribe once it is reopened.
+			for {
+				ch, err := q.channel.current(ctx)
+				if err != nil {
+					if ctx.Err() == nil {
+						log.Printf("Stopped consuming from %s: %v", q.name, err)
+					}
+
+					return
+				}
+
+				if c, err = q.consume(ch); err == nil {
+					break
+				}
+
+				log.Printf("Error resubscribing to %s: %v", q.name, err)
+				q.channel.lost(ch)
+
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(q.channel.conn.config.ReconnectTime):
+				}
+			}
+		}
+	}()
+
+	return msgs, nil
 }
 
 // Compile-time assurance that implementation satisfies interface.
-var _ queue.Queue = &Queue{}
+var (
+	_ queue.Queue          = &Queue{}
+	_ queue.Prefetcher     = &Queue{}
+	_ queue.AsyncPublisher = &Queue{}
+)
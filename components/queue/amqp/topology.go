@@ -0,0 +1,158 @@
+package amqp
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+// Overflow behaviours for queues which have reached their maximum length.
+const (
+	DropHead         = "drop-head"          // Drop the oldest messages.
+	RejectPublish    = "reject-publish"     // Negatively confirm new messages, failing their publish.
+	RejectPublishDLX = "reject-publish-dlx" // Like RejectPublish, also dead-lettering rejected messages.
+)
+
+var (
+	// ErrUnknownOverflow is returned when an unknown overflow behaviour is configured.
+	ErrUnknownOverflow = errors.New("unknown overflow behaviour")
+
+	// ErrTopologyMismatch is returned when an existing queue or exchange was declared with different arguments.
+	ErrTopologyMismatch = errors.New("existing declaration does not match configuration")
+)
+
+// QueueConfig specifies the declaration of a queue. Zero values of the optional MessageTTL, MaxLength, Overflow and
+// DeadLetterExchange leave the respective queue arguments out of the declaration.
+type QueueConfig struct {
+	Name               string
+	MaxPriority        uint8         // Highest priority supported by the queue.
+	MessageTTL         time.Duration // Time after which messages expire; optional.
+	MaxLength          int           // Maximum amount of messages in the queue; optional.
+	Overflow           string        // Behaviour when MaxLength is reached: DropHead (broker default), RejectPublish or RejectPublishDLX.
+	DeadLetterExchange string        // Fanout exchange receiving rejected and expired messages, bound to a queue of the same name; optional.
+}
+
+// DefaultQueueConfig generates a default configuration for the named queue.
+func DefaultQueueConfig(name string) *QueueConfig {
+	return &QueueConfig{
+		Name:        name,
+		MaxPriority: 9,                  // Enable all 9 priorities
+		MessageTTL:  7 * 24 * time.Hour, // Expire messages after 1 week
+	}
+}
+
+func (c *QueueConfig) validate() error {
+	switch c.Overflow {
+	case "", DropHead, RejectPublish:
+	case RejectPublishDLX:
+		if c.DeadLetterExchange == "" {
+			return fmt.Errorf("invalid configuration for queue %s: %s requires a dead letter exchange", c.Name, c.Overflow)
+		}
+	default:
+		return fmt.Errorf("%w for queue %s: %s", ErrUnknownOverflow, c.Name, c.Overflow)
+	}
+
+	if c.MaxPriority < 1 || c.MessageTTL < 0 || (c.MessageTTL > 0 && c.MessageTTL < time.Millisecond) || c.MaxLength < 0 {
+		return fmt.Errorf("invalid configuration for queue %s: %+v", c.Name, c)
+	}
+
+	return nil
+}
+
+// args returns the arguments with which the queue is declared.
+func (c *QueueConfig) args() amqp.Table {
+	args := amqp.Table{
+		"x-max-priority": int32(c.MaxPriority),
+		"x-queue-mode":   "lazy", // Allow RabbitMQ to write queue to disk as fast as possible
+	}
+
+	if c.MessageTTL != 0 {
+		args["x-message-ttl"] = int32(c.MessageTTL / time.Millisecond)
+	}
+
+	if c.MaxLength != 0 {
+		args["x-max-length"] = int32(c.MaxLength)
+	}
+
+	if c.Overflow != "" {
+		args["x-overflow"] = c.Overflow
+	}
+
+	if c.DeadLetterExchange != "" {
+		args["x-dead-letter-exchange"] = c.DeadLetterExchange
+	}
+
+	return args
+}
+
+// mismatch wraps errors from declarations conflicting with existing ones; the broker closes the channel on them.
+func mismatch(err error, kind, name string) error {
+	var amqpErr *amqp.Error
+	if errors.As(err, &amqpErr) && amqpErr.Code == amqp.PreconditionFailed {
+		return fmt.Errorf("%w: %s %s: %s", ErrTopologyMismatch, kind, name, amqpErr.Reason)
+	}
+
+	return err
+}
+
+// declareDeadLetter declares the fanout exchange dead lettered messages are published to, and binds it to a queue of
+// the same name so that they are kept for inspection rather than dropped.
+func declareDeadLetter(ch *amqp.Channel, name string) error {
+	err := ch.ExchangeDeclare(
+		name,                // name
+		amqp.ExchangeFanout, // kind
+		true,                // durable
+		false,               // delete when unused
+		false,               // internal
+		false,               // no-wait
+		nil,                 // args
+	)
+	if err != nil {
+		return mismatch(err, "exchange", name)
+	}
+
+	_, err = ch.QueueDeclare(
+		name,  // name
+		true,  // durable
+		false, // delete when unused
+		false, // exclusive
+		false, // no-wait
+		amqp.Table{
+			"x-queue-mode": "lazy",
+		},
+	)
+	if err != nil {
+		return mismatch(err, "queue", name)
+	}
+
+	return ch.QueueBind(
+		name,  // queue name
+		"",    // routing key, ignored by fanout exchanges
+		name,  // exchange
+		false, // no-wait
+		nil,   // args
+	)
+}
+
+// declare declares the queue and, when configured, its dead letter exchange and queue, failing with
+// ErrTopologyMismatch when they exist with different arguments.
+func declare(ch *amqp.Channel, cfg *QueueConfig) error {
+	if cfg.DeadLetterExchange != "" {
+		if err := declareDeadLetter(ch, cfg.DeadLetterExchange); err != nil {
+			return err
+		}
+	}
+
+	_, err := ch.QueueDeclare(
+		cfg.Name, // name
+		true,     // durable
+		false,    // delete when unused
+		false,    // exclusive
+		false,    // no-wait
+		cfg.args(),
+	)
+
+	return mismatch(err, "queue", cfg.Name)
+}
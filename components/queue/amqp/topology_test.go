@@ -0,0 +1,96 @@
+package amqp
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/suite"
+)
+
+type TopologyTestSuite struct {
+	suite.Suite
+	cfg *QueueConfig
+}
+
+func (s *TopologyTestSuite) SetupTest() {
+	s.cfg = DefaultQueueConfig("hashes")
+}
+
+func (s *TopologyTestSuite) TestDefaultValid() {
+	s.NoError(s.cfg.validate())
+}
+
+func (s *TopologyTestSuite) TestUnknownOverflow() {
+	s.cfg.Overflow = "drop-tail"
+	s.True(errors.Is(s.cfg.validate(), ErrUnknownOverflow))
+}
+
+func (s *TopologyTestSuite) TestInvalid() {
+	s.cfg.MaxLength = -1
+	s.Error(s.cfg.validate())
+}
+
+func (s *TopologyTestSuite) TestDLXWithoutExchange() {
+	s.cfg.Overflow = RejectPublishDLX
+	s.Error(s.cfg.validate())
+
+	s.cfg.DeadLetterExchange = "ipfs-search.dead-letter"
+	s.NoError(s.cfg.validate())
+}
+
+// TestDefaultArgs tests whether queues are declared with the same arguments as before limits were configurable.
+func (s *TopologyTestSuite) TestDefaultArgs() {
+	args := s.cfg.args()
+	s.NoError(args.Validate())
+
+	s.Equal(amqp.Table{
+		"x-max-priority": int32(9),
+		"x-message-ttl":  int32(604800000),
+		"x-queue-mode":   "lazy",
+	}, args)
+}
+
+func (s *TopologyTestSuite) TestArgs() {
+	s.cfg.MessageTTL = time.Hour
+	s.cfg.MaxLength = 10000000
+	s.cfg.Overflow = RejectPublish
+	s.cfg.DeadLetterExchange = "ipfs-search.dead-letter"
+
+	args := s.cfg.args()
+	s.NoError(args.Validate())
+
+	s.Equal(int32(9), args["x-max-priority"])
+	s.Equal(int32(3600000), args["x-message-ttl"])
+	s.Equal(int32(10000000), args["x-max-length"])
+	s.Equal(RejectPublish, args["x-overflow"])
+	s.Equal("ipfs-search.dead-letter", args["x-dead-letter-exchange"])
+}
+
+func (s *TopologyTestSuite) TestNoTTL() {
+	s.cfg.MessageTTL = 0
+
+	s.NoError(s.cfg.validate())
+	s.NotContains(s.cfg.args(), "x-message-ttl")
+}
+
+func (s *TopologyTestSuite) TestMismatch() {
+	err := mismatch(&amqp.Error{
+		Code:   amqp.PreconditionFailed,
+		Reason: "PRECONDITION_FAILED - inequivalent arg 'x-max-length'",
+	}, "queue", "hashes")
+
+	s.True(errors.Is(err, ErrTopologyMismatch))
+	s.Contains(err.Error(), "queue hashes")
+	s.Contains(err.Error(), "x-max-length")
+}
+
+func (s *TopologyTestSuite) TestOtherErrors() {
+	s.NoError(mismatch(nil, "queue", "hashes"))
+	s.Equal(amqp.ErrClosed, mismatch(amqp.ErrClosed, "queue", "hashes"))
+}
+
+func TestTopologyTestSuite(t *testing.T) {
+	suite.Run(t, new(TopologyTestSuite))
+}
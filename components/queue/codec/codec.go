@@ -0,0 +1,92 @@
+/*
+Package codec encodes and decodes message bodies.
+
+Bodies are encoded as JSON or as CBOR (RFC 7049), which is considerably more compact. The encoding of a body is
+identified by its content type, so that consumers accept both during migration: consumers should be upgraded before
+publishers are configured to encode as CBOR.
+
+CBOR bodies use integer keys, as specified by the cbor tags of the published types; CBORContentType is versioned, so
+that a different numbering can be introduced alongside it.
+*/
+package codec
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// Encodings of message bodies.
+const (
+	JSON = "json" // Human readable, understood by all consumers.
+	CBOR = "cbor" // Compact binary encoding.
+)
+
+// Content types of message bodies.
+const (
+	JSONContentType = "application/json"
+	CBORContentType = "application/vnd.ipfs-search.v1+cbor"
+)
+
+var (
+	// ErrUnknownEncoding is returned when an unknown encoding is configured.
+	ErrUnknownEncoding = errors.New("unknown encoding")
+
+	// ErrUnsupportedContentType is returned when decoding a body of an unsupported content type.
+	ErrUnsupportedContentType = errors.New("unsupported content type")
+)
+
+var (
+	// Times are encoded as epoch floats, rounded to microseconds.
+	encMode, _ = cbor.EncOptions{Time: cbor.TimeUnixMicro}.EncMode()
+	decMode, _ = cbor.DecOptions{}.DecMode()
+)
+
+// Validate returns ErrUnknownEncoding for unknown encodings.
+func Validate(encoding string) error {
+	switch encoding {
+	case JSON, CBOR:
+		return nil
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownEncoding, encoding)
+	}
+}
+
+// Marshal encodes v, returning the body and its content type.
+func Marshal(encoding string, v interface{}) ([]byte, string, error) {
+	switch encoding {
+	case JSON:
+		body, err := json.Marshal(v)
+		return body, JSONContentType, err
+	case CBOR:
+		body, err := encMode.Marshal(v)
+		return body, CBORContentType, err
+	default:
+		return nil, "", fmt.Errorf("%w: %s", ErrUnknownEncoding, encoding)
+	}
+}
+
+// Unmarshal decodes body according to its content type into v. Bodies without content type are decoded as JSON, as
+// they were published before content types were recorded.
+func Unmarshal(contentType string, body []byte, v interface{}) error {
+	if contentType == "" {
+		return json.Unmarshal(body, v)
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrUnsupportedContentType, contentType)
+	}
+
+	switch mediaType {
+	case JSONContentType:
+		return json.Unmarshal(body, v)
+	case CBORContentType:
+		return decMode.Unmarshal(body, v)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedContentType, contentType)
+	}
+}
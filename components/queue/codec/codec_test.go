@@ -0,0 +1,155 @@
+package codec
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// testResource returns a typical resource published to the files queue.
+func testResource() *t.AnnotatedResource {
+	return &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Reference: t.Reference{
+			Parent: &t.Resource{
+				Protocol: t.IPFSProtocol,
+				ID:       "QmeTtFXm42Jb2todcKR538j6qHYxXt6suUzpF3rtT9FPSd",
+			},
+			Name: "README.md",
+		},
+		Stat: t.Stat{
+			Type: t.FileType,
+			Size: 5432,
+		},
+		Source: t.Source{
+			Provider:     "12D3KooWEDoGaVDsFmTLhqexbWXMDMMrPsHK3gnhqBjqHdCp2Fdh",
+			LastProvided: time.Date(2021, 2, 3, 4, 5, 6, 789000000, time.UTC),
+		},
+	}
+}
+
+type CodecTestSuite struct {
+	suite.Suite
+}
+
+func (s *CodecTestSuite) roundtrip(encoding string) {
+	src := testResource()
+
+	body, contentType, err := Marshal(encoding, src)
+	s.Require().NoError(err)
+
+	dst := &t.AnnotatedResource{Resource: &t.Resource{}}
+	s.Require().NoError(Unmarshal(contentType, body, dst))
+
+	s.WithinDuration(src.Source.LastProvided, dst.Source.LastProvided, time.Microsecond)
+	dst.Source.LastProvided = src.Source.LastProvided
+
+	s.Equal(src, dst)
+}
+
+func (s *CodecTestSuite) TestJSON() {
+	s.roundtrip(JSON)
+}
+
+func (s *CodecTestSuite) TestCBOR() {
+	s.roundtrip(CBOR)
+}
+
+func (s *CodecTestSuite) TestContentTypes() {
+	_, contentType, err := Marshal(JSON, testResource())
+	s.NoError(err)
+	s.Equal(JSONContentType, contentType)
+
+	_, contentType, err = Marshal(CBOR, testResource())
+	s.NoError(err)
+	s.Equal(CBORContentType, contentType)
+}
+
+// TestLegacy tests decoding of JSON bodies without content type or with parameters.
+func (s *CodecTestSuite) TestLegacy() {
+	body := []byte(`{"Protocol":1,"ID":"QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp"}`)
+
+	for _, contentType := range []string{"", "application/json; charset=utf-8"} {
+		r := new(t.Resource)
+		s.NoError(Unmarshal(contentType, body, r))
+		s.True(r.IsValid())
+	}
+}
+
+// TestEmbedded tests whether types embedding Resource decode into it, as when deduplicating.
+func (s *CodecTestSuite) TestEmbedded() {
+	p := t.MockProvider()
+
+	body, contentType, err := Marshal(CBOR, &p)
+	s.Require().NoError(err)
+
+	r := new(t.Resource)
+	s.Require().NoError(Unmarshal(contentType, body, r))
+	s.Equal(p.Resource, r)
+}
+
+func (s *CodecTestSuite) TestUnknownEncoding() {
+	s.True(errors.Is(Validate("xml"), ErrUnknownEncoding))
+
+	_, _, err := Marshal("xml", testResource())
+	s.True(errors.Is(err, ErrUnknownEncoding))
+}
+
+func (s *CodecTestSuite) TestUnsupportedContentType() {
+	err := Unmarshal("application/vnd.ipfs-search.v2+cbor", []byte{}, new(t.Resource))
+	s.True(errors.Is(err, ErrUnsupportedContentType))
+}
+
+// TestCompact tests whether CBOR bodies are at least a third smaller than JSON bodies.
+func (s *CodecTestSuite) TestCompact() {
+	j, _, err := Marshal(JSON, testResource())
+	s.Require().NoError(err)
+
+	c, _, err := Marshal(CBOR, testResource())
+	s.Require().NoError(err)
+
+	s.LessOrEqual(3*len(c), 2*len(j))
+}
+
+func TestCodecTestSuite(t *testing.T) {
+	suite.Run(t, new(CodecTestSuite))
+}
+
+func benchmarkMarshal(b *testing.B, encoding string) {
+	r := testResource()
+
+	var body []byte
+	for i := 0; i < b.N; i++ {
+		body, _, _ = Marshal(encoding, r)
+	}
+
+	b.ReportMetric(float64(len(body)), "bytes/msg")
+}
+
+func benchmarkUnmarshal(b *testing.B, encoding string) {
+	body, contentType, err := Marshal(encoding, testResource())
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for i := 0; i < b.N; i++ {
+		r := &t.AnnotatedResource{Resource: &t.Resource{}}
+		if err := Unmarshal(contentType, body, r); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.ReportMetric(float64(len(body)), "bytes/msg")
+}
+
+func BenchmarkMarshalJSON(b *testing.B)   { benchmarkMarshal(b, JSON) }
+func BenchmarkMarshalCBOR(b *testing.B)   { benchmarkMarshal(b, CBOR) }
+func BenchmarkUnmarshalJSON(b *testing.B) { benchmarkUnmarshal(b, JSON) }
+func BenchmarkUnmarshalCBOR(b *testing.B) { benchmarkUnmarshal(b, CBOR) }
@@ -0,0 +1,23 @@
+package dedup
+
+import (
+	"time"
+)
+
+// Config specifies the configuration for deduplication.
+type Config struct {
+	Backend    string        // Set of recently published resources: MemoryBackend, RedisBackend or DisabledBackend.
+	Expiration time.Duration // Time after which a resource may be published again.
+	MaxLen     int           // Maximum amount of resources in a MemorySet.
+	RedisURL   string        // URL of Redis server for RedisBackend: redis://[:password@]host:port[/db]
+}
+
+// DefaultConfig generates a default configuration for deduplication.
+func DefaultConfig() *Config {
+	return &Config{
+		Backend:    MemoryBackend,
+		Expiration: time.Hour,
+		MaxLen:     1000000,
+		RedisURL:   "redis://localhost:6379",
+	}
+}
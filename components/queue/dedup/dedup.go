@@ -0,0 +1,346 @@
+/*
+Package dedup suppresses duplicate resources in queues.
+
+On publishing, resources which have been published to a queue within an expiration are suppressed, as recorded in a
+Set, which may be shared between processes. Resources are identified by their CID; the reference or provider of a
+suppressed duplicate is passed to an optional Updater instead. On consuming, resources which are identical to one being
+processed by another worker are collapsed into it: they are acknowledged right away, without being processed.
+*/
+package dedup
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/api/metric"
+	"go.opentelemetry.io/otel/label"
+
+	"github.com/ipfs-search/ipfs-search/components/queue"
+	"github.com/ipfs-search/ipfs-search/components/queue/codec"
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// Key returns the URI of the resource published as params, or false when it has none.
+func Key(params interface{}) (string, bool) {
+	var r *t.Resource
+
+	switch p := params.(type) {
+	case *t.AnnotatedResource:
+		r = p.Resource
+	case t.AnnotatedResource:
+		r = p.Resource
+	case *t.Provider:
+		r = p.Resource
+	case t.Provider:
+		r = p.Resource
+	case *t.Resource:
+		r = p
+	}
+
+	if r == nil || !r.IsValid() {
+		return "", false
+	}
+
+	return r.URI(), true
+}
+
+// annotated returns the resource published as params with its reference and provider, or nil when it has neither.
+func annotated(params interface{}) *t.AnnotatedResource {
+	var r *t.AnnotatedResource
+
+	switch p := params.(type) {
+	case *t.AnnotatedResource:
+		r = p
+	case t.AnnotatedResource:
+		r = &p
+	case *t.Provider:
+		r = &t.AnnotatedResource{
+			Resource: p.Resource,
+			Source:   t.Source{Provider: p.Provider, LastProvided: p.Date},
+		}
+	case t.Provider:
+		r = &t.AnnotatedResource{
+			Resource: p.Resource,
+			Source:   t.Source{Provider: p.Provider, LastProvided: p.Date},
+		}
+	}
+
+	if r == nil || (r.Reference.Parent == nil && r.Source.Provider == "") {
+		return nil
+	}
+
+	return r
+}
+
+// messageKey returns the URI of the resource in a message, or false when it has none.
+func messageKey(m queue.Message) (string, bool) {
+	r := new(t.Resource)
+
+	// Published types embed Resource.
+	if err := codec.Unmarshal(m.ContentType(), m.Body(), r); err != nil {
+		return "", false
+	}
+
+	return Key(r)
+}
+
+// Stats counts suppressed duplicates for a queue. It is safe for concurrent use.
+type Stats struct {
+	suppressed uint64 // Accessed atomically.
+	collapsed  uint64 // Accessed atomically.
+
+	queue             label.KeyValue
+	suppressedCounter metric.Int64Counter
+	collapsedCounter  metric.Int64Counter
+}
+
+// NewStats returns Stats for the named queue, reporting to the meter of i.
+func NewStats(name string, i *instr.Instrumentation) *Stats {
+	meter := metric.Must(i.Meter)
+
+	return &Stats{
+		queue:             label.String("queue", name),
+		suppressedCounter: meter.NewInt64Counter("queue.dedup.suppressed"),
+		collapsedCounter:  meter.NewInt64Counter("queue.dedup.collapsed"),
+	}
+}
+
+// Suppressed returns the amount of duplicates which were not published.
+func (s *Stats) Suppressed() uint64 {
+	return atomic.LoadUint64(&s.suppressed)
+}
+
+// Collapsed returns the amount of consumed duplicates which were acknowledged without being processed.
+func (s *Stats) Collapsed() uint64 {
+	return atomic.LoadUint64(&s.collapsed)
+}
+
+// Updater records the reference or provider of a suppressed duplicate with the resource, when it has been indexed.
+type Updater interface {
+	Update(ctx context.Context, r *t.AnnotatedResource) error
+}
+
+// Publisher suppresses publishing resources which have been published before.
+type Publisher struct {
+	queue.Publisher
+	set   Set
+	stats *Stats
+
+	// Updater, when set, receives suppressed duplicates with a reference or provider; otherwise these are dropped.
+	Updater Updater
+}
+
+// NewPublisher wraps a Publisher, suppressing resources which are in set.
+func NewPublisher(p queue.Publisher, set Set, stats *Stats) *Publisher {
+	return &Publisher{
+		Publisher: p,
+		set:       set,
+		stats:     stats,
+	}
+}
+
+// update passes the reference or provider of a suppressed duplicate to the Updater. Errors are logged, as the
+// duplicate has been published before.
+func (p *Publisher) update(ctx context.Context, params interface{}) {
+	if p.Updater == nil {
+		return
+	}
+
+	r := annotated(params)
+	if r == nil {
+		return
+	}
+
+	if err := p.Updater.Update(ctx, r); err != nil {
+		log.Printf("Error updating duplicate %v: %v", r, err)
+	}
+}
+
+// Publish publishes a resource unless it has been published before. Errors from the Set are logged, publishing
+// regardless.
+func (p *Publisher) Publish(ctx context.Context, params interface{}, priority uint8) error {
+	key, ok := Key(params)
+	if !ok {
+		return p.Publisher.Publish(ctx, params, priority)
+	}
+
+	added, err := p.set.Add(ctx, key)
+	if err != nil {
+		log.Printf("Error adding %s to deduplication set: %v", key, err)
+		return p.Publisher.Publish(ctx, params, priority)
+	}
+
+	if !added {
+		atomic.AddUint64(&p.stats.suppressed, 1)
+		p.stats.suppressedCounter.Add(ctx, 1, p.stats.queue)
+
+		p.update(ctx, params)
+
+		return nil
+	}
+
+	if err := p.Publisher.Publish(ctx, params, priority); err != nil {
+		// Allow publishing to be retried.
+		if err := p.set.Remove(ctx, key); err != nil {
+			log.Printf("Error removing %s from deduplication set: %v", key, err)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// Consumer collapses consumed resources which are being processed already.
+type Consumer struct {
+	queue.Consumer
+	stats *Stats
+
+	mu       sync.Mutex
+	inflight map[string]struct{}
+}
+
+// NewConsumer wraps a Consumer, collapsing resources which are in flight.
+func NewConsumer(c queue.Consumer, stats *Stats) *Consumer {
+	return &Consumer{
+		Consumer: c,
+		stats:    stats,
+		inflight: make(map[string]struct{}),
+	}
+}
+
+// acquire marks key in flight, returning false when it already is.
+func (c *Consumer) acquire(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.inflight[key]; ok {
+		return false
+	}
+
+	c.inflight[key] = struct{}{}
+
+	return true
+}
+
+func (c *Consumer) release(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.inflight, key)
+}
+
+// Consume consumes messages, acknowledging those with resources in flight right away.
+func (c *Consumer) Consume(ctx context.Context) (<-chan queue.Message, error) {
+	msgs, err := c.Consumer.Consume(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan queue.Message)
+
+	go func() {
+		defer close(out)
+
+		for m := range msgs {
+			key, ok := messageKey(m)
+			if !ok {
+				select {
+				case <-ctx.Done():
+					return
+				case out <- m:
+				}
+
+				continue
+			}
+
+			if !c.acquire(key) {
+				if err := m.Ack(); err != nil {
+					log.Printf("Error acknowledging duplicate %s: %v", key, err)
+				}
+
+				atomic.AddUint64(&c.stats.collapsed, 1)
+				c.stats.collapsedCounter.Add(ctx, 1, c.stats.queue)
+
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				c.release(key)
+				return
+			case out <- &message{Message: m, release: func() { c.release(key) }}:
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// SetPrefetch adjusts prefetching of the wrapped Consumer, when it supports it.
+func (c *Consumer) SetPrefetch(ctx context.Context, count int) error {
+	if p, ok := c.Consumer.(queue.Prefetcher); ok {
+		return p.SetPrefetch(ctx, count)
+	}
+
+	return nil
+}
+
+// message releases its resource once acknowledged.
+type message struct {
+	queue.Message
+	once    sync.Once
+	release func()
+}
+
+func (m *message) Ack() error {
+	defer m.once.Do(m.release)
+	return m.Message.Ack()
+}
+
+func (m *message) Nack(requeue bool) error {
+	defer m.once.Do(m.release)
+	return m.Message.Nack(requeue)
+}
+
+// Queue deduplicates both publishing and consuming.
+type Queue struct {
+	*Publisher
+	*Consumer
+}
+
+// NewQueue wraps a Queue, suppressing resources in set when publishing and collapsing those in flight when consuming.
+func NewQueue(q queue.Queue, set Set, stats *Stats) *Queue {
+	return &Queue{
+		NewPublisher(q, set, stats),
+		NewConsumer(q, stats),
+	}
+}
+
+// PublisherFactory creates deduplicating Publishers.
+type PublisherFactory struct {
+	queue.PublisherFactory
+	Set   Set
+	Stats *Stats
+}
+
+// NewPublisher returns a deduplicating Publisher.
+func (f PublisherFactory) NewPublisher(ctx context.Context) (queue.Publisher, error) {
+	p, err := f.PublisherFactory.NewPublisher(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPublisher(p, f.Set, f.Stats), nil
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var (
+	_ queue.Queue            = &Queue{}
+	_ queue.Prefetcher       = &Queue{}
+	_ queue.Message          = &message{}
+	_ queue.PublisherFactory = PublisherFactory{}
+)
@@ -0,0 +1,211 @@
+package dedup
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/components/queue"
+	"github.com/ipfs-search/ipfs-search/components/queue/memory"
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+type DedupTestSuite struct {
+	suite.Suite
+	ctx    context.Context
+	cancel func()
+	set    *MemorySet
+	stats  *Stats
+}
+
+func (s *DedupTestSuite) SetupTest() {
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.set = NewMemorySet(time.Hour, 10)
+	s.stats = NewStats("hashes", instr.New())
+}
+
+func (s *DedupTestSuite) TearDownTest() {
+	s.cancel()
+}
+
+func resource(id string) *t.AnnotatedResource {
+	return &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       id,
+		},
+	}
+}
+
+func (s *DedupTestSuite) receive(msgs <-chan queue.Message) queue.Message {
+	select {
+	case m := <-msgs:
+		return m
+	case <-time.After(time.Second):
+		s.FailNow("timeout receiving message")
+		return nil
+	}
+}
+
+func referenced(id, parent, name string) *t.AnnotatedResource {
+	r := resource(id)
+	r.Reference = t.Reference{
+		Parent: &t.Resource{Protocol: t.IPFSProtocol, ID: parent},
+		Name:   name,
+	}
+
+	return r
+}
+
+func (s *DedupTestSuite) TestKey() {
+	key, ok := Key(resource("QmA"))
+	s.True(ok)
+	s.Equal("ipfs://QmA", key)
+
+	key, ok = Key(t.Provider{Resource: &t.Resource{Protocol: t.IPFSProtocol, ID: "QmB"}, Provider: "QmPeer"})
+	s.True(ok)
+	s.Equal("ipfs://QmB", key)
+
+	key, ok = Key(referenced("QmA", "QmP", "a.txt"))
+	s.True(ok)
+	s.Equal("ipfs://QmA", key)
+
+	_, ok = Key(&t.AnnotatedResource{})
+	s.False(ok)
+
+	_, ok = Key("QmA")
+	s.False(ok)
+}
+
+// TestMessageKey tests whether consumed messages have the same key as the published resource.
+func (s *DedupTestSuite) TestMessageKey() {
+	r := referenced("QmA", "QmP", "a.txt")
+	r.Source.Provider = "QmPeer"
+
+	expected, _ := Key(r)
+
+	mq := memory.New("hashes", 10, instr.New())
+	s.NoError(mq.Publish(s.ctx, r, 5))
+
+	msgs, err := mq.Consume(s.ctx)
+	s.NoError(err)
+
+	key, ok := messageKey(s.receive(msgs))
+	s.True(ok)
+	s.Equal(expected, key)
+}
+
+type mockUpdater struct {
+	mock.Mock
+}
+
+func (m *mockUpdater) Update(ctx context.Context, r *t.AnnotatedResource) error {
+	args := m.Called(ctx, r)
+	return args.Error(0)
+}
+
+// TestUpdate tests whether the references and providers of suppressed duplicates are passed to the Updater.
+func (s *DedupTestSuite) TestUpdate() {
+	q := &queue.Mock{}
+	q.Test(s.T())
+	q.On("Publish", mock.Anything, mock.Anything, uint8(5)).Return(nil).Once()
+
+	u := &mockUpdater{}
+	u.Test(s.T())
+
+	ref := referenced("QmA", "QmP2", "a.txt")
+	u.On("Update", mock.Anything, ref).Return(nil).Once()
+
+	provider := t.Provider{Resource: &t.Resource{Protocol: t.IPFSProtocol, ID: "QmA"}, Provider: "QmPeer", Date: time.Now()}
+	u.On("Update", mock.Anything, mock.MatchedBy(func(r *t.AnnotatedResource) bool {
+		return r.Resource == provider.Resource &&
+			r.Source.Provider == provider.Provider &&
+			r.Source.LastProvided.Equal(provider.Date)
+	})).Return(errors.New("update")).Once()
+
+	p := NewPublisher(q, s.set, s.stats)
+	p.Updater = u
+
+	s.NoError(p.Publish(s.ctx, referenced("QmA", "QmP1", "a.txt"), 5))
+	s.NoError(p.Publish(s.ctx, ref, 5))
+	s.NoError(p.Publish(s.ctx, provider, 5))
+
+	// Without reference or provider, there is nothing to update.
+	s.NoError(p.Publish(s.ctx, resource("QmA"), 5))
+
+	q.AssertExpectations(s.T())
+	u.AssertExpectations(s.T())
+	s.Equal(uint64(3), s.stats.Suppressed())
+}
+
+func (s *DedupTestSuite) TestSuppress() {
+	q := &queue.Mock{}
+	q.Test(s.T())
+	q.On("Publish", mock.Anything, mock.Anything, uint8(5)).Return(nil).Twice()
+
+	p := NewPublisher(q, s.set, s.stats)
+
+	s.NoError(p.Publish(s.ctx, resource("QmA"), 5))
+	s.NoError(p.Publish(s.ctx, resource("QmA"), 5))
+	s.NoError(p.Publish(s.ctx, resource("QmB"), 5))
+
+	q.AssertExpectations(s.T())
+	s.Equal(uint64(1), s.stats.Suppressed())
+}
+
+// TestPublishError tests whether resources which failed to publish can be published again.
+func (s *DedupTestSuite) TestPublishError() {
+	publishErr := errors.New("publish")
+
+	q := &queue.Mock{}
+	q.Test(s.T())
+	q.On("Publish", mock.Anything, mock.Anything, uint8(5)).Return(publishErr).Once()
+	q.On("Publish", mock.Anything, mock.Anything, uint8(5)).Return(nil).Once()
+
+	p := NewPublisher(q, s.set, s.stats)
+
+	s.Equal(publishErr, p.Publish(s.ctx, resource("QmA"), 5))
+	s.NoError(p.Publish(s.ctx, resource("QmA"), 5))
+
+	q.AssertExpectations(s.T())
+	s.Equal(uint64(0), s.stats.Suppressed())
+}
+
+// TestCollapse tests whether resources in flight are acknowledged without delivery, until they are processed.
+func (s *DedupTestSuite) TestCollapse() {
+	mq := memory.New("hashes", 10, instr.New())
+	c := NewConsumer(mq, s.stats)
+
+	for _, id := range []string{"QmA", "QmA", "QmB"} {
+		s.NoError(mq.Publish(s.ctx, resource(id), 5))
+	}
+
+	msgs, err := c.Consume(s.ctx)
+	s.NoError(err)
+
+	a := s.receive(msgs)
+	b := s.receive(msgs)
+	s.NotEqual(a.Body(), b.Body())
+	s.Equal(uint64(1), s.stats.Collapsed())
+	s.Equal(0, mq.Len())
+
+	s.NoError(a.Ack())
+	s.Error(a.Ack())
+
+	// QmA is no longer in flight.
+	s.NoError(mq.Publish(s.ctx, resource("QmA"), 5))
+	a = s.receive(msgs)
+	s.NoError(a.Nack(false))
+	s.NoError(b.Ack())
+
+	s.Equal(uint64(1), s.stats.Collapsed())
+}
+
+func TestDedupTestSuite(t *testing.T) {
+	suite.Run(t, new(DedupTestSuite))
+}
@@ -0,0 +1,102 @@
+package dedup
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	key     string
+	expires time.Time
+}
+
+// MemorySet is an in-memory Set. Keys are kept in order of addition, so that expired keys can be removed from the
+// front in amortized constant time. The amount of keys is capped at MaxLen; when full, the oldest key is evicted,
+// regardless of whether it has expired. It is safe for concurrent use.
+type MemorySet struct {
+	mu         sync.Mutex
+	keys       map[string]*list.Element
+	order      *list.List // Entries, oldest first.
+	Expiration time.Duration
+	MaxLen     int
+
+	now func() time.Time
+}
+
+// NewMemorySet initialises a new MemorySet.
+func NewMemorySet(expiration time.Duration, maxLen int) *MemorySet {
+	return &MemorySet{
+		keys:       make(map[string]*list.Element),
+		order:      list.New(),
+		Expiration: expiration,
+		MaxLen:     maxLen,
+		now:        time.Now,
+	}
+}
+
+// remove removes an entry; the lock must be held.
+func (s *MemorySet) remove(el *list.Element) {
+	s.order.Remove(el)
+	delete(s.keys, el.Value.(*memoryEntry).key)
+}
+
+// prune removes expired entries and evicts the oldest while full; the lock must be held.
+func (s *MemorySet) prune(now time.Time) {
+	for el := s.order.Front(); el != nil; el = s.order.Front() {
+		if now.Before(el.Value.(*memoryEntry).expires) && s.order.Len() < s.MaxLen {
+			return
+		}
+
+		s.remove(el)
+	}
+}
+
+// Add adds a key, returning whether it was absent.
+func (s *MemorySet) Add(_ context.Context, key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+
+	if el, ok := s.keys[key]; ok {
+		if now.Before(el.Value.(*memoryEntry).expires) {
+			return false, nil
+		}
+
+		s.remove(el)
+	}
+
+	s.prune(now)
+
+	s.keys[key] = s.order.PushBack(&memoryEntry{
+		key:     key,
+		expires: now.Add(s.Expiration),
+	})
+
+	return true, nil
+}
+
+// Remove removes a key.
+func (s *MemorySet) Remove(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if el, ok := s.keys[key]; ok {
+		s.remove(el)
+	}
+
+	return nil
+}
+
+// Len returns the amount of keys, including those expired but not yet removed.
+func (s *MemorySet) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.order.Len()
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ Set = &MemorySet{}
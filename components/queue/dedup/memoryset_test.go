@@ -0,0 +1,65 @@
+package dedup
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type MemorySetTestSuite struct {
+	suite.Suite
+	ctx context.Context
+	now time.Time
+	s   *MemorySet
+}
+
+func (s *MemorySetTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.now = time.Now()
+	s.s = NewMemorySet(time.Minute, 2)
+	s.s.now = func() time.Time { return s.now }
+}
+
+func (s *MemorySetTestSuite) add(key string) bool {
+	added, err := s.s.Add(s.ctx, key)
+	s.Require().NoError(err)
+	return added
+}
+
+func (s *MemorySetTestSuite) TestAdd() {
+	s.True(s.add("a"))
+	s.False(s.add("a"))
+	s.True(s.add("b"))
+}
+
+func (s *MemorySetTestSuite) TestExpire() {
+	s.True(s.add("a"))
+
+	s.now = s.now.Add(time.Minute)
+	s.True(s.add("a"))
+	s.Equal(1, s.s.Len())
+}
+
+func (s *MemorySetTestSuite) TestEvict() {
+	s.True(s.add("a"))
+	s.True(s.add("b"))
+	s.True(s.add("c"))
+	s.Equal(2, s.s.Len())
+
+	// Oldest evicted.
+	s.True(s.add("a"))
+	s.False(s.add("c"))
+}
+
+func (s *MemorySetTestSuite) TestRemove() {
+	s.True(s.add("a"))
+	s.NoError(s.s.Remove(s.ctx, "a"))
+	s.NoError(s.s.Remove(s.ctx, "a"))
+	s.True(s.add("a"))
+}
+
+func TestMemorySetTestSuite(t *testing.T) {
+	suite.Run(t, new(MemorySetTestSuite))
+}
@@ -0,0 +1,52 @@
+package dedup
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/ipfs-search/ipfs-search/utils/redis"
+)
+
+// RedisKeyPrefix is the prefix for keys in which a RedisSet stores keys.
+const RedisKeyPrefix = "ipfs-search:dedup:"
+
+// RedisSet is a Set in a server speaking the Redis protocol, allowing it to be shared between processes. Keys
+// expire after the expiration.
+type RedisSet struct {
+	client     *redis.Client
+	prefix     string
+	Expiration time.Duration
+}
+
+// NewRedisSet initialises a new RedisSet for the named queue.
+func NewRedisSet(client *redis.Client, name string, expiration time.Duration) *RedisSet {
+	return &RedisSet{
+		client:     client,
+		prefix:     RedisKeyPrefix + name + ":",
+		Expiration: expiration,
+	}
+}
+
+// Add adds a key, returning whether it was absent.
+func (s *RedisSet) Add(ctx context.Context, key string) (bool, error) {
+	// Rounded up to whole milliseconds.
+	px := strconv.FormatInt(int64((s.Expiration+time.Millisecond-1)/time.Millisecond), 10)
+
+	reply, err := s.client.Do(ctx, "SET", s.prefix+key, "1", "PX", px, "NX")
+	if err != nil {
+		return false, err
+	}
+
+	// Null reply when the key exists.
+	return reply != nil, nil
+}
+
+// Remove removes a key.
+func (s *RedisSet) Remove(ctx context.Context, key string) error {
+	_, err := s.client.Do(ctx, "DEL", s.prefix+key)
+	return err
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ Set = &RedisSet{}
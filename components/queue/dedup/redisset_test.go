@@ -0,0 +1,69 @@
+package dedup
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/utils/redis"
+	"github.com/ipfs-search/ipfs-search/utils/redis/redistest"
+)
+
+type RedisSetTestSuite struct {
+	suite.Suite
+	ctx    context.Context
+	server *redistest.Server
+	client *redis.Client
+	s      *RedisSet
+}
+
+func (s *RedisSetTestSuite) SetupTest() {
+	s.ctx = context.Background()
+
+	var err error
+	s.server, err = redistest.NewServer()
+	s.Require().NoError(err)
+
+	s.client, err = redis.New(s.server.URL(), nil)
+	s.Require().NoError(err)
+
+	s.s = NewRedisSet(s.client, "hashes", 50*time.Millisecond)
+}
+
+func (s *RedisSetTestSuite) TearDownTest() {
+	s.client.Close()
+	s.server.Close()
+}
+
+func (s *RedisSetTestSuite) add(key string) bool {
+	added, err := s.s.Add(s.ctx, key)
+	s.Require().NoError(err)
+	return added
+}
+
+func (s *RedisSetTestSuite) TestAdd() {
+	s.True(s.add("a"))
+	s.False(s.add("a"))
+	s.True(s.add("b"))
+
+	s.Equal(2, s.server.Len())
+}
+
+func (s *RedisSetTestSuite) TestExpire() {
+	s.True(s.add("a"))
+
+	time.Sleep(60 * time.Millisecond)
+	s.True(s.add("a"))
+}
+
+func (s *RedisSetTestSuite) TestRemove() {
+	s.True(s.add("a"))
+	s.NoError(s.s.Remove(s.ctx, "a"))
+	s.True(s.add("a"))
+}
+
+func TestRedisSetTestSuite(t *testing.T) {
+	suite.Run(t, new(RedisSetTestSuite))
+}
@@ -0,0 +1,49 @@
+package dedup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ipfs-search/ipfs-search/utils/redis"
+)
+
+// Backends for the set of recently published resources.
+const (
+	MemoryBackend   = "memory"   // Per process.
+	RedisBackend    = "redis"    // Shared between processes through Redis.
+	DisabledBackend = "disabled" // No deduplication.
+)
+
+// ErrUnknownBackend is returned when an unknown deduplication backend is configured.
+var ErrUnknownBackend = errors.New("unknown deduplication backend")
+
+// Set is a set of keys, which expire after a while.
+type Set interface {
+	// Add adds a key, returning whether it was absent.
+	Add(ctx context.Context, key string) (bool, error)
+	// Remove removes a key.
+	Remove(ctx context.Context, key string) error
+}
+
+// NewSet returns a Set for the named queue according to cfg, or nil for DisabledBackend.
+func NewSet(cfg *Config, name string) (Set, error) {
+	switch cfg.Backend {
+	case MemoryBackend:
+		return NewMemorySet(cfg.Expiration, cfg.MaxLen), nil
+
+	case RedisBackend:
+		client, err := redis.New(cfg.RedisURL, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewRedisSet(client, name, cfg.Expiration), nil
+
+	case DisabledBackend:
+		return nil, nil
+
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownBackend, cfg.Backend)
+	}
+}
@@ -0,0 +1,80 @@
+// Package factory creates PublisherFactories for queues on the configured backend.
+package factory
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	samqp "github.com/streadway/amqp"
+
+	"github.com/ipfs-search/ipfs-search/components/queue"
+	"github.com/ipfs-search/ipfs-search/components/queue/amqp"
+	"github.com/ipfs-search/ipfs-search/components/queue/dedup"
+	"github.com/ipfs-search/ipfs-search/components/queue/memory"
+	"github.com/ipfs-search/ipfs-search/components/queue/redis"
+	"github.com/ipfs-search/ipfs-search/config"
+	"github.com/ipfs-search/ipfs-search/instr"
+	"github.com/ipfs-search/ipfs-search/utils"
+)
+
+// NewPublisherFactory returns a PublisherFactory for a queue on the configured backend.
+func NewPublisherFactory(ctx context.Context, cfg *config.Config, q config.Queue, i *instr.Instrumentation) (queue.PublisherFactory, error) {
+	switch cfg.Queues.Backend {
+	case queue.AMQPBackend:
+		dialer := &utils.RetryingDialer{
+			Dialer: net.Dialer{
+				Timeout:   30 * time.Second,
+				KeepAlive: 30 * time.Second,
+				DualStack: false,
+			},
+			Context: ctx,
+		}
+
+		return amqp.PublisherFactory{
+			Config:          cfg.AMQPConfig(),
+			AMQPConfig:      &samqp.Config{Dial: dialer.Dial},
+			Queue:           q.AMQPConfig(),
+			Instrumentation: i,
+		}, nil
+
+	case queue.MemoryBackend:
+		return memory.PublisherFactory{
+			Broker: memory.Default(cfg.MemoryQueueConfig()),
+			Queue:  q.Name,
+		}, nil
+
+	case queue.RedisBackend:
+		return redis.PublisherFactory{
+			Config:          cfg.RedisQueueConfig(),
+			Queue:           q.Name,
+			Instrumentation: i,
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("%w: %s", queue.ErrUnknownBackend, cfg.Queues.Backend)
+	}
+}
+
+// NewHashesPublisherFactory returns a PublisherFactory for the hashes queue, deduplicating published resources unless
+// disabled.
+func NewHashesPublisherFactory(ctx context.Context, cfg *config.Config, i *instr.Instrumentation) (queue.PublisherFactory, error) {
+	f, err := NewPublisherFactory(ctx, cfg, cfg.Queues.Hashes, i)
+	if err != nil {
+		return nil, err
+	}
+
+	name := cfg.Queues.Hashes.Name
+
+	set, err := dedup.NewSet(cfg.DedupConfig(), name)
+	if err != nil || set == nil {
+		return f, err
+	}
+
+	return dedup.PublisherFactory{
+		PublisherFactory: f,
+		Set:              set,
+		Stats:            dedup.NewStats(name, i),
+	}, nil
+}
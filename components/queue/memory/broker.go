@@ -0,0 +1,71 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ipfs-search/ipfs-search/components/queue"
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+// Broker holds named in-memory queues, so that publishers and consumers within a process share them.
+type Broker struct {
+	cfg *Config
+
+	mu     sync.Mutex
+	queues map[string]*Queue
+
+	*instr.Instrumentation
+}
+
+// NewBroker returns a new Broker creating queues according to cfg.
+func NewBroker(cfg *Config, i *instr.Instrumentation) *Broker {
+	return &Broker{
+		cfg:             cfg,
+		queues:          make(map[string]*Queue),
+		Instrumentation: i,
+	}
+}
+
+var (
+	defaultOnce   sync.Once
+	defaultBroker *Broker
+)
+
+// Default returns the Broker shared within the process, creating it according to cfg on first use; later
+// configuration is ignored.
+func Default(cfg *Config) *Broker {
+	defaultOnce.Do(func() {
+		defaultBroker = NewBroker(cfg, instr.New())
+	})
+
+	return defaultBroker
+}
+
+// Queue returns the queue with the given name, creating it when necessary.
+func (b *Broker) Queue(name string) *Queue {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	q, ok := b.queues[name]
+	if !ok {
+		q = New(name, b.cfg.Capacity, b.Instrumentation)
+		b.queues[name] = q
+	}
+
+	return q
+}
+
+// PublisherFactory creates Publishers for a queue of a Broker.
+type PublisherFactory struct {
+	Broker *Broker
+	Queue  string
+}
+
+// NewPublisher returns the Broker's queue; it never fails.
+func (f PublisherFactory) NewPublisher(context.Context) (queue.Publisher, error) {
+	return f.Broker.Queue(f.Queue), nil
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ queue.PublisherFactory = PublisherFactory{}
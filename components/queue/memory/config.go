@@ -0,0 +1,13 @@
+package memory
+
+// Config specifies the configuration for in-memory queues.
+type Config struct {
+	Capacity int // Maximum number of messages waiting in a queue, beyond which publishing blocks; see package docs.
+}
+
+// DefaultConfig generates a default configuration for in-memory queues.
+func DefaultConfig() *Config {
+	return &Config{
+		Capacity: 100000,
+	}
+}
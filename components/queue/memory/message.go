@@ -0,0 +1,66 @@
+package memory
+
+import (
+	"sync/atomic"
+
+	"github.com/ipfs-search/ipfs-search/components/queue"
+)
+
+// Message is a message consumed from an in-memory Queue.
+type Message struct {
+	q           *Queue
+	body        []byte
+	contentType string
+	priority    uint8
+	acked       uint32 // Accessed atomically.
+}
+
+// Body returns the message's payload.
+func (m *Message) Body() []byte {
+	return m.body
+}
+
+// ContentType returns the content type of the message's payload.
+func (m *Message) ContentType() string {
+	return m.contentType
+}
+
+// Headers returns nil, as in-memory messages have no headers.
+func (m *Message) Headers() map[string]interface{} {
+	return nil
+}
+
+// Priority returns the message's priority.
+func (m *Message) Priority() uint8 {
+	return m.priority
+}
+
+// Ack acknowledges the message, or returns ErrAcknowledged when it has already been acknowledged.
+func (m *Message) Ack() error {
+	if !atomic.CompareAndSwapUint32(&m.acked, 0, 1) {
+		return ErrAcknowledged
+	}
+
+	return nil
+}
+
+// Nack signals the message has not been processed, requeueing it when requeue is true.
+func (m *Message) Nack(requeue bool) error {
+	if !atomic.CompareAndSwapUint32(&m.acked, 0, 1) {
+		return ErrAcknowledged
+	}
+
+	if requeue {
+		m.q.requeue(&Message{
+			q:           m.q,
+			body:        m.body,
+			contentType: m.contentType,
+			priority:    m.priority,
+		})
+	}
+
+	return nil
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ queue.Message = &Message{}
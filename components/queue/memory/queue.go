@@ -0,0 +1,206 @@
+/*
+Package memory provides in-process queues, so that a single process can run without a message broker.
+
+Messages are consumed highest priority first and in order of publishing within a priority. Queues have a bounded
+capacity: when full, publishing blocks until messages are consumed or the context is closed. Messages are lost when the
+process exits.
+
+Note that directory workers are both the only consumers of the directories queue and publishers to it, with a timeout
+per directory entry. When it is full with all directory workers publishing, listings fail after that timeout and the
+directories being listed are dropped. Hence, the capacity should exceed the amount of subdirectories expected to be
+waiting at any time, i.e. the breadth of the directory trees being crawled.
+*/
+package memory
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+
+	"github.com/ipfs-search/ipfs-search/components/queue"
+	"github.com/ipfs-search/ipfs-search/components/queue/codec"
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+// maxPriority is the highest priority; higher priorities are lowered to it.
+const maxPriority = 9
+
+// ErrAcknowledged is returned when acknowledging a Message more than once.
+var ErrAcknowledged = errors.New("message already acknowledged")
+
+// Queue is an in-memory priority queue. It is safe for concurrent use.
+type Queue struct {
+	name     string
+	capacity int
+
+	mu       sync.Mutex
+	levels   [maxPriority + 1][]*Message // FIFO per priority.
+	len      int
+	notEmpty chan struct{} // Closed and replaced when messages are added.
+	notFull  chan struct{} // Closed and replaced when messages are removed.
+
+	*instr.Instrumentation
+}
+
+// New returns a new Queue holding at most capacity messages.
+func New(name string, capacity int, i *instr.Instrumentation) *Queue {
+	if capacity < 1 {
+		panic("capacity should be at least 1")
+	}
+
+	return &Queue{
+		name:            name,
+		capacity:        capacity,
+		notEmpty:        make(chan struct{}),
+		notFull:         make(chan struct{}),
+		Instrumentation: i,
+	}
+}
+
+// String returns the name of the queue
+func (q *Queue) String() string {
+	return q.name
+}
+
+// Len returns the amount of messages waiting to be consumed.
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.len
+}
+
+// push adds a message; callers should hold the lock.
+func (q *Queue) push(m *Message) {
+	q.levels[m.priority] = append(q.levels[m.priority], m)
+	q.len++
+
+	close(q.notEmpty)
+	q.notEmpty = make(chan struct{})
+}
+
+// pop removes and returns the highest priority message, or nil when empty; callers should hold the lock.
+func (q *Queue) pop() *Message {
+	for p := maxPriority; p >= 0; p-- {
+		if l := q.levels[p]; len(l) > 0 {
+			m := l[0]
+			l[0] = nil
+			q.levels[p] = l[1:]
+			q.len--
+
+			close(q.notFull)
+			q.notFull = make(chan struct{})
+
+			return m
+		}
+	}
+
+	return nil
+}
+
+// requeue adds a message regardless of capacity, so that consumers never block on returning messages.
+func (q *Queue) requeue(m *Message) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.push(m)
+}
+
+// Publish adds a message with params as JSON body, waiting for capacity until the context is closed.
+// priority: higher number, higher priority
+func (q *Queue) Publish(ctx context.Context, params interface{}, priority uint8) error {
+	ctx, span := q.Tracer.Start(ctx, "queue.memory.Publish",
+		trace.WithAttributes(label.String("queue", q.name)),
+		trace.WithAttributes(label.Uint("priority", uint(priority))),
+	)
+	defer span.End()
+
+	// Messages never leave the process, so there is nothing to gain from a compact encoding.
+	body, contentType, err := codec.Marshal(codec.JSON, params)
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return err
+	}
+
+	if priority > maxPriority {
+		priority = maxPriority
+	}
+
+	m := &Message{
+		q:           q,
+		body:        body,
+		contentType: contentType,
+		priority:    priority,
+	}
+
+	for {
+		q.mu.Lock()
+		if q.len < q.capacity {
+			q.push(m)
+			q.mu.Unlock()
+
+			return nil
+		}
+		notFull := q.notFull
+		q.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			span.RecordError(ctx, ctx.Err(), trace.WithErrorStatus(codes.Error))
+			return ctx.Err()
+		case <-notFull:
+		}
+	}
+}
+
+// next waits for and removes the next message, until the context is closed.
+func (q *Queue) next(ctx context.Context) (*Message, error) {
+	for {
+		q.mu.Lock()
+		if m := q.pop(); m != nil {
+			q.mu.Unlock()
+			return m, nil
+		}
+		notEmpty := q.notEmpty
+		q.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-notEmpty:
+		}
+	}
+}
+
+// Consume consumes messages from the queue until the context is closed, after which the channel is closed.
+func (q *Queue) Consume(ctx context.Context) (<-chan queue.Message, error) {
+	msgs := make(chan queue.Message)
+
+	go func() {
+		defer close(msgs)
+
+		for {
+			m, err := q.next(ctx)
+			if err != nil {
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				// Not delivered, return it for other consumers.
+				q.requeue(m)
+				return
+			case msgs <- m:
+			}
+		}
+	}()
+
+	return msgs, nil
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ queue.Queue = &Queue{}
@@ -0,0 +1,143 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/components/queue"
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+type QueueTestSuite struct {
+	suite.Suite
+	ctx    context.Context
+	cancel func()
+	q      *Queue
+}
+
+func (s *QueueTestSuite) SetupTest() {
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.q = New("test", 3, instr.New())
+}
+
+func (s *QueueTestSuite) TearDownTest() {
+	s.cancel()
+}
+
+// receive returns the next message and its decoded body.
+func (s *QueueTestSuite) receive(msgs <-chan queue.Message) (queue.Message, string) {
+	select {
+	case m := <-msgs:
+		var body string
+		s.Require().NoError(json.Unmarshal(m.Body(), &body))
+		return m, body
+	case <-time.After(time.Second):
+		s.FailNow("timeout receiving message")
+		return nil, ""
+	}
+}
+
+// TestPriority tests whether messages are consumed highest priority first, in order of publishing.
+func (s *QueueTestSuite) TestPriority() {
+	s.NoError(s.q.Publish(s.ctx, "low", 1))
+	s.NoError(s.q.Publish(s.ctx, "high1", 9))
+	s.NoError(s.q.Publish(s.ctx, "high2", 12))
+
+	msgs, err := s.q.Consume(s.ctx)
+	s.NoError(err)
+
+	for _, expected := range []string{"high1", "high2", "low"} {
+		m, body := s.receive(msgs)
+		s.Equal(expected, body)
+		s.NoError(m.Ack())
+	}
+
+	s.Equal(0, s.q.Len())
+}
+
+// TestBackpressure tests whether publishing blocks on a full queue until messages are consumed.
+func (s *QueueTestSuite) TestBackpressure() {
+	for i := 0; i < 3; i++ {
+		s.NoError(s.q.Publish(s.ctx, "queued", 5))
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, 10*time.Millisecond)
+	defer cancel()
+	s.Equal(context.DeadlineExceeded, s.q.Publish(ctx, "full", 5))
+
+	published := make(chan error)
+	go func() { published <- s.q.Publish(s.ctx, "blocked", 5) }()
+
+	msgs, err := s.q.Consume(s.ctx)
+	s.NoError(err)
+	s.receive(msgs)
+
+	select {
+	case err := <-published:
+		s.NoError(err)
+	case <-time.After(time.Second):
+		s.Fail("publishing still blocked")
+	}
+}
+
+// TestNack tests whether nacked messages are requeued only when requested.
+func (s *QueueTestSuite) TestNack() {
+	s.NoError(s.q.Publish(s.ctx, "retried", 5))
+	s.NoError(s.q.Publish(s.ctx, "rejected", 5))
+
+	msgs, err := s.q.Consume(s.ctx)
+	s.NoError(err)
+
+	m, body := s.receive(msgs)
+	s.Equal("retried", body)
+	s.NoError(m.Nack(true))
+	s.Equal(ErrAcknowledged, m.Ack())
+
+	m, body = s.receive(msgs)
+	s.Equal("rejected", body)
+	s.NoError(m.Nack(false))
+
+	m, body = s.receive(msgs)
+	s.Equal("retried", body)
+	s.Equal(uint8(5), m.Priority())
+	s.NoError(m.Ack())
+}
+
+// TestConsumeCancel tests whether the channel is closed on context cancellation, keeping undelivered messages.
+func (s *QueueTestSuite) TestConsumeCancel() {
+	ctx, cancel := context.WithCancel(s.ctx)
+
+	msgs, err := s.q.Consume(ctx)
+	s.NoError(err)
+
+	s.NoError(s.q.Publish(s.ctx, "kept", 5))
+	cancel()
+
+	// The message may have been handed to the consumer before cancellation was noticed.
+	delivered := 0
+	for range msgs {
+		delivered++
+	}
+
+	s.Equal(1, s.q.Len()+delivered)
+}
+
+// TestBroker tests whether publishers and consumers share queues by name.
+func (s *QueueTestSuite) TestBroker() {
+	b := NewBroker(&Config{Capacity: 1}, instr.New())
+
+	p, err := PublisherFactory{Broker: b, Queue: "hashes"}.NewPublisher(s.ctx)
+	s.NoError(err)
+	s.NoError(p.Publish(s.ctx, "hash", 9))
+
+	s.Equal(1, b.Queue("hashes").Len())
+	s.Equal(0, b.Queue("files").Len())
+}
+
+func TestQueueTestSuite(t *testing.T) {
+	suite.Run(t, new(QueueTestSuite))
+}
@@ -0,0 +1,22 @@
+package queue
+
+// Message is a consumed message, independent of the broker it was consumed from.
+type Message interface {
+	// Body returns the message's payload.
+	Body() []byte
+
+	// ContentType returns the content type of the message's payload, or an empty string when it has none.
+	ContentType() string
+
+	// Headers returns the message's headers, or nil when it has none.
+	Headers() map[string]interface{}
+
+	// Priority returns the message's priority; higher number, higher priority.
+	Priority() uint8
+
+	// Ack acknowledges the message has been processed.
+	Ack() error
+
+	// Nack signals the message has not been processed, redelivering it when requeue is true.
+	Nack(requeue bool) error
+}
@@ -2,7 +2,6 @@ package queue
 
 import (
 	"context"
-	"github.com/streadway/amqp"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -18,9 +17,50 @@ func (m *Mock) Publish(ctx context.Context, pub interface{}, priority uint8) err
 }
 
 // Consume mocks the corresponding method on the Queue interface.
-func (m *Mock) Consume(ctx context.Context) (<-chan amqp.Delivery, error) {
+func (m *Mock) Consume(ctx context.Context) (<-chan Message, error) {
 	args := m.Called(ctx)
-	return args.Get(0).(<-chan amqp.Delivery), args.Error(1)
+	return args.Get(0).(<-chan Message), args.Error(1)
+}
+
+// MockMessage mocks the Message interface.
+type MockMessage struct {
+	mock.Mock
+}
+
+// Body mocks the corresponding method on the Message interface.
+func (m *MockMessage) Body() []byte {
+	args := m.Called()
+	return args.Get(0).([]byte)
+}
+
+// ContentType mocks the corresponding method on the Message interface.
+func (m *MockMessage) ContentType() string {
+	args := m.Called()
+	return args.String(0)
+}
+
+// Headers mocks the corresponding method on the Message interface.
+func (m *MockMessage) Headers() map[string]interface{} {
+	args := m.Called()
+	return args.Get(0).(map[string]interface{})
+}
+
+// Priority mocks the corresponding method on the Message interface.
+func (m *MockMessage) Priority() uint8 {
+	args := m.Called()
+	return args.Get(0).(uint8)
+}
+
+// Ack mocks the corresponding method on the Message interface.
+func (m *MockMessage) Ack() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+// Nack mocks the corresponding method on the Message interface.
+func (m *MockMessage) Nack(requeue bool) error {
+	args := m.Called(requeue)
+	return args.Error(0)
 }
 
 // MockFactory mocks the Factory interface.
@@ -36,4 +76,5 @@ func (f *MockFactory) NewPublisher(ctx context.Context) (Publisher, error) {
 
 // Compile-time assurance that implementation satisfies interface.
 var _ Queue = &Mock{}
+var _ Message = &MockMessage{}
 var _ PublisherFactory = &MockFactory{}
@@ -3,17 +3,40 @@ package queue
 
 import (
 	"context"
-	"github.com/streadway/amqp"
+	"errors"
 )
 
+// Backends implementing Queue.
+const (
+	AMQPBackend   = "amqp"   // RabbitMQ or another AMQP 0.9.1 broker.
+	MemoryBackend = "memory" // In-process queues, for single-process deployments and tests.
+	RedisBackend  = "redis"  // Redis Streams.
+)
+
+// ErrUnknownBackend is returned when an unknown queue backend is configured.
+var ErrUnknownBackend = errors.New("unknown queue backend")
+
 // Publisher allows publishing of sniffed items.
 type Publisher interface {
 	Publish(context.Context, interface{}, uint8) error
 }
 
+// AsyncPublisher is implemented by Publishers which can publish without waiting for each item to be confirmed, so
+// that confirmations of many items can be awaited at once.
+type AsyncPublisher interface {
+	// PublishAsync publishes like Publish, returning a function which waits for the item to be confirmed.
+	PublishAsync(context.Context, interface{}, uint8) (func(context.Context) error, error)
+}
+
 // Consumer allows consuming of published items.
 type Consumer interface {
-	Consume(context.Context) (<-chan amqp.Delivery, error)
+	Consume(context.Context) (<-chan Message, error)
+}
+
+// Prefetcher is implemented by Consumers of which the amount of messages delivered ahead of being acknowledged can be
+// adjusted.
+type Prefetcher interface {
+	SetPrefetch(context.Context, int) error
 }
 
 // PublisherFactory creates Publishers.
@@ -0,0 +1,30 @@
+package redis
+
+import (
+	"time"
+
+	"github.com/ipfs-search/ipfs-search/components/queue/codec"
+)
+
+// Config specifies the configuration for Redis Streams queues.
+type Config struct {
+	URL          string        // URL of the Redis server, of the form redis://[:password@]host:port[/db].
+	Group        string        // Consumer group shared by consumers of a queue.
+	Bands        int           // Number of streams per queue, approximating priorities.
+	MaxLen       int           // Approximate maximum length of a stream, trimming the oldest entries, consumed or not; 0 disables.
+	PollInterval time.Duration // Time to wait in between polls when a queue is empty.
+	ClaimTimeout time.Duration // Time after which unacknowledged messages are redelivered to another consumer.
+	Encoding     string        // Encoding of published messages, codec.JSON or codec.CBOR.
+}
+
+// DefaultConfig generates a default configuration for Redis Streams queues.
+func DefaultConfig() *Config {
+	return &Config{
+		URL:          "redis://localhost:6379",
+		Group:        "ipfs-search",
+		Bands:        3,
+		PollInterval: 100 * time.Millisecond,
+		ClaimTimeout: 10 * time.Minute,
+		Encoding:     codec.JSON,
+	}
+}
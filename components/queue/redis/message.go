@@ -0,0 +1,82 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+
+	"github.com/ipfs-search/ipfs-search/components/queue"
+)
+
+// ErrAcknowledged is returned when acknowledging a Message more than once.
+var ErrAcknowledged = errors.New("message already acknowledged")
+
+// Message is a message consumed from a Redis Streams Queue.
+type Message struct {
+	q           *Queue
+	band        int
+	id          string
+	body        []byte
+	contentType string
+	priority    uint8
+	acked       uint32 // Accessed atomically.
+}
+
+// Body returns the message's payload.
+func (m *Message) Body() []byte {
+	return m.body
+}
+
+// ContentType returns the content type of the message's payload, or an empty string for messages published without.
+func (m *Message) ContentType() string {
+	return m.contentType
+}
+
+// Headers returns the stream and entry ID of the message.
+func (m *Message) Headers() map[string]interface{} {
+	return map[string]interface{}{
+		"stream": m.q.stream(m.band),
+		"id":     m.id,
+	}
+}
+
+// Priority returns the message's priority.
+func (m *Message) Priority() uint8 {
+	return m.priority
+}
+
+func (m *Message) ack(ctx context.Context) error {
+	_, err := m.q.client.Do(ctx, "XACK", m.q.stream(m.band), m.q.cfg.Group, m.id)
+	return err
+}
+
+// Ack acknowledges the message, or returns ErrAcknowledged when it has already been acknowledged.
+func (m *Message) Ack() error {
+	if !atomic.CompareAndSwapUint32(&m.acked, 0, 1) {
+		return ErrAcknowledged
+	}
+
+	return m.ack(context.Background())
+}
+
+// Nack signals the message has not been processed, discarding it or, when requeue is true, publishing it again at the
+// end of its band.
+func (m *Message) Nack(requeue bool) error {
+	if !atomic.CompareAndSwapUint32(&m.acked, 0, 1) {
+		return ErrAcknowledged
+	}
+
+	ctx := context.Background()
+
+	if requeue {
+		if err := m.q.add(ctx, m.band, m.body, m.contentType, m.priority); err != nil {
+			// The message remains pending, to be claimed after ClaimTimeout.
+			return err
+		}
+	}
+
+	return m.ack(ctx)
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ queue.Message = &Message{}
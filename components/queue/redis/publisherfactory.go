@@ -0,0 +1,55 @@
+package redis
+
+import (
+	"context"
+	"log"
+
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+
+	"github.com/ipfs-search/ipfs-search/components/queue"
+	"github.com/ipfs-search/ipfs-search/instr"
+	"github.com/ipfs-search/ipfs-search/utils/redis"
+)
+
+// PublisherFactory automates creation of Redis Streams Publishers.
+type PublisherFactory struct {
+	*Config
+	Dial  redis.DialContextFunc // Optional; defaults to net.Dialer.
+	Queue string
+	*instr.Instrumentation
+}
+
+// NewPublisher generates a new publisher or returns an error.
+func (f PublisherFactory) NewPublisher(ctx context.Context) (queue.Publisher, error) {
+	ctx, span := f.Tracer.Start(ctx, "queue.redis.NewPublisher",
+		trace.WithAttributes(label.String("queue", f.Queue)),
+	)
+	defer span.End()
+
+	client, err := redis.New(f.Config.URL, f.Dial)
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return nil, err
+	}
+
+	q, err := New(ctx, client, f.Queue, f.Config, f.Instrumentation)
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		client.Close()
+		return nil, err
+	}
+
+	// Close connection when context closes
+	go func() {
+		<-ctx.Done()
+		log.Printf("Closing Redis connection; context closed")
+		client.Close()
+	}()
+
+	return q, nil
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ queue.PublisherFactory = PublisherFactory{}
@@ -0,0 +1,324 @@
+/*
+Package redis provides durable queues on Redis Streams, using consumer groups for acknowledgement and redelivery.
+
+Redis Streams have no notion of priority. Instead, every queue is made up of a number of streams (bands), each holding a
+range of priorities; consumers read from the highest band with pending messages. Within a band, messages are consumed in
+order of publishing.
+
+Messages which are not acknowledged within ClaimTimeout, for example because a consumer died, are claimed and
+redelivered by other consumers. Messages are thus delivered at least once.
+*/
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+
+	"github.com/ipfs-search/ipfs-search/components/queue"
+	"github.com/ipfs-search/ipfs-search/components/queue/codec"
+	"github.com/ipfs-search/ipfs-search/instr"
+	"github.com/ipfs-search/ipfs-search/utils/redis"
+)
+
+// maxPriority is the highest priority; higher priorities are lowered to it.
+const maxPriority = 9
+
+var errUnexpectedReply = errors.New("unexpected reply")
+
+// Queue is a priority queue on Redis Streams.
+type Queue struct {
+	name     string
+	client   *redis.Client
+	cfg      *Config
+	consumer string
+
+	*instr.Instrumentation
+}
+
+// consumerName identifies the process within consumer groups.
+func consumerName() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
+// New returns a Queue, creating its streams and consumer groups when they do not exist.
+func New(ctx context.Context, client *redis.Client, name string, cfg *Config, i *instr.Instrumentation) (*Queue, error) {
+	if cfg.Bands < 1 {
+		return nil, fmt.Errorf("invalid amount of bands: %d", cfg.Bands)
+	}
+
+	if err := codec.Validate(cfg.Encoding); err != nil {
+		return nil, err
+	}
+
+	q := &Queue{
+		name:            name,
+		client:          client,
+		cfg:             cfg,
+		consumer:        consumerName(),
+		Instrumentation: i,
+	}
+
+	for band := 0; band < cfg.Bands; band++ {
+		_, err := client.Do(ctx, "XGROUP", "CREATE", q.stream(band), cfg.Group, "0", "MKSTREAM")
+
+		var e redis.Error
+		if errors.As(err, &e) && strings.HasPrefix(string(e), "BUSYGROUP") {
+			// Group already exists.
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("creating consumer group for %s: %w", q.stream(band), err)
+		}
+	}
+
+	return q, nil
+}
+
+// String returns the name of the queue
+func (q *Queue) String() string {
+	return q.name
+}
+
+// stream returns the key of the stream for a band.
+func (q *Queue) stream(band int) string {
+	return fmt.Sprintf("%s:%d", q.name, band)
+}
+
+// band returns the band holding messages of a priority.
+func (q *Queue) band(priority uint8) int {
+	if priority > maxPriority {
+		priority = maxPriority
+	}
+
+	return int(priority) * q.cfg.Bands / (maxPriority + 1)
+}
+
+func (q *Queue) add(ctx context.Context, band int, body []byte, contentType string, priority uint8) error {
+	args := []string{"XADD", q.stream(band)}
+
+	if q.cfg.MaxLen > 0 {
+		args = append(args, "MAXLEN", "~", strconv.Itoa(q.cfg.MaxLen))
+	}
+
+	args = append(args, "*",
+		"body", string(body),
+		"content_type", contentType,
+		"priority", strconv.Itoa(int(priority)),
+	)
+
+	_, err := q.client.Do(ctx, args...)
+
+	return err
+}
+
+// Publish adds a task with specified params to the Queue
+// priority: higher number, higher priority
+func (q *Queue) Publish(ctx context.Context, params interface{}, priority uint8) error {
+	ctx, span := q.Tracer.Start(ctx, "queue.redis.Publish",
+		trace.WithAttributes(label.String("queue", q.name)),
+		trace.WithAttributes(label.Any("params", params)),
+		trace.WithAttributes(label.Uint("priority", uint(priority))),
+	)
+	defer span.End()
+
+	body, contentType, err := codec.Marshal(q.cfg.Encoding, params)
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return err
+	}
+
+	if err := q.add(ctx, q.band(priority), body, contentType, priority); err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return err
+	}
+
+	return nil
+}
+
+// parseEntry parses a stream entry of the form [id, [field, value, ...]] into a Message.
+func (q *Queue) parseEntry(band int, reply interface{}) (*Message, error) {
+	entry, ok := reply.([]interface{})
+	if !ok || len(entry) != 2 {
+		return nil, fmt.Errorf("%w: entry %v", errUnexpectedReply, reply)
+	}
+
+	id, ok := entry[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: entry id %v", errUnexpectedReply, entry[0])
+	}
+
+	fields, ok := entry[1].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: entry fields %v", errUnexpectedReply, entry[1])
+	}
+
+	m := &Message{
+		q:    q,
+		band: band,
+		id:   id,
+	}
+
+	for i := 0; i+1 < len(fields); i += 2 {
+		k, _ := fields[i].(string)
+		v, _ := fields[i+1].(string)
+
+		switch k {
+		case "body":
+			m.body = []byte(v)
+		case "content_type":
+			m.contentType = v
+		case "priority":
+			p, err := strconv.ParseUint(v, 10, 8)
+			if err != nil {
+				return nil, fmt.Errorf("%w: priority %s", errUnexpectedReply, v)
+			}
+			m.priority = uint8(p)
+		}
+	}
+
+	return m, nil
+}
+
+// read returns a new message from a band, or nil when there is none.
+func (q *Queue) read(ctx context.Context, band int) (*Message, error) {
+	reply, err := q.client.Do(ctx, "XREADGROUP", "GROUP", q.cfg.Group, q.consumer,
+		"COUNT", "1", "STREAMS", q.stream(band), ">")
+	if err != nil || reply == nil {
+		return nil, err
+	}
+
+	// Reply: [[stream, [entry, ...]], ...]
+	streams, ok := reply.([]interface{})
+	if !ok || len(streams) == 0 {
+		return nil, fmt.Errorf("%w: %v", errUnexpectedReply, reply)
+	}
+
+	stream, ok := streams[0].([]interface{})
+	if !ok || len(stream) != 2 {
+		return nil, fmt.Errorf("%w: %v", errUnexpectedReply, streams[0])
+	}
+
+	entries, ok := stream[1].([]interface{})
+	if !ok || len(entries) == 0 {
+		return nil, nil
+	}
+
+	return q.parseEntry(band, entries[0])
+}
+
+// claim returns a message from a band which has not been acknowledged within ClaimTimeout, or nil when there is none.
+func (q *Queue) claim(ctx context.Context, band int) (*Message, error) {
+	minIdle := strconv.FormatInt(int64(q.cfg.ClaimTimeout/time.Millisecond), 10)
+
+	reply, err := q.client.Do(ctx, "XAUTOCLAIM", q.stream(band), q.cfg.Group, q.consumer,
+		minIdle, "0-0", "COUNT", "1")
+	if err != nil {
+		return nil, err
+	}
+
+	// Reply: [next, [entry, ...]] or, as of Redis 7, [next, [entry, ...], [deleted id, ...]]
+	r, ok := reply.([]interface{})
+	if !ok || len(r) < 2 {
+		return nil, fmt.Errorf("%w: %v", errUnexpectedReply, reply)
+	}
+
+	entries, ok := r[1].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: %v", errUnexpectedReply, r[1])
+	}
+
+	for _, e := range entries {
+		// Trimmed entries are returned as null by Redis 6.2.
+		if e != nil {
+			return q.parseEntry(band, e)
+		}
+	}
+
+	return nil, nil
+}
+
+// next returns the next message, highest band first, or nil when there is none. Unacknowledged messages are claimed
+// at most once every ClaimTimeout, before reading new messages.
+func (q *Queue) next(ctx context.Context, lastClaim *time.Time) (*Message, error) {
+	if time.Since(*lastClaim) >= q.cfg.ClaimTimeout {
+		for band := q.cfg.Bands - 1; band >= 0; band-- {
+			m, err := q.claim(ctx, band)
+			if m != nil || err != nil {
+				return m, err
+			}
+		}
+
+		// Only reset after all stale messages have been claimed.
+		*lastClaim = time.Now()
+	}
+
+	for band := q.cfg.Bands - 1; band >= 0; band-- {
+		m, err := q.read(ctx, band)
+		if m != nil || err != nil {
+			return m, err
+		}
+	}
+
+	return nil, nil
+}
+
+// Consume consumes messages from a queue, until the context is closed.
+func (q *Queue) Consume(ctx context.Context) (<-chan queue.Message, error) {
+	ctx, span := q.Tracer.Start(ctx, "queue.redis.Consume",
+		trace.WithAttributes(label.String("queue", q.name)),
+	)
+	defer span.End()
+
+	msgs := make(chan queue.Message)
+
+	go func() {
+		defer close(msgs)
+
+		var lastClaim time.Time
+
+		for {
+			m, err := q.next(ctx, &lastClaim)
+
+			if err != nil && ctx.Err() == nil {
+				log.Printf("Error consuming from %s: %v", q.name, err)
+			}
+
+			if m == nil {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(q.cfg.PollInterval):
+					continue
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				// The message remains pending and is claimed by another consumer after ClaimTimeout.
+				return
+			case msgs <- m:
+			}
+		}
+	}()
+
+	return msgs, nil
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ queue.Queue = &Queue{}
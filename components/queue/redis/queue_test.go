@@ -0,0 +1,314 @@
+package redis
+
+import (
+	"context"
+	"net"
+	"os/exec"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/components/queue"
+	"github.com/ipfs-search/ipfs-search/components/queue/codec"
+	"github.com/ipfs-search/ipfs-search/instr"
+	"github.com/ipfs-search/ipfs-search/utils/redis"
+	"github.com/ipfs-search/ipfs-search/utils/redis/redistest"
+)
+
+// testServer is a server speaking the Redis protocol, which can be inspected.
+type testServer interface {
+	URL() string
+	StreamLen(key string) int
+	Pending(key, group string) int
+	Close() error
+}
+
+type QueueTestSuite struct {
+	suite.Suite
+	ctx       context.Context
+	cancel    func()
+	newServer func() (testServer, error)
+	server    testServer
+	client    *redis.Client
+	cfg       *Config
+	q         *Queue
+}
+
+func (s *QueueTestSuite) SetupTest() {
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+
+	var err error
+	s.server, err = s.newServer()
+	s.Require().NoError(err)
+
+	s.client, err = redis.New(s.server.URL(), nil)
+	s.Require().NoError(err)
+
+	s.cfg = DefaultConfig()
+	s.cfg.PollInterval = time.Millisecond
+
+	s.q, err = New(s.ctx, s.client, "test", s.cfg, instr.New())
+	s.Require().NoError(err)
+}
+
+func (s *QueueTestSuite) TearDownTest() {
+	s.cancel()
+	s.client.Close()
+	s.server.Close()
+}
+
+// receive returns the next message and its decoded body.
+func (s *QueueTestSuite) receive(msgs <-chan queue.Message) (queue.Message, string) {
+	select {
+	case m := <-msgs:
+		var body string
+		s.Require().NoError(codec.Unmarshal(m.ContentType(), m.Body(), &body))
+		return m, body
+	case <-time.After(time.Second):
+		s.FailNow("timeout receiving message")
+		return nil, ""
+	}
+}
+
+// pending returns the total amount of unacknowledged messages.
+func (s *QueueTestSuite) pending() int {
+	n := 0
+	for band := 0; band < s.cfg.Bands; band++ {
+		n += s.server.Pending(s.q.stream(band), s.cfg.Group)
+	}
+
+	return n
+}
+
+// TestExistingGroup tests whether queues can be created for existing consumer groups.
+func (s *QueueTestSuite) TestExistingGroup() {
+	_, err := New(s.ctx, s.client, "test", s.cfg, instr.New())
+	s.NoError(err)
+}
+
+// TestEncoding tests whether the content type is recorded, also for requeued messages.
+func (s *QueueTestSuite) TestEncoding() {
+	s.cfg.Encoding = codec.CBOR
+
+	s.Require().NoError(s.q.Publish(s.ctx, "a", 0))
+
+	msgs, err := s.q.Consume(s.ctx)
+	s.Require().NoError(err)
+
+	m, body := s.receive(msgs)
+	s.Equal(codec.CBORContentType, m.ContentType())
+	s.Equal("a", body)
+
+	s.Require().NoError(m.Nack(true))
+
+	m, body = s.receive(msgs)
+	s.Equal(codec.CBORContentType, m.ContentType())
+	s.Equal("a", body)
+}
+
+// TestPriority tests whether messages are consumed highest band first, in order of publishing within a band.
+func (s *QueueTestSuite) TestPriority() {
+	s.NoError(s.q.Publish(s.ctx, "low", 1))
+	s.NoError(s.q.Publish(s.ctx, "high1", 9))
+	s.NoError(s.q.Publish(s.ctx, "high2", 12))
+	s.NoError(s.q.Publish(s.ctx, "medium", 5))
+
+	s.Equal(1, s.server.StreamLen("test:0"))
+	s.Equal(1, s.server.StreamLen("test:1"))
+	s.Equal(2, s.server.StreamLen("test:2"))
+
+	msgs, err := s.q.Consume(s.ctx)
+	s.NoError(err)
+
+	for _, expected := range []string{"high1", "high2", "medium", "low"} {
+		m, body := s.receive(msgs)
+		s.Equal(expected, body)
+		s.NoError(m.Ack())
+	}
+
+	s.Equal(0, s.pending())
+}
+
+// TestAckTwice tests whether acknowledging twice fails.
+func (s *QueueTestSuite) TestAckTwice() {
+	s.NoError(s.q.Publish(s.ctx, "msg", 5))
+
+	msgs, err := s.q.Consume(s.ctx)
+	s.NoError(err)
+
+	m, _ := s.receive(msgs)
+	s.Equal(uint8(5), m.Priority())
+	s.Equal(1, s.pending())
+
+	s.NoError(m.Ack())
+	s.Equal(ErrAcknowledged, m.Ack())
+	s.Equal(ErrAcknowledged, m.Nack(true))
+	s.Equal(0, s.pending())
+}
+
+// TestNack tests whether nacked messages are only redelivered when requeued.
+func (s *QueueTestSuite) TestNack() {
+	s.NoError(s.q.Publish(s.ctx, "requeued", 5))
+	s.NoError(s.q.Publish(s.ctx, "discarded", 5))
+
+	msgs, err := s.q.Consume(s.ctx)
+	s.NoError(err)
+
+	m, body := s.receive(msgs)
+	s.Equal("requeued", body)
+	s.NoError(m.Nack(true))
+
+	m, body = s.receive(msgs)
+	s.Equal("discarded", body)
+	s.NoError(m.Nack(false))
+
+	m, body = s.receive(msgs)
+	s.Equal("requeued", body)
+	s.Equal(uint8(5), m.Priority())
+	s.NoError(m.Ack())
+
+	s.Equal(0, s.pending())
+}
+
+// TestRedeliver tests whether messages left unacknowledged are redelivered after ClaimTimeout.
+func (s *QueueTestSuite) TestRedeliver() {
+	s.cfg.ClaimTimeout = 50 * time.Millisecond
+	s.NoError(s.q.Publish(s.ctx, "unacked", 5))
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	msgs, err := s.q.Consume(ctx)
+	s.NoError(err)
+
+	s.receive(msgs)
+	cancel()
+
+	msgs, err = s.q.Consume(s.ctx)
+	s.NoError(err)
+
+	select {
+	case <-msgs:
+		s.FailNow("message redelivered before ClaimTimeout")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	m, body := s.receive(msgs)
+	s.Equal("unacked", body)
+	s.NoError(m.Ack())
+	s.Equal(0, s.pending())
+}
+
+func TestQueueTestSuite(t *testing.T) {
+	suite.Run(t, &QueueTestSuite{
+		newServer: func() (testServer, error) {
+			return redistest.NewServer()
+		},
+	})
+}
+
+// TestRedisServerQueueTestSuite runs the tests against a local redis-server (6.2 or later), when installed.
+func TestRedisServerQueueTestSuite(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping redis-server in short mode")
+	}
+
+	path, err := exec.LookPath("redis-server")
+	if err != nil {
+		t.Skip("redis-server not installed")
+	}
+
+	suite.Run(t, &QueueTestSuite{
+		newServer: func() (testServer, error) {
+			return startRedisServer(path)
+		},
+	})
+}
+
+// redisServer is a redis-server process, listening on a random port on the loopback interface.
+type redisServer struct {
+	cmd    *exec.Cmd
+	url    string
+	client *redis.Client
+}
+
+func startRedisServer(path string) (*redisServer, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	port := strconv.Itoa(l.Addr().(*net.TCPAddr).Port)
+	l.Close()
+
+	cmd := exec.Command(path, "--bind", "127.0.0.1", "--port", port, "--save", "", "--appendonly", "no")
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	s := &redisServer{
+		cmd: cmd,
+		url: "redis://127.0.0.1:" + port,
+	}
+
+	if s.client, err = redis.New(s.url, nil); err != nil {
+		s.Close()
+		return nil, err
+	}
+
+	// Wait for the server to accept connections.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		_, err := s.client.Do(ctx, "PING")
+		cancel()
+
+		if err == nil {
+			return s, nil
+		}
+
+		if time.Now().After(deadline) {
+			s.Close()
+			return nil, err
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func (s *redisServer) URL() string {
+	return s.url
+}
+
+func (s *redisServer) StreamLen(key string) int {
+	n, err := s.client.Int(context.Background(), "XLEN", key)
+	if err != nil {
+		panic(err)
+	}
+
+	return int(n)
+}
+
+func (s *redisServer) Pending(key, group string) int {
+	reply, err := s.client.Do(context.Background(), "XPENDING", key, group)
+	if err != nil {
+		panic(err)
+	}
+
+	// Summary form: amount of pending entries, smallest and greatest ID, and consumers.
+	return int(reply.([]interface{})[0].(int64))
+}
+
+func (s *redisServer) Close() error {
+	if s.client != nil {
+		s.client.Close()
+	}
+
+	if err := s.cmd.Process.Kill(); err != nil {
+		return err
+	}
+
+	// Killed processes exit with an error.
+	s.cmd.Wait()
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package breaker
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Backoff generates exponentially increasing, randomized delays. It is not safe for concurrent use.
+type Backoff struct {
+	Initial    time.Duration // First delay.
+	Max        time.Duration // Maximum delay, before jitter.
+	Multiplier float64       // Factor by which the delay increases after every attempt.
+	Jitter     float64       // Fraction by which delays are randomly increased or decreased, between 0 and 1.
+
+	attempt int
+}
+
+// NewBackoff returns a Backoff doubling delays from initial up to max, with a jitter of 0.5.
+func NewBackoff(initial, max time.Duration) *Backoff {
+	return &Backoff{
+		Initial:    initial,
+		Max:        max,
+		Multiplier: 2,
+		Jitter:     0.5,
+	}
+}
+
+// Next returns the delay before the next attempt.
+func (b *Backoff) Next() time.Duration {
+	d := float64(b.Initial) * math.Pow(b.Multiplier, float64(b.attempt))
+
+	if d >= float64(b.Max) {
+		d = float64(b.Max)
+	} else {
+		b.attempt++
+	}
+
+	// Randomize, so that many clients failing at once don't retry at once.
+	d += d * b.Jitter * (2*rand.Float64() - 1)
+
+	return time.Duration(d)
+}
+
+// Reset restarts delays from Initial.
+func (b *Backoff) Reset() {
+	b.attempt = 0
+}
@@ -0,0 +1,118 @@
+/*
+Package breaker provides a circuit breaker with exponential backoff, for operations which fail as a whole when a
+dependency becomes unavailable.
+*/
+package breaker
+
+import (
+	"sync"
+	"time"
+)
+
+// State represents the state of a Breaker.
+type State int
+
+// States of a Breaker.
+const (
+	Closed   State = iota // Operating normally.
+	Open                  // Failed; waiting before trying again.
+	HalfOpen              // Trying, without having succeeded since starting or failing.
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText renders a State as its name.
+func (s State) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Status is a snapshot of a Breaker's state.
+type Status struct {
+	State               State     `json:"state"`
+	Since               time.Time `json:"since"`
+	ConsecutiveFailures uint64    `json:"consecutive_failures"`
+	LastError           string    `json:"last_error,omitempty"`
+}
+
+// Breaker opens after a failure, until the caller tries again after a backoff, and closes after a success.
+// It starts in HalfOpen state. It is safe for concurrent use.
+type Breaker struct {
+	mu      sync.Mutex
+	backoff *Backoff
+	status  Status
+	now     func() time.Time
+}
+
+// New returns a new Breaker, waiting according to backoff after failures.
+func New(backoff *Backoff) *Breaker {
+	return &Breaker{
+		backoff: backoff,
+		status: Status{
+			State: HalfOpen,
+			Since: time.Now(),
+		},
+		now: time.Now,
+	}
+}
+
+func (b *Breaker) setState(s State) {
+	if b.status.State != s {
+		b.status.State = s
+		b.status.Since = b.now()
+	}
+}
+
+// Success records a successful operation, closing the Breaker and resetting the backoff.
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.status.State == Closed {
+		return
+	}
+
+	b.setState(Closed)
+	b.status.ConsecutiveFailures = 0
+	b.backoff.Reset()
+}
+
+// Failure records a failed operation, opening the Breaker, and returns the time to wait before calling Retry.
+func (b *Breaker) Failure(err error) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.setState(Open)
+	b.status.ConsecutiveFailures++
+	if err != nil {
+		b.status.LastError = err.Error()
+	}
+
+	return b.backoff.Next()
+}
+
+// Retry half-opens the Breaker, signaling that the operation is tried again.
+func (b *Breaker) Retry() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.setState(HalfOpen)
+}
+
+// Status returns the current status of the Breaker.
+func (b *Breaker) Status() Status {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.status
+}
@@ -0,0 +1,73 @@
+package breaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoff(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBackoff(time.Second, 5*time.Second)
+	b.Jitter = 0
+
+	assert.Equal(time.Second, b.Next())
+	assert.Equal(2*time.Second, b.Next())
+	assert.Equal(4*time.Second, b.Next())
+	assert.Equal(5*time.Second, b.Next())
+	assert.Equal(5*time.Second, b.Next())
+
+	b.Reset()
+	assert.Equal(time.Second, b.Next())
+}
+
+func TestBackoffJitter(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBackoff(time.Second, time.Second)
+
+	for i := 0; i < 100; i++ {
+		d := b.Next()
+		assert.True(d >= 500*time.Millisecond && d <= 1500*time.Millisecond, "%s", d)
+	}
+}
+
+func TestBreaker(t *testing.T) {
+	assert := assert.New(t)
+
+	backoff := NewBackoff(time.Second, time.Minute)
+	backoff.Jitter = 0
+
+	b := New(backoff)
+	assert.Equal(HalfOpen, b.Status().State)
+
+	b.Success()
+	assert.Equal(Closed, b.Status().State)
+
+	assert.Equal(time.Second, b.Failure(errors.New("first")))
+	b.Retry()
+	assert.Equal(HalfOpen, b.Status().State)
+
+	assert.Equal(2*time.Second, b.Failure(errors.New("second")))
+
+	status := b.Status()
+	assert.Equal(Open, status.State)
+	assert.Equal(uint64(2), status.ConsecutiveFailures)
+	assert.Equal("second", status.LastError)
+
+	// Success resets backoff
+	b.Retry()
+	b.Success()
+	assert.Equal(uint64(0), b.Status().ConsecutiveFailures)
+	assert.Equal(time.Second, b.Failure(nil))
+}
+
+func TestStateText(t *testing.T) {
+	text, err := Open.MarshalText()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "open", string(text))
+}
@@ -1,21 +1,57 @@
 package sniffer
 
-import "time"
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/c2h5oh/datasize"
+
+	filters "github.com/ipfs-search/ipfs-search/components/sniffer/providerfilters"
+)
 
 // Config holds configuration for a Sniffer.
 type Config struct {
-	LastSeenExpiration time.Duration // Expiration time for the last-seen resources
-	LastSeenPruneLen   int           // Cleanup expired resources from the last-seen
-	LoggerTimeout      time.Duration // Throw timeout error when no log messages arrive
-	BufferSize         uint          // Size of the channels buffering between yielder, filter and adder
+	EventSource        string            // Source of provider records: "datastore" (Put operations) or "providerstore"
+	LastSeenExpiration time.Duration     // Expiration time for the last-seen resources
+	LastSeenMaxLen     int               // Maximum number of resources in the in-memory last-seen
+	LastSeenBackend    string            // Storage for the last-seen: "memory", "badger" (on disk) or "redis"
+	LastSeenPath       string            // Directory of the on-disk store for the "badger" last-seen backend
+	RedisURL           string            // URL of Redis server for the "redis" last-seen backend: redis://[:password@]host:port[/db]
+	LoggerTimeout      time.Duration     // Throw timeout error when no log messages arrive
+	BufferSize         uint              // Size of the channels buffering between yielder, filter and adder
+	FilterWorkers      uint              // Number of concurrent workers filtering providers
+	Filters            []filters.Spec    // Filters providers pass, in order, before being queued
+	DebugAddress       string            // Address to serve debug information, such as rate limit offenders, on
+	RestartBackoff     time.Duration     // Initial wait before retrying after failing to publish or sniff
+	MaxRestartBackoff  time.Duration     // Maximum wait before retrying, doubling from RestartBackoff on repeated failure
+	SpoolDir           string            // Directory to spool providers in while publishing fails; empty to drop them instead
+	SpoolMaxSize       datasize.ByteSize // Maximum size of the spool; oldest providers are evicted beyond this size
+	SpoolSegmentSize   datasize.ByteSize // Size of spool files, at most half of SpoolMaxSize
 }
 
 // DefaultConfig returns the default configuration for a Sniffer.
 func DefaultConfig() *Config {
 	return &Config{
+		EventSource:        DatastoreSource,
 		LastSeenExpiration: 60 * time.Duration(time.Minute),
-		LastSeenPruneLen:   32768,
+		LastSeenMaxLen:     262144,
+		LastSeenBackend:    MemoryBackend,
+		LastSeenPath:       filepath.Join(os.TempDir(), "ipfs-search", "lastseen"),
+		RedisURL:           "redis://localhost:6379",
 		LoggerTimeout:      60 * time.Duration(time.Second),
 		BufferSize:         512,
+		FilterWorkers:      uint(runtime.NumCPU()),
+		Filters: []filters.Spec{
+			{Type: "lastseen"},
+			{Type: "cid", Params: filters.Params{"codecs": "raw,protobuf"}},
+		},
+		DebugAddress:      "localhost:7070",
+		RestartBackoff:    time.Second,
+		MaxRestartBackoff: 5 * time.Minute,
+		SpoolDir:          filepath.Join(os.TempDir(), "ipfs-search", "spool"),
+		SpoolMaxSize:      datasize.GB,
+		SpoolSegmentSize:  16 * datasize.MB,
 	}
 }
@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/ipfs/go-cid"
 	"github.com/ipfs/go-datastore"
 	"github.com/libp2p/go-eventbus"
 	"github.com/libp2p/go-libp2p-core/event"
+	"github.com/libp2p/go-libp2p-core/peer"
 
 	"go.opentelemetry.io/otel/api/trace"
 	"go.opentelemetry.io/otel/codes"
@@ -23,7 +25,8 @@ var handleTimeout = time.Second
 
 type handleFunc func(context.Context, EvtProviderPut) error
 
-// EventSource generates events on a Bus after Put operations by proxying a Batching datastore.
+// EventSource generates events on a Bus after Put operations by proxying a Batching datastore, or after provider
+// records are added to a wrapped DHT ProviderStore.
 type EventSource struct {
 	bus     event.Bus
 	emitter event.Emitter
@@ -77,6 +80,14 @@ func (s *EventSource) afterPut(k datastore.Key, v []byte, err error) error {
 		return nil
 	}
 
+	s.emit(ctx, span, cid, pid)
+
+	// Return *original* error
+	return err
+}
+
+// emit emits an EvtProviderPut, recording the result on span.
+func (s *EventSource) emit(ctx context.Context, span trace.Span, cid cid.Cid, pid peer.ID) {
 	span.SetAttributes(
 		label.Stringer("cid", cid),
 		label.Stringer("peerid", pid),
@@ -93,9 +104,6 @@ func (s *EventSource) afterPut(k datastore.Key, v []byte, err error) error {
 	} else {
 		span.SetStatus(codes.Ok, "emitted")
 	}
-
-	// Return *original* error
-	return err
 }
 
 // Batching returns the proxied Batching datastore.
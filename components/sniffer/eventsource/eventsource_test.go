@@ -8,10 +8,13 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ipfs/go-cid"
 	"github.com/ipfs/go-datastore"
 	"github.com/libp2p/go-eventbus"
 	"github.com/libp2p/go-libp2p-core/event"
+	"github.com/libp2p/go-libp2p-core/peer"
 
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -83,6 +86,61 @@ func (s *EventSourceTestSuite) TestSubscribePut() {
 	wg.Wait()
 }
 
+type providerStoreMock struct {
+	mock.Mock
+}
+
+func (m *providerStoreMock) AddProvider(ctx context.Context, key []byte, prov peer.AddrInfo) error {
+	return m.Called(ctx, key, prov).Error(0)
+}
+
+func (m *providerStoreMock) GetProviders(ctx context.Context, key []byte) ([]peer.AddrInfo, error) {
+	args := m.Called(ctx, key)
+	return args.Get(0).([]peer.AddrInfo), args.Error(1)
+}
+
+func (s *EventSourceTestSuite) TestSubscribeAddProvider() {
+	es, _ := New(s.bus, s.ds)
+
+	c, _ := cid.Decode("QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp")
+	pid, _ := peer.Decode("QmeTtFXm42Jb2todcKR538j6qHYxXt6suUzpF3rtT9FPSd")
+	prov := peer.AddrInfo{ID: pid}
+
+	m := &providerStoreMock{}
+	m.Test(s.T())
+	m.On("AddProvider", mock.Anything, []byte(c.Hash()), prov).Return(nil)
+
+	events := make(chan EvtProviderPut, 1)
+	go es.Subscribe(s.ctx, func(ctx context.Context, e EvtProviderPut) error {
+		events <- e
+		return nil
+	})
+
+	// Give the goroutine some time to start
+	time.Sleep(10 * time.Millisecond)
+
+	// Keys are multihashes
+	err := es.ProviderStore(m).AddProvider(s.ctx, c.Hash(), prov)
+	s.NoError(err)
+
+	e := <-events
+	s.Equal(c, e.CID)
+	s.Equal(pid, e.PeerID)
+
+	m.AssertExpectations(s.T())
+}
+
+func (s *EventSourceTestSuite) TestAddProviderError() {
+	es, _ := New(s.bus, s.ds)
+
+	m := &providerStoreMock{}
+	m.Test(s.T())
+	m.On("AddProvider", mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("mock"))
+
+	err := es.ProviderStore(m).AddProvider(s.ctx, []byte("invalid"), peer.AddrInfo{})
+	s.EqualError(err, "mock")
+}
+
 func TestEventSourceTestSuite(t *testing.T) {
 	suite.Run(t, new(EventSourceTestSuite))
 }
@@ -0,0 +1,49 @@
+package eventsource
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ipfs/go-cid"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-kad-dht/providers"
+
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// providerStore wraps a ProviderStore, emitting events for added provider records.
+type providerStore struct {
+	providers.ProviderStore
+	s *EventSource
+}
+
+// ProviderStore wraps a DHT's ProviderStore, generating events after provider records are added to it. This captures
+// ADD_PROVIDER messages directly, regardless of how the DHT stores provider records.
+func (s *EventSource) ProviderStore(ps providers.ProviderStore) providers.ProviderStore {
+	return &providerStore{ps, s}
+}
+
+// AddProvider adds a provider record to the wrapped ProviderStore and emits an event when successful.
+func (p *providerStore) AddProvider(ctx context.Context, key []byte, prov peer.AddrInfo) error {
+	if err := p.ProviderStore.AddProvider(ctx, key, prov); err != nil {
+		return err
+	}
+
+	ctx, span := p.s.Tracer.Start(ctx, "eventsource.AddProvider")
+	defer span.End()
+
+	// Keys are multihashes, which cast to v0 CID's, or CID's.
+	_, c, err := cid.CidFromBytes(key)
+	if err != nil {
+		span.RecordError(ctx, fmt.Errorf("cid from key '%x': %w", key, err), trace.WithErrorStatus(codes.Error))
+		return nil
+	}
+
+	p.s.emit(ctx, span, c, prov.ID)
+
+	return nil
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ providers.ProviderStore = &providerStore{}
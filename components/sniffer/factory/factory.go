@@ -3,16 +3,14 @@ package factory
 import (
 	"context"
 	"fmt"
-	"time"
 
-	"github.com/ipfs-search/ipfs-search/components/queue/amqp"
+	"github.com/ipfs-search/ipfs-search/components/queue"
+	"github.com/ipfs-search/ipfs-search/components/queue/factory"
 	"github.com/ipfs-search/ipfs-search/components/sniffer"
 	"github.com/ipfs-search/ipfs-search/config"
 	"github.com/ipfs-search/ipfs-search/instr"
-	"github.com/ipfs-search/ipfs-search/utils"
 	"github.com/ipfs/go-datastore"
-	samqp "github.com/streadway/amqp"
-	"net"
+	"github.com/libp2p/go-libp2p-kad-dht/providers"
 )
 
 func getConfig() (*config.Config, error) {
@@ -36,36 +34,40 @@ func getInstr(cfg *instr.Config) (*instr.Instrumentation, func(), error) {
 	return instr.New(), instFlusher, nil
 }
 
-func getQueue(ctx context.Context, cfg *amqp.Config, i *instr.Instrumentation) amqp.PublisherFactory {
-	// Retrying dialer for connecting
-	dialer := &utils.RetryingDialer{
-		Dialer: net.Dialer{
-			Timeout:   30 * time.Second,
-			KeepAlive: 30 * time.Second,
-			DualStack: false,
-		},
-		Context: ctx,
-	}
-	samqpConfig := &samqp.Config{
-		Dial: dialer.Dial,
-	}
+func getSniffer(cfg *sniffer.Config, ds datastore.Batching, q queue.PublisherFactory, i *instr.Instrumentation) (*sniffer.Sniffer, error) {
+	return sniffer.New(cfg, ds, q, i)
+}
 
-	return amqp.PublisherFactory{
-		Config:          cfg,
-		AMQPConfig:      samqpConfig,
-		Queue:           "hashes",
-		Instrumentation: i,
+// Start initialises a sniffer and all its dependencies and launches it in a goroutine, returning a wrapped context
+// and datastore, which should replace the original ones, or an error from initialisation.
+func Start(ctx context.Context, ds datastore.Batching) (context.Context, datastore.Batching, error) {
+	ctx, ds, _, err := StartWithHealth(ctx, ds)
+	return ctx, ds, err
+}
+
+// StartWithHealth is like Start, additionally returning a function reporting the sniffer's health.
+func StartWithHealth(ctx context.Context, ds datastore.Batching) (context.Context, datastore.Batching, func() sniffer.Health, error) {
+	ctx, s, err := start(ctx, ds)
+	if err != nil {
+		return nil, nil, nil, err
 	}
+
+	return ctx, s.Batching(), s.Health, nil
 }
 
-func getSniffer(ds datastore.Batching, q amqp.PublisherFactory, i *instr.Instrumentation) (*sniffer.Sniffer, error) {
-	c := sniffer.DefaultConfig()
-	return sniffer.New(c, ds, q, i)
+// StartWithProviderStore is like StartWithHealth, additionally returning a wrapped ProviderStore, which should be
+// passed to the DHT with dht.ProviderStore(). Whether provider records are sniffed from the datastore or the
+// ProviderStore is selected by the sniffer's configured event source.
+func StartWithProviderStore(ctx context.Context, ds datastore.Batching, ps providers.ProviderStore) (context.Context, datastore.Batching, providers.ProviderStore, func() sniffer.Health, error) {
+	ctx, s, err := start(ctx, ds)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	return ctx, s.Batching(), s.ProviderStore(ps), s.Health, nil
 }
 
-// Start initialises a sniffer and all its dependencies and launches it in a goroutine, returning a wrapped context
-// and datastore, which should replace the original ones, or an error from initialisation.
-func Start(ctx context.Context, ds datastore.Batching) (context.Context, datastore.Batching, error) {
+func start(ctx context.Context, ds datastore.Batching) (context.Context, *sniffer.Sniffer, error) {
 	cfg, err := getConfig()
 	if err != nil {
 		return nil, nil, err
@@ -79,16 +81,17 @@ func Start(ctx context.Context, ds datastore.Batching) (context.Context, datasto
 	// Create context which can be canceled by sniffer so as to propagate failure from sniffer goroutine.
 	ctx, cancel := context.WithCancel(ctx)
 
-	q := getQueue(ctx, cfg.AMQPConfig(), i)
-
-	s, err := getSniffer(ds, q, i)
+	q, err := factory.NewHashesPublisherFactory(ctx, cfg, i)
 	if err != nil {
 		cancel()
 		return nil, nil, err
 	}
 
-	// Use batched datastore
-	ds = s.Batching()
+	s, err := getSniffer(cfg.SnifferConfig(), ds, q, i)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
 
 	// Start sniffer
 	go func() {
@@ -100,5 +103,5 @@ func Start(ctx context.Context, ds datastore.Batching) (context.Context, datasto
 		fmt.Printf("Sniffer exited: %s\n", err)
 	}()
 
-	return ctx, ds, nil
+	return ctx, s, nil
 }
@@ -50,6 +50,25 @@ func (s *FactoryTestSuite) TestStartBurn() {
 	s.NotEqual(s.ctx, ctx)
 }
 
+// TestStartWithHealthBurn performs a burn test for StartWithHealth().
+func (s *FactoryTestSuite) TestStartWithHealthBurn() {
+	_, _, health, err := StartWithHealth(s.ctx, s.ds)
+	s.NoError(err)
+
+	s.NotNil(health)
+	health()
+}
+
+// TestStartWithProviderStoreBurn performs a burn test for StartWithProviderStore().
+func (s *FactoryTestSuite) TestStartWithProviderStoreBurn() {
+	_, ds, ps, health, err := StartWithProviderStore(s.ctx, s.ds, nil)
+	s.NoError(err)
+
+	s.NotEqual(s.ds, ds)
+	s.Nil(ps)
+	s.NotNil(health)
+}
+
 func TestFactoryTestSuite(t *testing.T) {
 	suite.Run(t, new(FactoryTestSuite))
 }
@@ -0,0 +1,57 @@
+package sniffer
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/ipfs-search/ipfs-search/components/sniffer/breaker"
+)
+
+// HealthDebugPath is where the Sniffer's Health is served.
+const HealthDebugPath = "/debug/sniffer/health"
+
+// Health represents the state of a Sniffer.
+type Health struct {
+	// Publishing state: closed when publishing, open when providers are dropped because publishing failed
+	// and half-open when (re)connecting.
+	Publishing breaker.Status `json:"publishing"`
+	Restarts   uint64         `json:"restarts"` // Restarts after subscribing or filtering failed.
+	Dropped    uint64         `json:"dropped"`  // Providers dropped while publishing was failing.
+	Spooled    int            `json:"spooled"`  // Providers spooled, waiting to be published.
+	Evicted    uint64         `json:"evicted"`  // Providers evicted from the spool because it was full.
+}
+
+// Healthy returns whether sniffed providers are being published.
+func (h Health) Healthy() bool {
+	return h.Publishing.State == breaker.Closed
+}
+
+// Health returns the current Health of the Sniffer.
+func (s *Sniffer) Health() Health {
+	h := Health{
+		Publishing: s.breaker.Status(),
+		Restarts:   atomic.LoadUint64(&s.restarts),
+		Dropped:    atomic.LoadUint64(&s.dropped),
+	}
+
+	if s.spool != nil {
+		h.Spooled = s.spool.Len()
+		h.Evicted = s.spool.Evicted()
+	}
+
+	return h
+}
+
+// serveHealth serves Health as JSON, with status 503 when the Sniffer is not healthy.
+func (s *Sniffer) serveHealth(w http.ResponseWriter, r *http.Request) {
+	h := s.Health()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if !h.Healthy() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	json.NewEncoder(w).Encode(h)
+}
@@ -0,0 +1,51 @@
+package sniffer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+
+	filters "github.com/ipfs-search/ipfs-search/components/sniffer/providerfilters"
+	"github.com/ipfs-search/ipfs-search/utils/redis"
+)
+
+// Backends for the last-seen store.
+const (
+	MemoryBackend = "memory" // In-memory, lost on restart.
+	BadgerBackend = "badger" // Persisted on disk in a dedicated store at LastSeenPath.
+	RedisBackend  = "redis"  // Shared between sniffers through Redis.
+)
+
+// ErrUnknownBackend is returned when an unknown backend for the last-seen store is configured.
+var ErrUnknownBackend = errors.New("unknown last-seen backend")
+
+func newLastSeenStore(cfg *Config) (filters.LastSeenStore, error) {
+	switch cfg.LastSeenBackend {
+	case MemoryBackend:
+		return filters.NewMemoryStore(cfg.LastSeenExpiration, cfg.LastSeenMaxLen), nil
+
+	case BadgerBackend:
+		return filters.NewBadgerStore(cfg.LastSeenPath, cfg.LastSeenExpiration)
+
+	case RedisBackend:
+		client, err := redis.New(cfg.RedisURL, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		return filters.NewRedisStore(client, cfg.LastSeenExpiration), nil
+
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownBackend, cfg.LastSeenBackend)
+	}
+}
+
+// closeLastSeen closes a last-seen store holding resources, such as a database or connection.
+func closeLastSeen(store filters.LastSeenStore) {
+	if c, ok := store.(io.Closer); ok {
+		if err := c.Close(); err != nil {
+			log.Printf("Error closing last-seen store: %s", err)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package node
+
+import (
+	"time"
+
+	dht "github.com/libp2p/go-libp2p-kad-dht"
+)
+
+// Config holds configuration for a Node.
+type Config struct {
+	ListenAddresses []string      // Multiaddresses to listen on
+	BootstrapPeers  []string      // Multiaddresses, including peer ID, of peers to join the DHT through
+	ConnLowWater    int           // Amount of connections to trim down to
+	ConnHighWater   int           // Amount of connections above which they are trimmed
+	ConnGracePeriod time.Duration // Time new connections are not subject to trimming
+}
+
+// DefaultConfig returns the default configuration for a Node.
+func DefaultConfig() *Config {
+	bootstrapPeers := make([]string, len(dht.DefaultBootstrapPeers))
+	for i, addr := range dht.DefaultBootstrapPeers {
+		bootstrapPeers[i] = addr.String()
+	}
+
+	return &Config{
+		// Port 4001 is typically used by go-ipfs.
+		ListenAddresses: []string{
+			"/ip4/0.0.0.0/tcp/4002",
+			"/ip6/::/tcp/4002",
+		},
+		BootstrapPeers:  bootstrapPeers,
+		ConnLowWater:    600,
+		ConnHighWater:   900,
+		ConnGracePeriod: 20 * time.Second,
+	}
+}
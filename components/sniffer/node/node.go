@@ -0,0 +1,144 @@
+/*
+Package node provides a minimal libp2p DHT server node, storing the provider records it receives in a datastore.
+
+By passing a sniffer's Batching() datastore, or its ProviderStore() wrapper, the node can be sniffed without running a
+full IPFS node.
+*/
+package node
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/libp2p/go-libp2p"
+	connmgr "github.com/libp2p/go-libp2p-connmgr"
+	"github.com/libp2p/go-libp2p-core/host"
+	"github.com/libp2p/go-libp2p-core/peer"
+	dht "github.com/libp2p/go-libp2p-kad-dht"
+	"github.com/libp2p/go-libp2p-kad-dht/providers"
+	"github.com/multiformats/go-multiaddr"
+)
+
+// Node is a libp2p host participating in the DHT as a server.
+type Node struct {
+	cfg  *Config
+	host host.Host
+	pm   *providers.ProviderManager
+	dht  *dht.IpfsDHT
+}
+
+// WrapProviderStore wraps the DHT's ProviderStore, e.g. to sniff the provider records added to it.
+type WrapProviderStore func(providers.ProviderStore) providers.ProviderStore
+
+// Start creates a new libp2p host with a random identity, joins the DHT in server mode, storing records in ds,
+// and bootstraps it. When wrap is not nil, the DHT uses the ProviderStore it returns.
+func Start(ctx context.Context, cfg *Config, ds datastore.Batching, wrap WrapProviderStore) (*Node, error) {
+	peers, err := parsePeers(cfg.BootstrapPeers)
+	if err != nil {
+		return nil, err
+	}
+
+	h, err := libp2p.New(ctx,
+		libp2p.ListenAddrStrings(cfg.ListenAddresses...),
+		libp2p.ConnectionManager(connmgr.NewConnManager(cfg.ConnLowWater, cfg.ConnHighWater, cfg.ConnGracePeriod)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creating libp2p host: %w", err)
+	}
+
+	pm, err := providers.NewProviderManager(ctx, h.ID(), h.Peerstore(), ds)
+	if err != nil {
+		h.Close()
+		return nil, fmt.Errorf("creating provider manager: %w", err)
+	}
+
+	var ps providers.ProviderStore = pm
+	if wrap != nil {
+		ps = wrap(ps)
+	}
+
+	d, err := dht.New(ctx, h,
+		dht.Mode(dht.ModeServer),
+		dht.Datastore(ds),
+		dht.ProviderStore(ps),
+	)
+	if err != nil {
+		pm.Process().Close()
+		h.Close()
+		return nil, fmt.Errorf("creating DHT: %w", err)
+	}
+
+	n := &Node{
+		cfg:  cfg,
+		host: h,
+		pm:   pm,
+		dht:  d,
+	}
+
+	log.Printf("Started DHT node %s listening on %v", h.ID(), h.Addrs())
+
+	n.connect(ctx, peers)
+
+	if err := d.Bootstrap(ctx); err != nil {
+		n.Close()
+		return nil, fmt.Errorf("bootstrapping DHT: %w", err)
+	}
+
+	return n, nil
+}
+
+func parsePeers(addrs []string) ([]peer.AddrInfo, error) {
+	maddrs := make([]multiaddr.Multiaddr, len(addrs))
+
+	for i, addr := range addrs {
+		maddr, err := multiaddr.NewMultiaddr(addr)
+		if err != nil {
+			return nil, fmt.Errorf("parsing bootstrap peer %s: %w", addr, err)
+		}
+
+		maddrs[i] = maddr
+	}
+
+	// Merges multiple addresses of the same peer.
+	return peer.AddrInfosFromP2pAddrs(maddrs...)
+}
+
+// connect concurrently connects to peers, logging failures.
+func (n *Node) connect(ctx context.Context, peers []peer.AddrInfo) {
+	var wg sync.WaitGroup
+
+	for _, p := range peers {
+		wg.Add(1)
+
+		go func(p peer.AddrInfo) {
+			defer wg.Done()
+
+			if err := n.host.Connect(ctx, p); err != nil {
+				log.Printf("Error connecting to bootstrap peer %s: %s", p.ID, err)
+			}
+		}(p)
+	}
+
+	wg.Wait()
+}
+
+// ID returns the peer ID of the node.
+func (n *Node) ID() peer.ID {
+	return n.host.ID()
+}
+
+// Close leaves the DHT, stops the provider manager and closes the host.
+func (n *Node) Close() error {
+	if err := n.dht.Close(); err != nil {
+		return err
+	}
+
+	if err := n.pm.Process().Close(); err != nil {
+		return err
+	}
+
+	return n.host.Close()
+}
@@ -0,0 +1,87 @@
+package node
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	connmgr "github.com/libp2p/go-libp2p-connmgr"
+	dht "github.com/libp2p/go-libp2p-kad-dht"
+	"github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDefaultBootstrapPeers(t *testing.T) {
+	assert := assert.New(t)
+
+	peers, err := parsePeers(DefaultConfig().BootstrapPeers)
+
+	assert.NoError(err)
+	assert.NotEmpty(peers)
+}
+
+func TestParseInvalidPeer(t *testing.T) {
+	_, err := parsePeers([]string{"/ip4/127.0.0.1/tcp/4001"})
+
+	// Peer ID is required
+	assert.Error(t, err)
+}
+
+func TestStart(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Listen on a random loopback port, without bootstrapping.
+	cfg := &Config{
+		ListenAddresses: []string{"/ip4/127.0.0.1/tcp/0"},
+		ConnLowWater:    10,
+		ConnHighWater:   20,
+		ConnGracePeriod: time.Minute,
+	}
+
+	n, err := Start(ctx, cfg, dssync.MutexWrap(datastore.NewMapDatastore()), nil)
+	if !assert.NoError(err) {
+		return
+	}
+
+	addrs := n.host.Network().ListenAddresses()
+	if !assert.Len(addrs, 1) {
+		return
+	}
+
+	ip, err := addrs[0].ValueForProtocol(multiaddr.P_IP4)
+	assert.NoError(err)
+	assert.Equal("127.0.0.1", ip)
+
+	port, err := addrs[0].ValueForProtocol(multiaddr.P_TCP)
+	assert.NoError(err)
+
+	address := net.JoinHostPort(ip, port)
+
+	conn, err := net.Dial("tcp", address)
+	if assert.NoError(err) {
+		conn.Close()
+	}
+
+	assert.Equal(n.host.ID(), n.ID())
+	assert.Equal(dht.ModeServer, n.dht.Mode())
+
+	if cm, ok := n.host.ConnManager().(*connmgr.BasicConnMgr); assert.True(ok) {
+		info := cm.GetInfo()
+
+		assert.Equal(cfg.ConnLowWater, info.LowWater)
+		assert.Equal(cfg.ConnHighWater, info.HighWater)
+		assert.Equal(cfg.ConnGracePeriod, info.GracePeriod)
+	}
+
+	assert.NoError(n.Close())
+
+	// Closing stops listening.
+	_, err = net.Dial("tcp", address)
+	assert.Error(err)
+}
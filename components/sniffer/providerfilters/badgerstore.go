@@ -0,0 +1,133 @@
+package providerfilters
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dgraph-io/badger"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+var errInvalidLastSeen = errors.New("invalid last seen value")
+
+// BadgerStore is a LastSeenStore persisted in a dedicated, embedded Badger database on disk, so that it survives
+// restarts. Resources expire from the store after the expiration; as values are small enough to be kept in the LSM
+// tree, expired resources are discarded on compaction without scanning the store. It is safe for concurrent use.
+type BadgerStore struct {
+	db         *badger.DB
+	Expiration time.Duration
+}
+
+// NewBadgerStore opens (or creates) a BadgerStore in the directory at path.
+func NewBadgerStore(path string, expiration time.Duration) (*BadgerStore, error) {
+	db, err := badger.Open(badger.DefaultOptions(path).WithLogger(nil))
+	if err != nil {
+		return nil, err
+	}
+
+	return &BadgerStore{
+		db:         db,
+		Expiration: expiration,
+	}, nil
+}
+
+func badgerKey(r t.Resource) []byte {
+	return []byte(fmt.Sprintf("%s/%s", r.Protocol, r.ID))
+}
+
+func encodeTime(t time.Time) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, uint64(t.UnixNano()))
+	return buf
+}
+
+func decodeTime(buf []byte) (time.Time, error) {
+	if len(buf) != 8 {
+		return time.Time{}, fmt.Errorf("%w: length %d", errInvalidLastSeen, len(buf))
+	}
+
+	return time.Unix(0, int64(binary.BigEndian.Uint64(buf))), nil
+}
+
+// Get returns the time a resource was last seen and whether it is known.
+func (s *BadgerStore) Get(r t.Resource) (time.Time, bool, error) {
+	var lastSeen time.Time
+
+	err := s.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(badgerKey(r))
+		if err != nil {
+			return err
+		}
+
+		return item.Value(func(val []byte) error {
+			lastSeen, err = decodeTime(val)
+			return err
+		})
+	})
+
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return time.Time{}, false, nil
+	}
+
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	return lastSeen, true, nil
+}
+
+// Set stores the time a resource was last seen, expiring it after the expiration.
+func (s *BadgerStore) Set(r t.Resource, lastSeen time.Time) error {
+	ttl := time.Until(lastSeen.Add(s.Expiration))
+	if ttl <= 0 {
+		// Already expired; no use in storing it.
+		return nil
+	}
+
+	return s.db.Update(func(txn *badger.Txn) error {
+		return txn.SetEntry(badger.NewEntry(badgerKey(r), encodeTime(lastSeen)).WithTTL(ttl))
+	})
+}
+
+// SetIfAbsent stores the time a resource was last seen unless it is known, returning whether it was stored.
+func (s *BadgerStore) SetIfAbsent(r t.Resource, lastSeen time.Time) (bool, error) {
+	ttl := time.Until(lastSeen.Add(s.Expiration))
+
+	for {
+		stored := false
+
+		err := s.db.Update(func(txn *badger.Txn) error {
+			_, err := txn.Get(badgerKey(r))
+			if err == nil || !errors.Is(err, badger.ErrKeyNotFound) {
+				return err
+			}
+
+			stored = true
+
+			if ttl <= 0 {
+				// Already expired; no use in storing it.
+				return nil
+			}
+
+			return txn.SetEntry(badger.NewEntry(badgerKey(r), encodeTime(lastSeen)).WithTTL(ttl))
+		})
+
+		if errors.Is(err, badger.ErrConflict) {
+			// Concurrently written by another transaction; try again to see its result.
+			continue
+		}
+
+		return stored, err
+	}
+}
+
+// Close closes the underlying database.
+func (s *BadgerStore) Close() error {
+	return s.db.Close()
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ LastSeenStore = &BadgerStore{}
@@ -3,6 +3,7 @@ package providerfilters
 import (
 	"errors"
 	"fmt"
+	"strconv"
 
 	"github.com/ipfs/go-cid"
 
@@ -13,14 +14,66 @@ var (
 	errUnsupportedProtocol = errors.New("unsupported protocol")
 	errDecodingCID         = errors.New("unable to decode CID")
 	errUnsupportedCodec    = errors.New("unsupported codec")
+	errUnsupportedVersion  = errors.New("unsupported CID version")
 )
 
-// CidFilter filters out invalid CID's or those which are not Raw or DagProtobuf.
-type CidFilter struct{}
+// CidFilter filters out invalid CID's or those with a codec or version which is not allowed.
+// It is safe for concurrent use.
+type CidFilter struct {
+	codecs   map[uint64]bool
+	versions map[uint64]bool // Empty for any version.
+}
 
-// NewCidFilter returns a pointer to a new CidFilter.
+// NewCidFilter returns a pointer to a new CidFilter, allowing Raw and DagProtobuf CID's of any version.
 func NewCidFilter() *CidFilter {
-	return &CidFilter{}
+	return &CidFilter{
+		codecs: map[uint64]bool{
+			// (Potential) files and directories
+			cid.Raw:         true,
+			cid.DagProtobuf: true,
+		},
+	}
+}
+
+// newCidFilterFromParams creates a CidFilter from the comma-separated parameters "codecs",
+// codec names or numbers, and "versions".
+func newCidFilterFromParams(params Params, _ *Deps) (Filter, error) {
+	if err := params.Check("codecs", "versions"); err != nil {
+		return nil, err
+	}
+
+	f := NewCidFilter()
+
+	if codecs := params.List("codecs"); codecs != nil {
+		f.codecs = make(map[uint64]bool, len(codecs))
+
+		for _, name := range codecs {
+			codec, ok := cid.Codecs[name]
+			if !ok {
+				var err error
+				if codec, err = strconv.ParseUint(name, 0, 64); err != nil {
+					return nil, fmt.Errorf("%w: unknown codec '%s'", ErrInvalidParam, name)
+				}
+			}
+
+			f.codecs[codec] = true
+		}
+	}
+
+	if versions := params.List("versions"); versions != nil {
+		f.versions = make(map[uint64]bool, len(versions))
+
+		for _, v := range versions {
+			version, err := strconv.ParseUint(v, 10, 64)
+			if err != nil || version > 1 {
+				return nil, fmt.Errorf("%w: invalid CID version '%s'", ErrInvalidParam, v)
+			}
+
+			f.versions[version] = true
+		}
+	}
+
+	return f, nil
 }
 
 // Filter takes a Provider and returns true when it is to be included, false
@@ -36,12 +89,18 @@ func (f *CidFilter) Filter(p t.Provider) (bool, error) {
 		return false, fmt.Errorf("%w: %s decoding CID %v", errDecodingCID, err, p)
 	}
 
-	switch cidType := c.Type(); cidType {
-	case cid.Raw, cid.DagProtobuf:
-		// (Potential) files and directories
-		return true, nil
-	default:
+	if len(f.versions) > 0 && !f.versions[c.Version()] {
+		return false, fmt.Errorf("%w: %d for %v", errUnsupportedVersion, c.Version(), p)
+	}
+
+	if cidType := c.Type(); !f.codecs[cidType] {
 		// Can't handle other types (for now)
 		return false, fmt.Errorf("%w: %s for %v", errUnsupportedCodec, cid.CodecToStr[cidType], p)
 	}
+
+	return true, nil
+}
+
+func init() {
+	Register("cid", newCidFilterFromParams)
 }
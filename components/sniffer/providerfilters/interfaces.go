@@ -6,6 +6,7 @@ import (
 
 // Filter takes a Provider and returns true when it is to be included, false
 // when not and an error when unexpected condition occur.
+// Implementations must be safe for concurrent use.
 type Filter interface {
 	Filter(t.Provider) (bool, error)
 }
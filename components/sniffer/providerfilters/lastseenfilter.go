@@ -1,68 +1,111 @@
 package providerfilters
 
 import (
+	"errors"
+	"fmt"
+	"hash/fnv"
 	"log"
+	"sync"
 	"time"
 
 	t "github.com/ipfs-search/ipfs-search/types"
 )
 
-// LastSeenFilter filters out recently seen Providers.
+// lockStripes is the amount of locks over which resources are distributed.
+const lockStripes = 256
+
+// LastSeenFilter filters out recently seen Providers. It is safe for concurrent use.
 type LastSeenFilter struct {
-	resources  map[t.Resource]time.Time
+	store      LastSeenStore
 	Expiration time.Duration
-	PruneLen   int
-}
 
-// NewLastSeenFilter initialises a new LastSeenFilter and returns a pointer to it.
-func NewLastSeenFilter(expiration time.Duration, pruneLen int) *LastSeenFilter {
-	// Allocate memory for pruneLen+1
-	r := make(map[t.Resource]time.Time, pruneLen+1)
+	// Locks serializing lookup and update of a resource, striped over resources.
+	locks [lockStripes]sync.Mutex
+}
 
+// NewLastSeenFilter initialises a new LastSeenFilter, backed by a LastSeenStore, and returns a pointer to it.
+func NewLastSeenFilter(store LastSeenStore, expiration time.Duration) *LastSeenFilter {
 	return &LastSeenFilter{
+		store:      store,
 		Expiration: expiration,
-		PruneLen:   pruneLen,
-		resources:  r,
 	}
 }
 
-func (f *LastSeenFilter) prune() {
-	if len(f.resources) > f.PruneLen {
-		// Delete all expired items
-		now := time.Now()
-		cnt := 0
-
-		for i, t := range f.resources {
-			if now.Sub(t) > f.Expiration {
-				delete(f.resources, i)
-				cnt++
-			}
-		}
+// newLastSeenFilterFromParams creates a LastSeenFilter from the shared last-seen store in deps.
+// The "expiration" parameter, a duration, overrides the configured last-seen expiration.
+func newLastSeenFilterFromParams(params Params, deps *Deps) (Filter, error) {
+	if err := params.Check("expiration"); err != nil {
+		return nil, err
+	}
+
+	if deps == nil || deps.LastSeen == nil {
+		return nil, errors.New("no last-seen store available")
+	}
 
-		log.Printf("Pruned %d resources, len: %d, pruneLen: %d", cnt, len(f.resources), f.PruneLen)
+	expiration := deps.LastSeenExpiration
+	if v, ok := params["expiration"]; ok {
+		var err error
+		if expiration, err = time.ParseDuration(v); err != nil {
+			return nil, fmt.Errorf("%w: expiration: %v", ErrInvalidParam, err)
+		}
 	}
+
+	return NewLastSeenFilter(deps.LastSeen, expiration), nil
+}
+
+// lock locks the stripe for a resource, returning the corresponding unlock function.
+func (f *LastSeenFilter) lock(r *t.Resource) func() {
+	h := fnv.New32a()
+	h.Write([]byte(r.ID))
+
+	l := &f.locks[h.Sum32()%lockStripes]
+	l.Lock()
+
+	return l.Unlock
 }
 
 // Filter takes a Provider and returns true when it is to be included, false
 // when not and an error when unexpected condition occur.
+//
+// Unknown resources are stored and included atomically, so that filters in other sniffers sharing the store include
+// them only once. Updates of expired resources are not atomic; but as stores expire resources after the configured
+// last-seen expiration, these only occur when a filter's expiration is shorter.
 func (f *LastSeenFilter) Filter(p t.Provider) (bool, error) {
-	f.prune()
+	// Prevent concurrent filtering of the same resource from including it more than once.
+	defer f.lock(p.Resource)()
 
-	lastSeen, present := f.resources[*(p.Resource)]
+	var (
+		lastSeen time.Time
+		present  bool
+	)
 
-	if !present {
-		// Not present, add it!
-		log.Printf("Adding LastSeen: %v, len: %d", p, len(f.resources))
-		f.resources[*(p.Resource)] = p.Date
+	for !present {
+		stored, err := f.store.SetIfAbsent(*(p.Resource), p.Date)
+		if err != nil {
+			return false, err
+		}
 
-		// Index it!
-		return true, nil
+		if stored {
+			// Not present, added it.
+			log.Printf("Added LastSeen: %v", p)
+
+			// Index it!
+			return true, nil
+		}
+
+		// Present, unless it expired in the meantime.
+		lastSeen, present, err = f.store.Get(*(p.Resource))
+		if err != nil {
+			return false, err
+		}
 	}
 
 	if p.Date.Sub(lastSeen) > f.Expiration {
 		// Last seen longer than expiration ago, update last seen.
-		log.Printf("Updating LastSeen: %v, len: %d", p, len(f.resources))
-		f.resources[*(p.Resource)] = p.Date
+		log.Printf("Updating LastSeen: %v", p)
+		if err := f.store.Set(*(p.Resource), p.Date); err != nil {
+			return false, err
+		}
 
 		// Index it!
 		return true, nil
@@ -72,3 +115,7 @@ func (f *LastSeenFilter) Filter(p t.Provider) (bool, error) {
 	log.Printf("Filtering recent %v, LastSeen %s", p, lastSeen)
 	return false, nil
 }
+
+func init() {
+	Register("lastseen", newLastSeenFilterFromParams)
+}
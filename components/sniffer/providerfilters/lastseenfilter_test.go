@@ -0,0 +1,289 @@
+package providerfilters
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/types"
+	"github.com/ipfs-search/ipfs-search/utils/redis"
+	"github.com/ipfs-search/ipfs-search/utils/redis/redistest"
+)
+
+const (
+	testExpiration = time.Hour
+	otherID        = "bafkreiblvqc3q73ygovlzaxz4iilm5fopppcdc3uzkrtepjsgkvyev3kgy"
+)
+
+// LastSeenFilterTestSuite tests LastSeenFilter semantics, for a store created by newStore.
+type LastSeenFilterTestSuite struct {
+	suite.Suite
+
+	newStore func() LastSeenStore
+	store    LastSeenStore
+	f        *LastSeenFilter
+}
+
+func (s *LastSeenFilterTestSuite) SetupTest() {
+	s.store = s.newStore()
+	s.f = NewLastSeenFilter(s.store, testExpiration)
+}
+
+func (s *LastSeenFilterTestSuite) TearDownTest() {
+	if c, ok := s.store.(io.Closer); ok {
+		s.NoError(c.Close())
+	}
+}
+
+func (s *LastSeenFilterTestSuite) TestNew() {
+	p := makeProvider(nil)
+
+	result, err := s.f.Filter(*p)
+
+	s.NoError(err)
+	s.True(result)
+}
+
+func (s *LastSeenFilterTestSuite) TestRecent() {
+	p := makeProvider(nil)
+	s.f.Filter(*p)
+
+	p.Date = p.Date.Add(testExpiration / 2)
+	result, err := s.f.Filter(*p)
+
+	s.NoError(err)
+	s.False(result)
+}
+
+func (s *LastSeenFilterTestSuite) TestExpired() {
+	p := makeProvider(nil)
+	s.f.Filter(*p)
+
+	p.Date = p.Date.Add(testExpiration + time.Second)
+	result, err := s.f.Filter(*p)
+
+	s.NoError(err)
+	s.True(result)
+
+	// Last seen has been updated
+	lastSeen, present, err := s.store.Get(*p.Resource)
+	s.NoError(err)
+	s.True(present)
+	s.True(p.Date.Equal(lastSeen))
+}
+
+func (s *LastSeenFilterTestSuite) TestOtherResource() {
+	p := makeProvider(nil)
+	s.f.Filter(*p)
+
+	other := makeProvider(&types.Resource{
+		Protocol: types.IPFSProtocol,
+		ID:       otherID,
+	})
+	result, err := s.f.Filter(*other)
+
+	s.NoError(err)
+	s.True(result)
+}
+
+// TestConcurrent tests whether a resource concurrently filtered by multiple goroutines is included once.
+func (s *LastSeenFilterTestSuite) TestConcurrent() {
+	const workers = 16
+
+	p := makeProvider(nil)
+
+	var (
+		wg       sync.WaitGroup
+		included int32
+	)
+
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+
+			result, err := s.f.Filter(*p)
+			s.NoError(err)
+
+			if result {
+				atomic.AddInt32(&included, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	s.Equal(int32(1), included)
+}
+
+// TestSharedConcurrent tests whether a resource concurrently filtered by filters sharing the store, as in multiple
+// sniffers, is included once.
+func (s *LastSeenFilterTestSuite) TestSharedConcurrent() {
+	const workers = 16
+
+	p := makeProvider(nil)
+
+	var (
+		wg       sync.WaitGroup
+		included int32
+	)
+
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+
+			f := NewLastSeenFilter(s.store, testExpiration)
+
+			result, err := f.Filter(*p)
+			s.NoError(err)
+
+			if result {
+				atomic.AddInt32(&included, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	s.Equal(int32(1), included)
+}
+
+// TestShared tests whether resources seen through one filter are filtered by another sharing the store.
+func (s *LastSeenFilterTestSuite) TestShared() {
+	p := makeProvider(nil)
+	s.f.Filter(*p)
+
+	other := NewLastSeenFilter(s.store, testExpiration)
+	result, err := other.Filter(*p)
+
+	s.NoError(err)
+	s.False(result)
+}
+
+func TestMemoryLastSeenFilter(t *testing.T) {
+	suite.Run(t, &LastSeenFilterTestSuite{
+		newStore: func() LastSeenStore {
+			return NewMemoryStore(testExpiration, 100)
+		},
+	})
+}
+
+func TestBadgerLastSeenFilter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lastseen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	suite.Run(t, &LastSeenFilterTestSuite{
+		newStore: func() LastSeenStore {
+			path, err := ioutil.TempDir(dir, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			store, err := NewBadgerStore(path, testExpiration)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			return store
+		},
+	})
+}
+
+func TestRedisLastSeenFilter(t *testing.T) {
+	server, err := redistest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	suite.Run(t, &LastSeenFilterTestSuite{
+		newStore: func() LastSeenStore {
+			client, err := redis.New(server.URL(), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Start with an empty server
+			client.Do(context.Background(), "DEL",
+				redisKey(*makeProvider(nil).Resource),
+				redisKey(types.Resource{Protocol: types.IPFSProtocol, ID: otherID}),
+			)
+
+			return NewRedisStore(client, testExpiration)
+		},
+	})
+}
+
+func TestBadgerExpired(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lastseen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s, err := NewBadgerStore(dir, testExpiration)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	expired := types.Resource{Protocol: types.IPFSProtocol, ID: "expired"}
+
+	if err := s.Set(expired, time.Now().Add(-2*testExpiration)); err != nil {
+		t.Fatal(err)
+	}
+
+	_, present, err := s.Get(expired)
+	if err != nil || present {
+		t.Errorf("expired resource present, err: %v", err)
+	}
+}
+
+func TestBadgerPersistence(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lastseen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s, err := NewBadgerStore(dir, testExpiration)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := types.Resource{Protocol: types.IPFSProtocol, ID: "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp"}
+	lastSeen := time.Now()
+
+	if err := s.Set(r, lastSeen); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reopen the store, as after a restart.
+	s, err = NewBadgerStore(dir, testExpiration)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	stored, present, err := s.Get(r)
+	if err != nil || !present {
+		t.Fatalf("resource not persisted, err: %v", err)
+	}
+
+	if !stored.Equal(time.Unix(0, lastSeen.UnixNano())) {
+		t.Errorf("unexpected last seen %v, expected %v", stored, lastSeen)
+	}
+}
@@ -0,0 +1,20 @@
+package providerfilters
+
+import (
+	"time"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// LastSeenStore stores the times at which resources were last seen, for use by a LastSeenFilter.
+// Stores may forget resources which have been last seen longer than the filter's expiration ago.
+// Implementations must be safe for concurrent use.
+type LastSeenStore interface {
+	// Get returns the time a resource was last seen and whether it is known.
+	Get(t.Resource) (time.Time, bool, error)
+	// Set stores the time a resource was last seen.
+	Set(t.Resource, time.Time) error
+	// SetIfAbsent atomically stores the time a resource was last seen unless it is known, returning whether it
+	// was stored.
+	SetIfAbsent(t.Resource, time.Time) (bool, error)
+}
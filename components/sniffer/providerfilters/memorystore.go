@@ -0,0 +1,172 @@
+package providerfilters
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/api/metric"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+type memoryEntry struct {
+	resource t.Resource
+	lastSeen time.Time
+}
+
+// MemoryStore is an in-memory LastSeenStore. Its contents are lost when the program exits.
+//
+// Resources are kept in order of their last update, so that expired resources can be removed from the front in
+// amortized constant time. The amount of resources is capped at MaxLen; when full, the least recently updated
+// resource is evicted, regardless of whether it has expired. With CID's of typical length, every resource
+// takes about 200 bytes. It is safe for concurrent use.
+type MemoryStore struct {
+	mu         sync.Mutex
+	resources  map[t.Resource]*list.Element
+	order      *list.List // Entries, least recently updated first.
+	Expiration time.Duration
+	MaxLen     int
+
+	now     func() time.Time
+	expired uint64
+	evicted uint64
+
+	expiredCounter metric.Int64Counter
+	evictedCounter metric.Int64Counter
+}
+
+// NewMemoryStore initialises a new MemoryStore, holding at most maxLen resources.
+func NewMemoryStore(expiration time.Duration, maxLen int) *MemoryStore {
+	if maxLen < 1 {
+		panic("maxLen should be at least 1")
+	}
+
+	meter := metric.Must(instr.New().Meter)
+
+	return &MemoryStore{
+		resources:      make(map[t.Resource]*list.Element, maxLen),
+		order:          list.New(),
+		Expiration:     expiration,
+		MaxLen:         maxLen,
+		now:            time.Now,
+		expiredCounter: meter.NewInt64Counter("sniffer.lastseen.expired"),
+		evictedCounter: meter.NewInt64Counter("sniffer.lastseen.evicted"),
+	}
+}
+
+func (s *MemoryStore) remove(e *list.Element) {
+	s.order.Remove(e)
+	delete(s.resources, e.Value.(*memoryEntry).resource)
+}
+
+// expire removes expired resources from the front of the store.
+func (s *MemoryStore) expire() {
+	now := s.now()
+	cnt := 0
+
+	for e := s.order.Front(); e != nil; e = s.order.Front() {
+		if now.Sub(e.Value.(*memoryEntry).lastSeen) <= s.Expiration {
+			break
+		}
+
+		s.remove(e)
+		cnt++
+	}
+
+	if cnt > 0 {
+		atomic.AddUint64(&s.expired, uint64(cnt))
+		s.expiredCounter.Add(context.Background(), int64(cnt))
+	}
+}
+
+// evict removes the least recently updated resource.
+func (s *MemoryStore) evict() {
+	s.remove(s.order.Front())
+
+	atomic.AddUint64(&s.evicted, 1)
+	s.evictedCounter.Add(context.Background(), 1)
+}
+
+// Get returns the time a resource was last seen and whether it is known.
+func (s *MemoryStore) Get(r t.Resource) (time.Time, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, present := s.resources[r]
+	if !present {
+		return time.Time{}, false, nil
+	}
+
+	return e.Value.(*memoryEntry).lastSeen, true, nil
+}
+
+// Set stores the time a resource was last seen.
+func (s *MemoryStore) Set(r t.Resource, lastSeen time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expire()
+
+	if e, present := s.resources[r]; present {
+		e.Value.(*memoryEntry).lastSeen = lastSeen
+		s.order.MoveToBack(e)
+		return nil
+	}
+
+	s.add(r, lastSeen)
+
+	return nil
+}
+
+// SetIfAbsent stores the time a resource was last seen unless it is known, returning whether it was stored.
+func (s *MemoryStore) SetIfAbsent(r t.Resource, lastSeen time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expire()
+
+	if _, present := s.resources[r]; present {
+		return false, nil
+	}
+
+	s.add(r, lastSeen)
+
+	return true, nil
+}
+
+// add adds a resource which is not in the store, evicting the least recently updated resource when full.
+func (s *MemoryStore) add(r t.Resource, lastSeen time.Time) {
+	if s.order.Len() >= s.MaxLen {
+		s.evict()
+	}
+
+	s.resources[r] = s.order.PushBack(&memoryEntry{
+		resource: r,
+		lastSeen: lastSeen,
+	})
+}
+
+// Len returns the amount of resources in the store.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.order.Len()
+}
+
+// Expired returns the total amount of resources removed from the store after expiring.
+func (s *MemoryStore) Expired() uint64 {
+	return atomic.LoadUint64(&s.expired)
+}
+
+// Evicted returns the total amount of resources removed from the store before expiring, because it was full.
+func (s *MemoryStore) Evicted() uint64 {
+	return atomic.LoadUint64(&s.evicted)
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ LastSeenStore = &MemoryStore{}
@@ -0,0 +1,194 @@
+package providerfilters
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ipfs-search/ipfs-search/types"
+)
+
+func makeResource(i int) types.Resource {
+	return types.Resource{
+		Protocol: types.IPFSProtocol,
+		ID:       "Qm" + strconv.Itoa(i),
+	}
+}
+
+func TestMemoryStoreEvict(t *testing.T) {
+	assert := assert.New(t)
+
+	s := NewMemoryStore(time.Hour, 2)
+	now := time.Now()
+
+	s.Set(makeResource(1), now)
+	s.Set(makeResource(2), now)
+	s.Set(makeResource(3), now)
+
+	assert.Equal(2, s.Len())
+	assert.Equal(uint64(1), s.Evicted())
+	assert.Equal(uint64(0), s.Expired())
+
+	// Least recently updated is evicted
+	_, present, _ := s.Get(makeResource(1))
+	assert.False(present)
+
+	_, present, _ = s.Get(makeResource(3))
+	assert.True(present)
+}
+
+func TestMemoryStoreUpdate(t *testing.T) {
+	assert := assert.New(t)
+
+	s := NewMemoryStore(time.Hour, 2)
+	now := time.Now()
+
+	s.Set(makeResource(1), now)
+	s.Set(makeResource(2), now)
+
+	// Updating makes 1 most recently updated, evicting 2 next.
+	s.Set(makeResource(1), now.Add(time.Second))
+	s.Set(makeResource(3), now)
+
+	lastSeen, present, _ := s.Get(makeResource(1))
+	assert.True(present)
+	assert.Equal(now.Add(time.Second), lastSeen)
+
+	_, present, _ = s.Get(makeResource(2))
+	assert.False(present)
+}
+
+func TestMemoryStoreExpire(t *testing.T) {
+	assert := assert.New(t)
+
+	s := NewMemoryStore(time.Hour, 10)
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	s.Set(makeResource(1), now.Add(-2*time.Hour))
+	s.Set(makeResource(2), now.Add(-90*time.Minute))
+	s.Set(makeResource(3), now.Add(-time.Minute))
+
+	// Expired resources are removed on the next Set.
+	s.Set(makeResource(4), now)
+
+	assert.Equal(2, s.Len())
+	assert.Equal(uint64(2), s.Expired())
+	assert.Equal(uint64(0), s.Evicted())
+
+	_, present, _ := s.Get(makeResource(2))
+	assert.False(present)
+
+	_, present, _ = s.Get(makeResource(3))
+	assert.True(present)
+}
+
+func makeResources(n int) []types.Resource {
+	resources := make([]types.Resource, n)
+	for i := range resources {
+		resources[i] = makeResource(i)
+	}
+
+	return resources
+}
+
+// fillMemoryStore returns a full MemoryStore with maxLen resources, none expired.
+func fillMemoryStore(maxLen int) *MemoryStore {
+	s := NewMemoryStore(time.Hour, maxLen)
+	now := time.Now()
+
+	for _, r := range makeResources(maxLen) {
+		s.Set(r, now)
+	}
+
+	return s
+}
+
+var benchmarkSizes = []int{1000, 100000, 1000000, 4000000}
+
+// BenchmarkMemoryStoreEvict measures Set() of new resources on a full store, evicting on every call.
+func BenchmarkMemoryStoreEvict(b *testing.B) {
+	for _, size := range benchmarkSizes {
+		b.Run(fmt.Sprintf("%d", size), func(b *testing.B) {
+			s := fillMemoryStore(size)
+			resources := make([]types.Resource, b.N)
+			for i := range resources {
+				resources[i] = makeResource(size + i)
+			}
+			now := time.Now()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for _, r := range resources {
+				s.Set(r, now)
+			}
+		})
+	}
+}
+
+// BenchmarkMemoryStoreExpire measures Set() of new resources on a full store, expiring one resource per call.
+func BenchmarkMemoryStoreExpire(b *testing.B) {
+	for _, size := range benchmarkSizes {
+		b.Run(fmt.Sprintf("%d", size), func(b *testing.B) {
+			s := NewMemoryStore(time.Hour, size)
+
+			// Resources seen one second apart, the oldest one hour before the start.
+			start := time.Now().Add(-time.Hour)
+			for i, r := range makeResources(size) {
+				s.Set(r, start.Add(time.Duration(i-size)*time.Second))
+			}
+
+			resources := make([]types.Resource, b.N)
+			for i := range resources {
+				resources[i] = makeResource(size + i)
+			}
+
+			// Advance time one second every call, expiring the oldest resource.
+			now := start
+			s.now = func() time.Time { return now }
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for _, r := range resources {
+				now = now.Add(time.Second)
+				s.Set(r, now)
+			}
+		})
+	}
+}
+
+// BenchmarkLastSeenFilter measures filtering of resources on a full store, where half of the resources are known.
+func BenchmarkLastSeenFilter(b *testing.B) {
+	for _, size := range benchmarkSizes {
+		b.Run(fmt.Sprintf("%d", size), func(b *testing.B) {
+			f := NewLastSeenFilter(fillMemoryStore(size), time.Hour)
+			providers := make([]types.Provider, b.N)
+			for i := range providers {
+				r := makeResource(size/2 + i)
+				providers[i] = types.Provider{
+					Resource: &r,
+					Date:     time.Now(),
+				}
+			}
+
+			// Prevent measuring logging.
+			log.SetOutput(ioutil.Discard)
+			defer log.SetOutput(os.Stderr)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for _, p := range providers {
+				f.Filter(p)
+			}
+		})
+	}
+}
@@ -1,11 +1,14 @@
 package providerfilters
 
 import (
+	"sync"
+
 	"github.com/ipfs-search/ipfs-search/types"
 )
 
-// MockFilter represents a mock for a Filter.
+// MockFilter represents a mock for a Filter. It is safe for concurrent use.
 type MockFilter struct {
+	mu    sync.Mutex
 	Calls int
 	R     bool
 	Err   error
@@ -14,6 +17,9 @@ type MockFilter struct {
 
 // Filter returns the specified mock result and/or error and increments calls.
 func (m *MockFilter) Filter(p types.Provider) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	m.P = p
 	m.Calls++
 	return m.R, m.Err
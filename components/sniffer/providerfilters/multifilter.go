@@ -12,6 +12,7 @@ var (
 )
 
 // MultiFilter efficiently combines multiple filters into a single filter.
+// It is safe for concurrent use, as its filters are.
 type MultiFilter struct {
 	filters []Filter
 }
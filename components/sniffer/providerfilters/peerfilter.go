@@ -0,0 +1,63 @@
+package providerfilters
+
+import (
+	"fmt"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// PeerFilter filters Providers by their peer ID, using allow and deny lists. It is safe for concurrent use.
+type PeerFilter struct {
+	allow map[string]bool // Empty to allow any peer which is not denied.
+	deny  map[string]bool
+}
+
+// NewPeerFilter returns a pointer to a new PeerFilter. When allow is empty, all peers not in deny are included.
+func NewPeerFilter(allow, deny []string) *PeerFilter {
+	f := &PeerFilter{
+		allow: make(map[string]bool, len(allow)),
+		deny:  make(map[string]bool, len(deny)),
+	}
+
+	for _, p := range allow {
+		f.allow[p] = true
+	}
+
+	for _, p := range deny {
+		f.deny[p] = true
+	}
+
+	return f
+}
+
+// newPeerFilterFromParams creates a PeerFilter from the comma-separated parameters "allow" and "deny".
+func newPeerFilterFromParams(params Params, _ *Deps) (Filter, error) {
+	if err := params.Check("allow", "deny"); err != nil {
+		return nil, err
+	}
+
+	allow, deny := params.List("allow"), params.List("deny")
+	if len(allow) == 0 && len(deny) == 0 {
+		return nil, fmt.Errorf("%w: either allow or deny is required", ErrInvalidParam)
+	}
+
+	return NewPeerFilter(allow, deny), nil
+}
+
+// Filter takes a Provider and returns true when it is to be included, false
+// when not and an error when unexpected condition occur.
+func (f *PeerFilter) Filter(p t.Provider) (bool, error) {
+	if f.deny[p.Provider] {
+		return false, nil
+	}
+
+	if len(f.allow) > 0 && !f.allow[p.Provider] {
+		return false, nil
+	}
+
+	return true, nil
+}
+
+func init() {
+	Register("peer", newPeerFilterFromParams)
+}
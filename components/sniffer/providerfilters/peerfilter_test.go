@@ -0,0 +1,44 @@
+package providerfilters
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ipfs-search/ipfs-search/types"
+)
+
+func TestPeerAllow(t *testing.T) {
+	assert := assert.New(t)
+
+	f := NewPeerFilter([]string{"QmGood"}, nil)
+
+	include, err := f.Filter(types.Provider{Provider: "QmGood"})
+	assert.NoError(err)
+	assert.True(include)
+
+	include, err = f.Filter(types.Provider{Provider: "QmOther"})
+	assert.NoError(err)
+	assert.False(include)
+}
+
+func TestPeerDeny(t *testing.T) {
+	assert := assert.New(t)
+
+	f := NewPeerFilter(nil, []string{"QmBad"})
+
+	include, err := f.Filter(types.Provider{Provider: "QmBad"})
+	assert.NoError(err)
+	assert.False(include)
+
+	include, err = f.Filter(types.Provider{Provider: "QmOther"})
+	assert.NoError(err)
+	assert.True(include)
+}
+
+func TestPeerNoParams(t *testing.T) {
+	_, err := newPeerFilterFromParams(Params{}, nil)
+
+	assert.True(t, errors.Is(err, ErrInvalidParam))
+}
@@ -0,0 +1,264 @@
+package providerfilters
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/api/metric"
+	"go.opentelemetry.io/otel/label"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+const (
+	// RateLimitDebugPath is where the RateLimitFilter's offenders are served when a debug mux is available.
+	RateLimitDebugPath = "/debug/sniffer/ratelimit"
+
+	// topOffenders is the amount of offending peers reported in metrics.
+	topOffenders = 10
+)
+
+type bucket struct {
+	peer     string
+	tokens   float64
+	last     time.Time
+	admitted uint64
+	dropped  uint64
+	excess   uint64
+}
+
+// Offender represents a peer exceeding its rate limit.
+type Offender struct {
+	Peer     string `json:"peer"`
+	Admitted uint64 `json:"admitted"`
+	Dropped  uint64 `json:"dropped"`
+}
+
+// RateLimitFilter limits the rate at which Providers are included per peer, using token buckets.
+//
+// Every peer may provide Burst records at once, after which its bucket is refilled at Rate records per second,
+// based on the Provider's Date. Of the records in excess, ExcessRatio is included nonetheless. At most MaxPeers
+// buckets are kept; when full, the least recently active peer is forgotten. It is safe for concurrent use.
+type RateLimitFilter struct {
+	Rate        float64
+	Burst       float64
+	ExcessRatio float64
+	MaxPeers    int
+
+	mu      sync.Mutex
+	buckets map[string]*list.Element
+	order   *list.List // Buckets, least recently active first.
+
+	admittedCounter metric.Int64Counter
+	droppedCounter  metric.Int64Counter
+}
+
+// NewRateLimitFilter returns a pointer to a new RateLimitFilter.
+func NewRateLimitFilter(rate, burst, excessRatio float64, maxPeers int) *RateLimitFilter {
+	if maxPeers < 1 {
+		panic("maxPeers should be at least 1")
+	}
+
+	f := &RateLimitFilter{
+		Rate:        rate,
+		Burst:       burst,
+		ExcessRatio: excessRatio,
+		MaxPeers:    maxPeers,
+		buckets:     make(map[string]*list.Element),
+		order:       list.New(),
+	}
+
+	meter := metric.Must(instr.New().Meter)
+	f.admittedCounter = meter.NewInt64Counter("sniffer.ratelimit.admitted")
+	f.droppedCounter = meter.NewInt64Counter("sniffer.ratelimit.dropped")
+	meter.NewInt64ValueObserver("sniffer.ratelimit.top_dropped", f.observe)
+
+	return f
+}
+
+// newRateLimitFilterFromParams creates a RateLimitFilter from the parameters "rate" (records per second,
+// default 10), "burst" (default 100), "excess_ratio" (default 0) and "max_peers" (default 65536).
+// When deps has a Mux, offenders are served on RateLimitDebugPath.
+func newRateLimitFilterFromParams(params Params, deps *Deps) (Filter, error) {
+	if err := params.Check("rate", "burst", "excess_ratio", "max_peers"); err != nil {
+		return nil, err
+	}
+
+	rate, err := params.Float("rate", 10)
+	if err != nil {
+		return nil, err
+	}
+
+	burst, err := params.Float("burst", 100)
+	if err != nil {
+		return nil, err
+	}
+
+	excessRatio, err := params.Float("excess_ratio", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	maxPeers, err := params.Int("max_peers", 65536)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case rate <= 0:
+		return nil, fmt.Errorf("%w: rate should be positive", ErrInvalidParam)
+	case burst < 1:
+		return nil, fmt.Errorf("%w: burst should be at least 1", ErrInvalidParam)
+	case excessRatio < 0 || excessRatio > 1:
+		return nil, fmt.Errorf("%w: excess_ratio should be between 0 and 1", ErrInvalidParam)
+	case maxPeers < 1:
+		return nil, fmt.Errorf("%w: max_peers should be at least 1", ErrInvalidParam)
+	}
+
+	f := NewRateLimitFilter(rate, burst, excessRatio, maxPeers)
+
+	if deps != nil && deps.Mux != nil {
+		req := &http.Request{URL: &url.URL{Path: RateLimitDebugPath}}
+		if _, pattern := deps.Mux.Handler(req); pattern == RateLimitDebugPath {
+			return nil, fmt.Errorf("%w: only a single ratelimit filter is supported", ErrInvalidParam)
+		}
+
+		deps.Mux.Handle(RateLimitDebugPath, f)
+	}
+
+	return f, nil
+}
+
+// getBucket returns the bucket for a peer, creating it with a full bucket when necessary.
+func (f *RateLimitFilter) getBucket(peer string, now time.Time) *bucket {
+	if e, present := f.buckets[peer]; present {
+		f.order.MoveToBack(e)
+		return e.Value.(*bucket)
+	}
+
+	if f.order.Len() >= f.MaxPeers {
+		e := f.order.Front()
+		f.order.Remove(e)
+		delete(f.buckets, e.Value.(*bucket).peer)
+	}
+
+	b := &bucket{
+		peer:   peer,
+		tokens: f.Burst,
+		last:   now,
+	}
+	f.buckets[peer] = f.order.PushBack(b)
+
+	return b
+}
+
+// take attempts to take a token from a bucket and returns whether the record is to be included.
+func (f *RateLimitFilter) take(b *bucket, now time.Time) bool {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		// Dates out of order don't refill.
+		b.tokens = math.Min(f.Burst, b.tokens+elapsed.Seconds()*f.Rate)
+		b.last = now
+	}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true
+	}
+
+	// Include ExcessRatio of the excess records, evenly spread.
+	b.excess++
+	return math.Floor(float64(b.excess)*f.ExcessRatio) > math.Floor(float64(b.excess-1)*f.ExcessRatio)
+}
+
+// Filter takes a Provider and returns true when it is to be included, false
+// when not and an error when unexpected condition occur.
+func (f *RateLimitFilter) Filter(p t.Provider) (bool, error) {
+	f.mu.Lock()
+
+	b := f.getBucket(p.Provider, p.Date)
+	include := f.take(b, p.Date)
+
+	if include {
+		b.admitted++
+	} else {
+		b.dropped++
+	}
+
+	f.mu.Unlock()
+
+	if include {
+		f.admittedCounter.Add(context.Background(), 1)
+	} else {
+		f.droppedCounter.Add(context.Background(), 1)
+	}
+
+	return include, nil
+}
+
+// Top returns at most n peers which had records dropped, most dropped first.
+func (f *RateLimitFilter) Top(n int) []Offender {
+	f.mu.Lock()
+
+	offenders := []Offender{}
+	for e := f.order.Front(); e != nil; e = e.Next() {
+		if b := e.Value.(*bucket); b.dropped > 0 {
+			offenders = append(offenders, Offender{
+				Peer:     b.peer,
+				Admitted: b.admitted,
+				Dropped:  b.dropped,
+			})
+		}
+	}
+
+	f.mu.Unlock()
+
+	sort.Slice(offenders, func(i, j int) bool {
+		return offenders[i].Dropped > offenders[j].Dropped
+	})
+
+	if len(offenders) > n {
+		offenders = offenders[:n]
+	}
+
+	return offenders
+}
+
+// observe reports the dropped records of the top offenders.
+func (f *RateLimitFilter) observe(_ context.Context, result metric.Int64ObserverResult) {
+	for _, o := range f.Top(topOffenders) {
+		result.Observe(int64(o.Dropped), label.String("peerid", o.Peer))
+	}
+}
+
+// ServeHTTP serves the top offenders as JSON. The amount of offenders can be set with the `n` query parameter.
+func (f *RateLimitFilter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n := 20
+
+	if v := r.URL.Query().Get("n"); v != "" {
+		var err error
+		if n, err = strconv.Atoi(v); err != nil || n < 0 {
+			http.Error(w, "invalid n", http.StatusBadRequest)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(f.Top(n)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func init() {
+	Register("ratelimit", newRateLimitFilterFromParams)
+}
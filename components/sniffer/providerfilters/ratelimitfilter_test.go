@@ -0,0 +1,142 @@
+package providerfilters
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ipfs-search/ipfs-search/types"
+)
+
+func makePeerProvider(peer string, date time.Time) types.Provider {
+	p := makeProvider(nil)
+	p.Provider = peer
+	p.Date = date
+
+	return *p
+}
+
+func admitted(f Filter, peer string, date time.Time, n int) int {
+	cnt := 0
+
+	for i := 0; i < n; i++ {
+		if include, _ := f.Filter(makePeerProvider(peer, date)); include {
+			cnt++
+		}
+	}
+
+	return cnt
+}
+
+func TestRateLimitBurst(t *testing.T) {
+	assert := assert.New(t)
+
+	f := NewRateLimitFilter(1, 10, 0, 16)
+	now := time.Now()
+
+	assert.Equal(10, admitted(f, "QmSpammer", now, 100))
+
+	// Other peers are not affected
+	assert.Equal(10, admitted(f, "QmOther", now, 10))
+}
+
+func TestRateLimitRefill(t *testing.T) {
+	assert := assert.New(t)
+
+	f := NewRateLimitFilter(2, 10, 0, 16)
+	now := time.Now()
+
+	assert.Equal(10, admitted(f, "QmPeer", now, 20))
+
+	// Refilled at 2 per second
+	assert.Equal(6, admitted(f, "QmPeer", now.Add(3*time.Second), 20))
+
+	// Never more than burst
+	assert.Equal(10, admitted(f, "QmPeer", now.Add(time.Hour), 20))
+
+	// Out of order dates don't refill
+	assert.Equal(0, admitted(f, "QmPeer", now, 20))
+}
+
+func TestRateLimitExcessRatio(t *testing.T) {
+	assert := assert.New(t)
+
+	f := NewRateLimitFilter(1, 10, 0.1, 16)
+
+	assert.Equal(10+9, admitted(f, "QmPeer", time.Now(), 100))
+}
+
+func TestRateLimitMaxPeers(t *testing.T) {
+	assert := assert.New(t)
+
+	f := NewRateLimitFilter(1, 1, 0, 2)
+	now := time.Now()
+
+	admitted(f, "QmPeer1", now, 2)
+	admitted(f, "QmPeer2", now, 2)
+	admitted(f, "QmPeer3", now, 2)
+
+	// QmPeer1 has been forgotten and has a full bucket again
+	assert.Equal(1, admitted(f, "QmPeer1", now, 2))
+}
+
+func TestRateLimitTop(t *testing.T) {
+	assert := assert.New(t)
+
+	f := NewRateLimitFilter(1, 1, 0, 16)
+	now := time.Now()
+
+	admitted(f, "QmWellBehaved", now, 1)
+	admitted(f, "QmSpammer", now, 10)
+	admitted(f, "QmOffender", now, 3)
+
+	assert.Equal([]Offender{
+		{Peer: "QmSpammer", Admitted: 1, Dropped: 9},
+		{Peer: "QmOffender", Admitted: 1, Dropped: 2},
+	}, f.Top(5))
+
+	assert.Len(f.Top(1), 1)
+}
+
+func TestRateLimitDebugEndpoint(t *testing.T) {
+	assert := assert.New(t)
+
+	mux := http.NewServeMux()
+	f, err := New([]Spec{{Type: "ratelimit", Params: Params{"rate": "1", "burst": "1"}}}, &Deps{Mux: mux})
+	assert.NoError(err)
+
+	admitted(f, "QmSpammer", time.Now(), 3)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", RateLimitDebugPath+"?n=1", nil))
+
+	assert.Equal(http.StatusOK, w.Code)
+
+	var offenders []Offender
+	assert.NoError(json.NewDecoder(w.Body).Decode(&offenders))
+	assert.Equal([]Offender{{Peer: "QmSpammer", Admitted: 1, Dropped: 2}}, offenders)
+
+	// Only a single ratelimit filter can serve the endpoint
+	_, err = New([]Spec{{Type: "ratelimit"}}, &Deps{Mux: mux})
+	assert.True(errors.Is(err, ErrInvalidParam))
+}
+
+func TestRateLimitInvalidParams(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, params := range []Params{
+		{"rate": "0"},
+		{"burst": "0.5"},
+		{"excess_ratio": "2"},
+		{"max_peers": "0"},
+		{"rate": "fast"},
+	} {
+		_, err := newRateLimitFilterFromParams(params, nil)
+		assert.True(errors.Is(err, ErrInvalidParam), "%v", params)
+	}
+}
@@ -0,0 +1,117 @@
+package providerfilters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ipfs-search/ipfs-search/utils/redis"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// redisTimeout is the maximum duration of a single request to Redis.
+const redisTimeout = 5 * time.Second
+
+// RedisKeyPrefix is the prefix for keys in which a RedisStore stores resources.
+const RedisKeyPrefix = "ipfs-search:lastseen:"
+
+// RedisStore is a LastSeenStore in a server speaking the Redis protocol, allowing it to be shared between sniffers.
+// Resources expire from the store after the expiration.
+type RedisStore struct {
+	client     *redis.Client
+	Expiration time.Duration
+}
+
+// NewRedisStore initialises a new RedisStore.
+func NewRedisStore(client *redis.Client, expiration time.Duration) *RedisStore {
+	return &RedisStore{
+		client:     client,
+		Expiration: expiration,
+	}
+}
+
+func redisKey(r t.Resource) string {
+	return fmt.Sprintf("%s%s:%s", RedisKeyPrefix, r.Protocol, r.ID)
+}
+
+// Get returns the time a resource was last seen and whether it is known.
+func (s *RedisStore) Get(r t.Resource) (time.Time, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	value, err := s.client.String(ctx, "GET", redisKey(r))
+
+	if errors.Is(err, redis.ErrNil) {
+		return time.Time{}, false, nil
+	}
+
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	nanos, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("%w: %v", errInvalidLastSeen, err)
+	}
+
+	return time.Unix(0, nanos), true, nil
+}
+
+// Set stores the time a resource was last seen, expiring it after the expiration.
+func (s *RedisStore) Set(r t.Resource, lastSeen time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	ttl, ok := s.ttl(lastSeen)
+	if !ok {
+		// Already expired; no use in storing it.
+		return nil
+	}
+
+	_, err := s.client.Do(ctx, "SET", redisKey(r), strconv.FormatInt(lastSeen.UnixNano(), 10), "PX", ttl)
+
+	return err
+}
+
+// SetIfAbsent stores the time a resource was last seen unless it is known, returning whether it was stored.
+// A single SET with NX makes sure only one of the sniffers sharing the server stores (and includes) a resource.
+func (s *RedisStore) SetIfAbsent(r t.Resource, lastSeen time.Time) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	ttl, ok := s.ttl(lastSeen)
+	if !ok {
+		// Already expired; nothing to store.
+		return true, nil
+	}
+
+	_, err := s.client.String(ctx, "SET", redisKey(r), strconv.FormatInt(lastSeen.UnixNano(), 10), "PX", ttl, "NX")
+
+	if errors.Is(err, redis.ErrNil) {
+		// Not set: the resource is known.
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// ttl returns the expiration relative to lastSeen in whole milliseconds, rounded up, and false when expired.
+func (s *RedisStore) ttl(lastSeen time.Time) (string, bool) {
+	ttl := time.Until(lastSeen.Add(s.Expiration))
+	if ttl < time.Millisecond {
+		return "", false
+	}
+
+	return strconv.FormatInt(int64((ttl+time.Millisecond-1)/time.Millisecond), 10), true
+}
+
+// Close closes the connection to the server.
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ LastSeenStore = &RedisStore{}
@@ -0,0 +1,160 @@
+package providerfilters
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrUnknownFilter is returned when a Spec refers to a filter which has not been registered.
+	ErrUnknownFilter = errors.New("unknown filter")
+
+	// ErrInvalidParam is returned when a filter is configured with an unknown or malformed parameter.
+	ErrInvalidParam = errors.New("invalid filter parameter")
+)
+
+// Spec declares a filter in the pipeline, as configured by operators.
+type Spec struct {
+	Type   string // Name under which the filter is registered, e.g. "cid".
+	Params Params // Filter-specific parameters.
+}
+
+// Params are string-valued parameters for a filter. Lists are comma-separated.
+type Params map[string]string
+
+// Check returns ErrInvalidParam when a parameter is set which is not in allowed.
+func (p Params) Check(allowed ...string) error {
+	for k := range p {
+		found := false
+		for _, a := range allowed {
+			if k == a {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return fmt.Errorf("%w: unknown parameter '%s'", ErrInvalidParam, k)
+		}
+	}
+
+	return nil
+}
+
+// List returns the comma-separated values of a parameter, or nil when it is not set.
+func (p Params) List(key string) []string {
+	v, ok := p[key]
+	if !ok {
+		return nil
+	}
+
+	var l []string
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			l = append(l, s)
+		}
+	}
+
+	return l
+}
+
+// Float returns the value of a parameter as a float, or def when it is not set.
+func (p Params) Float(key string, def float64) (float64, error) {
+	v, ok := p[key]
+	if !ok {
+		return def, nil
+	}
+
+	f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s: %v", ErrInvalidParam, key, err)
+	}
+
+	return f, nil
+}
+
+// Int returns the value of a parameter as an int, or def when it is not set.
+func (p Params) Int(key string, def int) (int, error) {
+	v, ok := p[key]
+	if !ok {
+		return def, nil
+	}
+
+	i, err := strconv.Atoi(strings.TrimSpace(v))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s: %v", ErrInvalidParam, key, err)
+	}
+
+	return i, nil
+}
+
+// Deps holds shared dependencies which filters may be constructed with.
+type Deps struct {
+	LastSeen           LastSeenStore
+	LastSeenExpiration time.Duration
+	Mux                *http.ServeMux // Optional; filters may serve debug information on it.
+}
+
+// Constructor returns a new Filter from its parameters and shared dependencies.
+type Constructor func(Params, *Deps) (Filter, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Constructor)
+)
+
+// Register makes a filter available under name. It panics when a filter is registered twice.
+func Register(name string, c Constructor) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, dup := registry[name]; dup {
+		panic("filter registered twice: " + name)
+	}
+
+	registry[name] = c
+}
+
+// Registered returns the sorted names of all registered filters.
+func Registered() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// New constructs the filters in specs, combining them in order into a MultiFilter.
+func New(specs []Spec, deps *Deps) (*MultiFilter, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	filters := make([]Filter, 0, len(specs))
+
+	for _, spec := range specs {
+		c, ok := registry[spec.Type]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownFilter, spec.Type)
+		}
+
+		f, err := c(spec.Params, deps)
+		if err != nil {
+			return nil, fmt.Errorf("filter %s: %w", spec.Type, err)
+		}
+
+		filters = append(filters, f)
+	}
+
+	return NewMultiFilter(filters...), nil
+}
@@ -0,0 +1,131 @@
+package providerfilters
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ipfs-search/ipfs-search/types"
+)
+
+func TestRegistered(t *testing.T) {
+	assert := assert.New(t)
+
+	names := Registered()
+
+	assert.Contains(names, "cid")
+	assert.Contains(names, "lastseen")
+	assert.Contains(names, "peer")
+	assert.Contains(names, "sample")
+}
+
+func TestNewPipeline(t *testing.T) {
+	assert := assert.New(t)
+
+	deps := &Deps{
+		LastSeen:           NewMemoryStore(time.Hour, 16),
+		LastSeenExpiration: time.Hour,
+	}
+
+	specs := []Spec{
+		{Type: "peer", Params: Params{"deny": "QmBadPeer"}},
+		{Type: "cid", Params: Params{"codecs": "raw, protobuf", "versions": "0,1"}},
+		{Type: "lastseen"},
+	}
+
+	f, err := New(specs, deps)
+	assert.NoError(err)
+
+	p := makeProvider(nil)
+
+	include, err := f.Filter(*p)
+	assert.NoError(err)
+	assert.True(include)
+
+	// Seen before
+	include, err = f.Filter(*p)
+	assert.NoError(err)
+	assert.False(include)
+
+	// Denied peer
+	p = makeProvider(nil)
+	p.Provider = "QmBadPeer"
+	include, err = f.Filter(*p)
+	assert.NoError(err)
+	assert.False(include)
+}
+
+func TestNewUnknownFilter(t *testing.T) {
+	_, err := New([]Spec{{Type: "unknown"}}, &Deps{})
+
+	assert.True(t, errors.Is(err, ErrUnknownFilter))
+}
+
+func TestNewUnknownParam(t *testing.T) {
+	_, err := New([]Spec{{Type: "cid", Params: Params{"codec": "raw"}}}, &Deps{})
+
+	assert.True(t, errors.Is(err, ErrInvalidParam))
+}
+
+func TestNewInvalidCodec(t *testing.T) {
+	_, err := New([]Spec{{Type: "cid", Params: Params{"codecs": "nonsense"}}}, &Deps{})
+
+	assert.True(t, errors.Is(err, ErrInvalidParam))
+}
+
+func TestNewLastSeenWithoutStore(t *testing.T) {
+	_, err := New([]Spec{{Type: "lastseen"}}, &Deps{})
+
+	assert.Error(t, err)
+}
+
+func TestParamsList(t *testing.T) {
+	assert := assert.New(t)
+
+	p := Params{"l": " a, b,,c "}
+
+	assert.Equal([]string{"a", "b", "c"}, p.List("l"))
+	assert.Nil(p.List("missing"))
+}
+
+func TestCidVersion(t *testing.T) {
+	assert := assert.New(t)
+
+	f, err := newCidFilterFromParams(Params{"versions": "1"}, nil)
+	assert.NoError(err)
+
+	// CIDv0
+	_, err = f.Filter(*makeProvider(nil))
+	assert.True(errors.Is(err, errUnsupportedVersion))
+
+	r := &types.Resource{
+		Protocol: types.IPFSProtocol,
+		ID:       "bafybeihpsvpelgck42nikpiiuvgbf3ob3ydjkzkq5267mnp5jq5uhzatcy",
+	}
+
+	include, err := f.Filter(*makeProvider(r))
+	assert.NoError(err)
+	assert.True(include)
+}
+
+func TestCidCodecs(t *testing.T) {
+	assert := assert.New(t)
+
+	f, err := newCidFilterFromParams(Params{"codecs": "raw"}, nil)
+	assert.NoError(err)
+
+	// DagProtobuf
+	_, err = f.Filter(*makeProvider(nil))
+	assert.True(errors.Is(err, errUnsupportedCodec))
+
+	r := &types.Resource{
+		Protocol: types.IPFSProtocol,
+		ID:       "bafkreiblvqc3q73ygovlzaxz4iilm5fopppcdc3uzkrtepjsgkvyev3kgy",
+	}
+
+	include, err := f.Filter(*makeProvider(r))
+	assert.NoError(err)
+	assert.True(include)
+}
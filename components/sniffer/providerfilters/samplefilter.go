@@ -0,0 +1,52 @@
+package providerfilters
+
+import (
+	"fmt"
+	"hash/fnv"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// SampleFilter includes a fixed ratio of resources. Sampling is deterministic on the resource, so
+// that every sniffer makes the same decision for the same CID. It is safe for concurrent use.
+type SampleFilter struct {
+	threshold uint64
+}
+
+// NewSampleFilter returns a pointer to a new SampleFilter, including ratio (between 0 and 1) of resources.
+func NewSampleFilter(ratio float64) *SampleFilter {
+	return &SampleFilter{
+		threshold: uint64(ratio * (1 << 32)),
+	}
+}
+
+// newSampleFilterFromParams creates a SampleFilter from the "ratio" parameter.
+func newSampleFilterFromParams(params Params, _ *Deps) (Filter, error) {
+	if err := params.Check("ratio"); err != nil {
+		return nil, err
+	}
+
+	ratio, err := params.Float("ratio", -1)
+	if err != nil {
+		return nil, err
+	}
+
+	if ratio < 0 || ratio > 1 {
+		return nil, fmt.Errorf("%w: ratio should be between 0 and 1", ErrInvalidParam)
+	}
+
+	return NewSampleFilter(ratio), nil
+}
+
+// Filter takes a Provider and returns true when it is to be included, false
+// when not and an error when unexpected condition occur.
+func (f *SampleFilter) Filter(p t.Provider) (bool, error) {
+	h := fnv.New32a()
+	h.Write([]byte(p.ID))
+
+	return uint64(h.Sum32()) < f.threshold, nil
+}
+
+func init() {
+	Register("sample", newSampleFilterFromParams)
+}
@@ -0,0 +1,60 @@
+package providerfilters
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ipfs-search/ipfs-search/types"
+)
+
+func sample(f Filter, n int) int {
+	included := 0
+
+	for i := 0; i < n; i++ {
+		p := types.Provider{
+			Resource: &types.Resource{
+				Protocol: types.IPFSProtocol,
+				ID:       fmt.Sprintf("resource-%d", i),
+			},
+		}
+
+		if include, _ := f.Filter(p); include {
+			included++
+		}
+	}
+
+	return included
+}
+
+func TestSampleRatio(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(0, sample(NewSampleFilter(0), 1000))
+	assert.Equal(1000, sample(NewSampleFilter(1), 1000))
+	assert.InDelta(2500, sample(NewSampleFilter(0.25), 10000), 250)
+}
+
+func TestSampleDeterministic(t *testing.T) {
+	assert := assert.New(t)
+
+	f := NewSampleFilter(0.5)
+	p := *makeProvider(nil)
+
+	first, _ := f.Filter(p)
+
+	for i := 0; i < 10; i++ {
+		include, _ := f.Filter(p)
+		assert.Equal(first, include)
+	}
+}
+
+func TestSampleInvalidRatio(t *testing.T) {
+	_, err := newSampleFilterFromParams(Params{"ratio": "1.5"}, nil)
+	assert.True(t, errors.Is(err, ErrInvalidParam))
+
+	_, err = newSampleFilterFromParams(Params{}, nil)
+	assert.True(t, errors.Is(err, ErrInvalidParam))
+}
@@ -2,6 +2,7 @@ package queuer
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"go.opentelemetry.io/otel/api/trace"
@@ -14,11 +15,13 @@ import (
 	t "github.com/ipfs-search/ipfs-search/types"
 )
 
+var errClosed = errors.New("providers channel closed")
+
 // Queuer publishes an AnnotatedResource to a queue Publisher for Provider's it receives on a channel.
 type Queuer struct {
-	queue        queue.Publisher
-	providers    <-chan t.Provider
-	queueTimeout time.Duration
+	queue          queue.Publisher
+	providers      <-chan t.Provider
+	publishTimeout time.Duration
 	*instr.Instrumentation
 }
 
@@ -27,50 +30,65 @@ func New(q queue.Publisher, providers <-chan t.Provider) Queuer {
 	return Queuer{
 		queue:           q,
 		providers:       providers,
-		queueTimeout:    5 * time.Minute, // Kamikaze after 5 minutes of waiting
+		publishTimeout:  time.Minute, // Fail when the queue is unresponsive
 		Instrumentation: instr.New(),
 	}
 }
 
-func (q *Queuer) iterate(ctx context.Context) error {
-	// Never wait more than queueTimeout for a message
-	ctx, cancel := context.WithTimeout(ctx, q.queueTimeout)
+// Publish publishes a single Provider.
+func (q *Queuer) Publish(ctx context.Context, p t.Provider) error {
+	// Never wait more than publishTimeout for a publish
+	ctx, cancel := context.WithTimeout(ctx, q.publishTimeout)
 	defer cancel()
 
+	ctx = trace.ContextWithRemoteSpanContext(ctx, p.SpanContext)
+	_, span := q.Tracer.Start(ctx, "queue.Publish", trace.WithAttributes(
+		label.String("cid", p.ID),
+		label.String("peerid", p.Provider),
+	), trace.WithSpanKind(trace.SpanKindProducer))
+	defer span.End()
+
+	r := t.AnnotatedResource{
+		Resource: p.Resource,
+		Source: t.Source{
+			Provider:     p.Provider,
+			LastProvided: p.Date,
+		},
+	}
+
+	// Add with highest priority (9), as this is supposed to be available
+	err := q.queue.Publish(ctx, &r, 9)
+
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+	} else {
+		span.SetStatus(codes.Ok, "published")
+	}
+
+	return err
+}
+
+func (q *Queuer) iterate(ctx context.Context) error {
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
-	case p := <-q.providers:
-		return func() error {
-			ctx = trace.ContextWithRemoteSpanContext(ctx, p.SpanContext)
-			_, span := q.Tracer.Start(ctx, "queue.Publish", trace.WithAttributes(
-				label.String("cid", p.ID),
-				label.String("peerid", p.Provider),
-			), trace.WithSpanKind(trace.SpanKindProducer))
-			defer span.End()
-
-			r := t.AnnotatedResource{
-				Resource: p.Resource,
-			}
-
-			// Add with highest priority (9), as this is supposed to be available
-			err := q.queue.Publish(ctx, &r, 9)
-
-			if err != nil {
-				span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
-			} else {
-				span.SetStatus(codes.Ok, "published")
-			}
+	case p, ok := <-q.providers:
+		if !ok {
+			return errClosed
+		}
 
-			return err
-		}()
+		return q.Publish(ctx, p)
 	}
 }
 
-// Queue reads from the providers channel and queue's its items.
+// Queue reads from the providers channel and queue's its items, returning nil when the channel is closed.
 func (q *Queuer) Queue(ctx context.Context) error {
 	for {
 		if err := q.iterate(ctx); err != nil {
+			if err == errClosed {
+				return nil
+			}
+
 			return err
 		}
 	}
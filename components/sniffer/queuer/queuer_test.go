@@ -28,6 +28,10 @@ func (s *QueuerTestSuite) SetupTest() {
 	s.p = t.MockProvider()
 	s.r = &t.AnnotatedResource{
 		Resource: s.p.Resource,
+		Source: t.Source{
+			Provider:     s.p.Provider,
+			LastProvided: s.p.Date,
+		},
 	}
 }
 
@@ -51,6 +55,20 @@ func (s *QueuerTestSuite) TestQueueContextCancel() {
 	s.q.AssertNotCalled(s.T(), "Publish")
 }
 
+// TestQueueClosed tests whether we're returning without error when the providers channel is closed.
+func (s *QueuerTestSuite) TestQueueClosed() {
+	ch := make(chan t.Provider)
+	close(ch)
+
+	pq := New(s.q, ch)
+
+	err := pq.Queue(s.ctx)
+
+	s.NoError(err)
+
+	s.q.AssertNotCalled(s.T(), "Publish")
+}
+
 // TestQueuePublish tests whether a queued provider gets published.
 func (s *QueuerTestSuite) TestQueuePublish() {
 	s.q.On("Publish", mock.Anything, s.r, uint8(9)).Return(nil)
@@ -0,0 +1,165 @@
+/*
+Package recorder records sniffed EvtProviderPut events to a gzip-compressed file and replays them, so that the sniffer's
+filter and queuer pipeline can be exercised reproducibly without a live DHT.
+
+Recordings consist of newline-delimited JSON records.
+*/
+package recorder
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ipfs-search/ipfs-search/components/sniffer/eventsource"
+)
+
+// Record is a recorded EvtProviderPut.
+type Record struct {
+	CID    string    `json:"cid"`
+	PeerID string    `json:"peerid"`
+	Time   time.Time `json:"time"`
+}
+
+// Recorder writes Records to a recording. It is safe for concurrent use.
+type Recorder struct {
+	mu  sync.Mutex
+	f   *os.File
+	gz  *gzip.Writer
+	enc *json.Encoder
+}
+
+// Create creates a new recording at path, truncating it when it exists.
+func Create(path string) (*Recorder, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	gz := gzip.NewWriter(f)
+
+	return &Recorder{
+		f:   f,
+		gz:  gz,
+		enc: json.NewEncoder(gz),
+	}, nil
+}
+
+// Record records an event which occurred at time t.
+func (r *Recorder) Record(e eventsource.EvtProviderPut, t time.Time) error {
+	rec := Record{
+		CID:    e.CID.String(),
+		PeerID: e.PeerID.String(),
+		Time:   t,
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.enc.Encode(&rec)
+}
+
+// Close completes the recording. Records are only guaranteed to be readable after closing.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.gz.Close(); err != nil {
+		r.f.Close()
+		return err
+	}
+
+	return r.f.Close()
+}
+
+// Reader reads Records from a recording.
+type Reader struct {
+	f   *os.File
+	gz  *gzip.Reader
+	dec *json.Decoder
+}
+
+// Open opens the recording at path for reading.
+func Open(path string) (*Reader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	gz, err := gzip.NewReader(bufio.NewReader(f))
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("reading recording %s: %w", path, err)
+	}
+
+	return &Reader{
+		f:   f,
+		gz:  gz,
+		dec: json.NewDecoder(gz),
+	}, nil
+}
+
+// Read returns the next Record, or io.EOF at the end of the recording. Recordings which have not been closed
+// properly return io.ErrUnexpectedEOF at their end.
+func (r *Reader) Read() (Record, error) {
+	var rec Record
+
+	err := r.dec.Decode(&rec)
+
+	return rec, err
+}
+
+// Close closes the recording.
+func (r *Reader) Close() error {
+	r.gz.Close()
+	return r.f.Close()
+}
+
+// Replay calls f for every Record read from r, waiting for the recorded interval between Records divided by speed.
+// With a speed of 0, Records are replayed as fast as possible. It returns nil at the end of the recording.
+func Replay(ctx context.Context, r *Reader, speed float64, f func(context.Context, Record) error) error {
+	if speed < 0 {
+		return fmt.Errorf("invalid speed %f", speed)
+	}
+
+	var (
+		first   time.Time
+		started = time.Now()
+	)
+
+	for i := 0; ; i++ {
+		rec, err := r.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return err
+		}
+
+		if i == 0 {
+			first = rec.Time
+		}
+
+		if speed > 0 {
+			at := started.Add(time.Duration(float64(rec.Time.Sub(first)) / speed))
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Until(at)):
+			}
+		}
+
+		if err := f(ctx, rec); err != nil {
+			return err
+		}
+	}
+}
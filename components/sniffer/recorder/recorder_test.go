@@ -0,0 +1,146 @@
+package recorder
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/components/sniffer/eventsource"
+)
+
+type RecorderTestSuite struct {
+	suite.Suite
+	ctx    context.Context
+	cancel func()
+	dir    string
+	path   string
+	evt    eventsource.EvtProviderPut
+}
+
+func (s *RecorderTestSuite) SetupTest() {
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+
+	var err error
+	s.dir, err = ioutil.TempDir("", "recorder")
+	s.Require().NoError(err)
+
+	s.path = filepath.Join(s.dir, "recording.gz")
+
+	c, err := cid.Decode("QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp")
+	s.Require().NoError(err)
+
+	pid, err := peer.Decode("QmeTtFXm42Jb2todcKR538j6qHYxXt6suUzpF3rtT9FPSd")
+	s.Require().NoError(err)
+
+	s.evt = eventsource.EvtProviderPut{CID: c, PeerID: pid}
+}
+
+func (s *RecorderTestSuite) TearDownTest() {
+	s.cancel()
+	os.RemoveAll(s.dir)
+}
+
+// record records events at times.
+func (s *RecorderTestSuite) record(times ...time.Time) {
+	r, err := Create(s.path)
+	s.Require().NoError(err)
+
+	for _, t := range times {
+		s.Require().NoError(r.Record(s.evt, t))
+	}
+
+	s.Require().NoError(r.Close())
+}
+
+func (s *RecorderTestSuite) TestRoundTrip() {
+	now := time.Now().UTC()
+	s.record(now, now.Add(time.Second))
+
+	r, err := Open(s.path)
+	s.Require().NoError(err)
+	defer r.Close()
+
+	for _, t := range []time.Time{now, now.Add(time.Second)} {
+		rec, err := r.Read()
+		s.NoError(err)
+		s.Equal("QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp", rec.CID)
+		s.Equal("QmeTtFXm42Jb2todcKR538j6qHYxXt6suUzpF3rtT9FPSd", rec.PeerID)
+		s.True(t.Equal(rec.Time))
+	}
+
+	_, err = r.Read()
+	s.Equal(io.EOF, err)
+}
+
+func (s *RecorderTestSuite) TestOpenInvalid() {
+	s.Require().NoError(ioutil.WriteFile(s.path, []byte("invalid"), 0644))
+
+	_, err := Open(s.path)
+	s.Error(err)
+}
+
+func (s *RecorderTestSuite) TestReplaySpeed() {
+	now := time.Now()
+	s.record(now, now.Add(200*time.Millisecond), now.Add(400*time.Millisecond))
+
+	r, err := Open(s.path)
+	s.Require().NoError(err)
+	defer r.Close()
+
+	var replayed []time.Duration
+	started := time.Now()
+
+	err = Replay(s.ctx, r, 2, func(_ context.Context, _ Record) error {
+		replayed = append(replayed, time.Since(started))
+		return nil
+	})
+	s.NoError(err)
+
+	s.Len(replayed, 3)
+	s.GreaterOrEqual(int64(replayed[2]), int64(200*time.Millisecond))
+	s.Less(int64(replayed[2]), int64(400*time.Millisecond))
+}
+
+func (s *RecorderTestSuite) TestReplayFast() {
+	now := time.Now()
+	s.record(now, now.Add(time.Hour))
+
+	r, err := Open(s.path)
+	s.Require().NoError(err)
+	defer r.Close()
+
+	n := 0
+	err = Replay(s.ctx, r, 0, func(_ context.Context, _ Record) error {
+		n++
+		return nil
+	})
+	s.NoError(err)
+	s.Equal(2, n)
+}
+
+func (s *RecorderTestSuite) TestReplayCancel() {
+	now := time.Now()
+	s.record(now, now.Add(time.Hour))
+
+	r, err := Open(s.path)
+	s.Require().NoError(err)
+	defer r.Close()
+
+	err = Replay(s.ctx, r, 1, func(_ context.Context, _ Record) error {
+		s.cancel()
+		return nil
+	})
+	s.Equal(context.Canceled, err)
+}
+
+func TestRecorderTestSuite(t *testing.T) {
+	suite.Run(t, new(RecorderTestSuite))
+}
@@ -9,38 +9,73 @@ package sniffer
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"golang.org/x/sync/errgroup"
 	"log"
+	"net/http"
+	"sync/atomic"
 	"time"
 
 	// "go.opentelemetry.io/otel/codes"
 	"github.com/ipfs/go-datastore"
 	"github.com/libp2p/go-eventbus"
+	"github.com/libp2p/go-libp2p-kad-dht/providers"
+	"go.opentelemetry.io/otel/api/metric"
 
 	"github.com/ipfs-search/ipfs-search/components/queue"
+	"github.com/ipfs-search/ipfs-search/components/sniffer/breaker"
 	"github.com/ipfs-search/ipfs-search/components/sniffer/eventsource"
 	"github.com/ipfs-search/ipfs-search/components/sniffer/handler"
 	filters "github.com/ipfs-search/ipfs-search/components/sniffer/providerfilters"
 	"github.com/ipfs-search/ipfs-search/components/sniffer/queuer"
+	"github.com/ipfs-search/ipfs-search/components/sniffer/recorder"
+	"github.com/ipfs-search/ipfs-search/components/sniffer/spool"
 	filter "github.com/ipfs-search/ipfs-search/components/sniffer/streamfilter"
 
 	"github.com/ipfs-search/ipfs-search/instr"
 	t "github.com/ipfs-search/ipfs-search/types"
 )
 
+// Sources of sniffed provider records.
+const (
+	DatastoreSource     = "datastore"     // Put operations on the DHT's datastore.
+	ProviderStoreSource = "providerstore" // Provider records added to the DHT's ProviderStore.
+)
+
+// ErrUnknownEventSource is returned when an unknown source of provider records is configured.
+var ErrUnknownEventSource = errors.New("unknown event source")
+
 // Sniffer allows sniffing Batching datastore's events, effectively allowing sniffing of the IPFS DHT.
-// To effectively use the Sniffer, the proxied datastore needs to be acquired by calling `Batching()` on the Sniffer.
+// To effectively use the Sniffer, the proxied datastore needs to be acquired by calling `Batching()` on the Sniffer,
+// or, with the "providerstore" event source, the DHT's ProviderStore needs to be wrapped with `ProviderStore()`.
 type Sniffer struct {
-	cfg *Config
-	es  eventsource.EventSource
-	pub queue.PublisherFactory
+	// Accessed atomically; first for alignment.
+	restarts uint64
+	dropped  uint64
+
+	cfg      *Config
+	ds       datastore.Batching
+	es       eventsource.EventSource
+	pub      queue.PublisherFactory
+	lastSeen filters.LastSeenStore
+	filters  filters.Filter
+	mux      *http.ServeMux
+	breaker  *breaker.Breaker
+	spool    *spool.Spool       // Optional
+	recorder *recorder.Recorder // Optional
+
+	droppedCounter metric.Int64Counter
 
 	*instr.Instrumentation
 }
 
 // New creates a new Sniffer based on a datastore, or returns an error.
 func New(cfg *Config, ds datastore.Batching, pub queue.PublisherFactory, i *instr.Instrumentation) (*Sniffer, error) {
+	if cfg.EventSource != DatastoreSource && cfg.EventSource != ProviderStoreSource {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEventSource, cfg.EventSource)
+	}
+
 	bus := eventbus.NewBus()
 
 	es, err := eventsource.New(bus, ds)
@@ -48,28 +83,96 @@ func New(cfg *Config, ds datastore.Batching, pub queue.PublisherFactory, i *inst
 		return nil, fmt.Errorf("failed to get eventsource: %w", err)
 	}
 
+	lastSeen, err := newLastSeenStore(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last-seen store: %w", err)
+	}
+
+	mux := http.NewServeMux()
+
+	deps := filters.Deps{
+		LastSeen:           lastSeen,
+		LastSeenExpiration: cfg.LastSeenExpiration,
+		Mux:                mux,
+	}
+
+	f, err := filters.New(cfg.Filters, &deps)
+	if err != nil {
+		closeLastSeen(lastSeen)
+		return nil, fmt.Errorf("failed to create filters: %w", err)
+	}
+
+	var sp *spool.Spool
+	if cfg.SpoolDir != "" {
+		sp, err = spool.Open(cfg.SpoolDir, int64(cfg.SpoolMaxSize), int64(cfg.SpoolSegmentSize))
+		if err != nil {
+			closeLastSeen(lastSeen)
+			return nil, fmt.Errorf("failed to open spool: %w", err)
+		}
+	}
+
 	s := Sniffer{
 		cfg:             cfg,
+		ds:              ds,
 		es:              es,
 		pub:             pub,
+		lastSeen:        lastSeen,
+		filters:         f,
+		mux:             mux,
+		breaker:         breaker.New(breaker.NewBackoff(cfg.RestartBackoff, cfg.MaxRestartBackoff)),
+		spool:           sp,
+		droppedCounter:  metric.Must(i.Meter).NewInt64Counter("sniffer.dropped"),
 		Instrumentation: i,
 	}
 
+	mux.HandleFunc(HealthDebugPath, s.serveHealth)
+
 	return &s, nil
 }
 
-// Batching returns the datastore wrapped with sniffing hooks.
+// Batching returns the datastore wrapped with sniffing hooks, or the original datastore when not sniffing datastore
+// Put operations.
 func (s *Sniffer) Batching() datastore.Batching {
+	if s.cfg.EventSource != DatastoreSource {
+		return s.ds
+	}
+
 	return s.es.Batching()
 }
 
+// ProviderStore returns ps wrapped with sniffing hooks, to be used by the DHT with dht.ProviderStore(), or ps itself
+// when not sniffing the ProviderStore.
+func (s *Sniffer) ProviderStore(ps providers.ProviderStore) providers.ProviderStore {
+	if s.cfg.EventSource != ProviderStoreSource {
+		return ps
+	}
+
+	return s.es.ProviderStore(ps)
+}
+
+// Record records sniffed events with r until it is called with nil. It should not be called while sniffing.
+func (s *Sniffer) Record(r *recorder.Recorder) {
+	s.recorder = r
+}
+
 func (s *Sniffer) subscribe(ctx context.Context, c chan<- t.Provider) error {
 	// ctx, span := s.Tracer.Start(ctx, "sniffer.subscribe")
 	// defer span.End()
 
 	h := handler.New(c)
 
-	err := s.es.Subscribe(ctx, h.HandleFunc)
+	handle := h.HandleFunc
+	if s.recorder != nil {
+		handle = func(ctx context.Context, e eventsource.EvtProviderPut) error {
+			if err := s.recorder.Record(e, time.Now()); err != nil {
+				log.Printf("Error recording %v: %s", e, err)
+			}
+
+			return h.HandleFunc(ctx, e)
+		}
+	}
+
+	err := s.es.Subscribe(ctx, handle)
 	// span.RecordError(ctx, err)
 	// span.SetStatus(codes.Internal, err.Error())
 	return err
@@ -79,10 +182,7 @@ func (s *Sniffer) filter(ctx context.Context, in <-chan t.Provider, out chan<- t
 	// ctx, span := s.Tracer.Start(ctx, "sniffer.filter")
 	// defer span.End()
 
-	lastSeenFilter := filters.NewLastSeenFilter(s.cfg.LastSeenExpiration, s.cfg.LastSeenPruneLen)
-	cidFilter := filters.NewCidFilter()
-	mutliFilter := filters.NewMultiFilter(lastSeenFilter, cidFilter)
-	f := filter.New(mutliFilter, in, out)
+	f := filter.New(s.filters, in, out, s.cfg.FilterWorkers)
 
 	err := f.Filter(ctx)
 	// span.RecordError(ctx, err)
@@ -90,21 +190,150 @@ func (s *Sniffer) filter(ctx context.Context, in <-chan t.Provider, out chan<- t
 	return err
 }
 
-func (s *Sniffer) queue(ctx context.Context, c <-chan t.Provider) error {
-	// ctx, span := s.Tracer.Start(ctx, "sniffer.Queue")
-	// defer span.End()
+// breakerPublisher records the outcome of publishing with a Breaker.
+type breakerPublisher struct {
+	queue.Publisher
+	b *breaker.Breaker
+}
+
+func (p *breakerPublisher) Publish(ctx context.Context, msg interface{}, priority uint8) error {
+	if err := p.Publisher.Publish(ctx, msg, priority); err != nil {
+		return err
+	}
+
+	p.b.Success()
+
+	return nil
+}
+
+// publish publishes providers from c until publishing fails.
+func (s *Sniffer) publish(ctx context.Context, c <-chan t.Provider) error {
+	// Closes the publisher's connection on failure.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
 	publisher, err := s.pub.NewPublisher(ctx)
 	if err != nil {
 		return err
 	}
 
-	q := queuer.New(publisher, c)
+	q := queuer.New(&breakerPublisher{publisher, s.breaker}, c)
 
-	err = q.Queue(ctx)
-	// span.RecordError(ctx, err)
-	// span.SetStatus(codes.Internal, err.Error())
-	return err
+	if s.spool != nil {
+		if err := s.replay(ctx, &q, c); err != nil {
+			return err
+		}
+	}
+
+	return q.Queue(ctx)
+}
+
+// replay publishes spooled providers in order until the spool is empty.
+func (s *Sniffer) replay(ctx context.Context, q *queuer.Queuer, c <-chan t.Provider) error {
+	for {
+		// Spool providers arriving meanwhile, so that they are published after spooled providers.
+		for spooled := true; spooled; {
+			select {
+			case p, ok := <-c:
+				if !ok {
+					spooled = false
+					break
+				}
+
+				s.spoolOrDrop(ctx, p)
+			default:
+				spooled = false
+			}
+		}
+
+		p, err := s.spool.Peek()
+		if errors.Is(err, spool.ErrEmpty) {
+			return nil
+		}
+
+		if err != nil {
+			log.Printf("Error reading spool: %s", err)
+			continue
+		}
+
+		if err := q.Publish(ctx, p); err != nil {
+			return err
+		}
+
+		// ErrEmpty signifies the provider has been evicted after publishing.
+		if err := s.spool.Pop(); err != nil && !errors.Is(err, spool.ErrEmpty) {
+			return err
+		}
+	}
+}
+
+// spoolOrDrop spools a provider or, without spool or when spooling fails, drops it.
+func (s *Sniffer) spoolOrDrop(ctx context.Context, p t.Provider) {
+	if s.spool != nil {
+		err := s.spool.Write(p)
+		if err == nil {
+			return
+		}
+
+		log.Printf("Error spooling %v: %s", p, err)
+	}
+
+	atomic.AddUint64(&s.dropped, 1)
+	s.droppedCounter.Add(ctx, 1)
+}
+
+// hold spools or drops providers from c for duration d.
+func (s *Sniffer) hold(ctx context.Context, c <-chan t.Provider, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			return nil
+		case p, ok := <-c:
+			if !ok {
+				// Wait for the timer only.
+				c = nil
+				continue
+			}
+
+			s.spoolOrDrop(ctx, p)
+		}
+	}
+}
+
+// queue publishes providers from c until it is closed. When publishing fails, providers are spooled, or dropped
+// without spool, for an increasing backoff period after which publishing is retried, so that sniffing continues while
+// the queue is unavailable.
+func (s *Sniffer) queue(ctx context.Context, c <-chan t.Provider) error {
+	// ctx, span := s.Tracer.Start(ctx, "sniffer.Queue")
+	// defer span.End()
+
+	for {
+		err := s.publish(ctx, c)
+
+		// Closing the context should cause a return, other errors open the breaker
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		// All providers have been published
+		if err == nil {
+			return nil
+		}
+
+		wait := s.breaker.Failure(err)
+		log.Printf("Publishing failed with error '%s', retrying in %s", err, wait)
+
+		if err := s.hold(ctx, c, wait); err != nil {
+			return err
+		}
+
+		s.breaker.Retry()
+	}
 }
 
 func (s *Sniffer) iterate(ctx context.Context, sniffed, filtered chan t.Provider) error {
@@ -126,15 +355,105 @@ func (s *Sniffer) iterate(ctx context.Context, sniffed, filtered chan t.Provider
 	return err
 }
 
+// Replay feeds the events recorded in r through the filter and queuer pipeline, at speed times the recorded rate or
+// as fast as possible with a speed of 0. It returns once all events have been published or dropped.
+func (s *Sniffer) Replay(ctx context.Context, r *recorder.Reader, speed float64) error {
+	// ctx, span := s.Tracer.Start(ctx, "sniffer.Replay")
+	// defer span.End()
+
+	defer s.close()
+
+	replayed := make(chan t.Provider, s.cfg.BufferSize)
+	filtered := make(chan t.Provider, s.cfg.BufferSize)
+
+	// Recorded intervals are kept, relative to the start of the replay, regardless of speed.
+	started := time.Now()
+	var first time.Time
+
+	errg, ctx := errgroup.WithContext(ctx)
+	errg.Go(func() error {
+		defer close(replayed)
+
+		return recorder.Replay(ctx, r, speed, func(ctx context.Context, rec recorder.Record) error {
+			if first.IsZero() {
+				first = rec.Time
+			}
+
+			p := t.Provider{
+				Resource: &t.Resource{
+					Protocol: t.IPFSProtocol,
+					ID:       rec.CID,
+				},
+				Date:     started.Add(rec.Time.Sub(first)),
+				Provider: rec.PeerID,
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case replayed <- p:
+				return nil
+			}
+		})
+	})
+	errg.Go(func() error {
+		defer close(filtered)
+
+		return s.filter(ctx, replayed, filtered)
+	})
+	errg.Go(func() error { return s.queue(ctx, filtered) })
+
+	return errg.Wait()
+}
+
+// serveDebug serves debug information on the configured address until the context is closed.
+func (s *Sniffer) serveDebug(ctx context.Context) {
+	srv := &http.Server{
+		Addr:    s.cfg.DebugAddress,
+		Handler: s.mux,
+	}
+
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+
+	log.Printf("Serving sniffer debug information on http://%s", s.cfg.DebugAddress)
+
+	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+		log.Printf("Error serving debug information: %s", err)
+	}
+}
+
+// close releases the resources held by the Sniffer.
+func (s *Sniffer) close() {
+	closeLastSeen(s.lastSeen)
+
+	if s.spool != nil {
+		if err := s.spool.Close(); err != nil {
+			log.Printf("Error closing spool: %s", err)
+		}
+	}
+}
+
 // Sniff starts sniffing until the context is closed - it restarts itself on intermittant errors.
 func (s *Sniffer) Sniff(ctx context.Context) error {
 	// ctx, span := s.Tracer.Start(ctx, "sniffer.Sniff")
 	// defer span.End()
 
+	defer s.close()
+
+	if s.cfg.DebugAddress != "" {
+		go s.serveDebug(ctx)
+	}
+
 	sniffed := make(chan t.Provider, s.cfg.BufferSize)
 	filtered := make(chan t.Provider, s.cfg.BufferSize)
 
+	backoff := breaker.NewBackoff(s.cfg.RestartBackoff, s.cfg.MaxRestartBackoff)
+
 	for {
+		started := time.Now()
 		err := s.iterate(ctx, sniffed, filtered)
 
 		// Closing the parent context should cause a return, other errors cause a restart
@@ -145,10 +464,19 @@ func (s *Sniffer) Sniff(ctx context.Context) error {
 			return err
 		}
 
-		log.Printf("Wait group exited with error '%s', restarting", err)
+		// Only back off further when failing repeatedly
+		if time.Since(started) > s.cfg.MaxRestartBackoff {
+			backoff.Reset()
+		}
+
+		wait := backoff.Next()
+		atomic.AddUint64(&s.restarts, 1)
+		log.Printf("Wait group exited with error '%s', restarting in %s", err, wait)
 
-		// TODO: Add circuit breaker here
-		log.Printf("Stubbornly restarting in 1s")
-		time.Sleep(time.Second)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
 	}
 }
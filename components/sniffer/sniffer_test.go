@@ -3,13 +3,19 @@ package sniffer
 import (
 	"context"
 	"encoding/binary"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/ipfs/go-cid"
 	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
 	"github.com/libp2p/go-libp2p-core/peer"
 	"github.com/libp2p/go-libp2p-kad-dht/providers"
 	"github.com/multiformats/go-base32"
@@ -17,6 +23,10 @@ import (
 	"github.com/stretchr/testify/suite"
 
 	"github.com/ipfs-search/ipfs-search/components/queue"
+	"github.com/ipfs-search/ipfs-search/components/sniffer/breaker"
+	"github.com/ipfs-search/ipfs-search/components/sniffer/eventsource"
+	filters "github.com/ipfs-search/ipfs-search/components/sniffer/providerfilters"
+	"github.com/ipfs-search/ipfs-search/components/sniffer/recorder"
 	"github.com/ipfs-search/ipfs-search/instr"
 	t "github.com/ipfs-search/ipfs-search/types"
 )
@@ -27,6 +37,7 @@ type SnifferTestSuite struct {
 	cancel func()
 	f      *queue.MockFactory
 	ds     datastore.Batching
+	dir    string
 }
 
 func (s *SnifferTestSuite) SetupTest() {
@@ -34,25 +45,119 @@ func (s *SnifferTestSuite) SetupTest() {
 	s.f = &queue.MockFactory{}
 	s.f.Test(s.T())
 	s.ds = datastore.NewMapDatastore()
+
+	var err error
+	s.dir, err = ioutil.TempDir("", "sniffer")
+	s.Require().NoError(err)
 }
 
 func (s *SnifferTestSuite) TearDownTest() {
 	s.cancel()
 	s.ds.Close()
+	os.RemoveAll(s.dir)
+}
+
+// config returns the default configuration, spooling to a temporary directory.
+func (s *SnifferTestSuite) config() *Config {
+	cfg := DefaultConfig()
+	cfg.SpoolDir = s.dir
+
+	return cfg
 }
 
 // TestNew does a burn test for New()
 func (s *SnifferTestSuite) TestNew() {
-	cfg := DefaultConfig()
+	cfg := s.config()
 	sniffy, e := New(cfg, s.ds, s.f, instr.New())
 
 	s.NotEmpty(sniffy)
 	s.NoError(e)
 }
 
+// TestNewUnknownBackend tests whether New() fails for an unknown last-seen backend.
+func (s *SnifferTestSuite) TestNewUnknownBackend() {
+	cfg := s.config()
+	cfg.LastSeenBackend = "unknown"
+
+	_, e := New(cfg, s.ds, s.f, instr.New())
+
+	s.True(errors.Is(e, ErrUnknownBackend))
+}
+
+// TestNewUnknownEventSource tests whether New() fails for an unknown event source.
+func (s *SnifferTestSuite) TestNewUnknownEventSource() {
+	cfg := s.config()
+	cfg.EventSource = "unknown"
+
+	_, e := New(cfg, s.ds, s.f, instr.New())
+
+	s.True(errors.Is(e, ErrUnknownEventSource))
+}
+
+// TestEventSource tests whether only the configured event source is wrapped.
+func (s *SnifferTestSuite) TestEventSource() {
+	cfg := s.config()
+
+	sniffy, e := New(cfg, s.ds, s.f, instr.New())
+	s.NoError(e)
+
+	s.NotEqual(s.ds, sniffy.Batching())
+	s.Nil(sniffy.ProviderStore(nil))
+
+	cfg.EventSource = ProviderStoreSource
+
+	sniffy, e = New(cfg, s.ds, s.f, instr.New())
+	s.NoError(e)
+
+	s.Equal(s.ds, sniffy.Batching())
+	s.NotNil(sniffy.ProviderStore(nil))
+}
+
+// TestNewUnknownFilter tests whether New() fails for an unknown filter.
+func (s *SnifferTestSuite) TestNewUnknownFilter() {
+	cfg := s.config()
+	cfg.Filters = append(cfg.Filters, filters.Spec{Type: "unknown"})
+
+	_, e := New(cfg, s.ds, s.f, instr.New())
+
+	s.True(errors.Is(e, filters.ErrUnknownFilter))
+}
+
+// TestNewBadgerBackend tests whether the last-seen is persisted in a dedicated store.
+func (s *SnifferTestSuite) TestNewBadgerBackend() {
+	cfg := s.config()
+	cfg.SpoolDir = filepath.Join(s.dir, "spool")
+	cfg.LastSeenBackend = BadgerBackend
+	cfg.LastSeenPath = filepath.Join(s.dir, "lastseen")
+
+	sniffy, e := New(cfg, s.ds, s.f, instr.New())
+	s.NoError(e)
+
+	p := t.MockProvider()
+	s.NoError(sniffy.lastSeen.Set(*p.Resource, p.Date))
+
+	s.NoError(sniffy.lastSeen.(io.Closer).Close())
+
+	store, err := filters.NewBadgerStore(cfg.LastSeenPath, cfg.LastSeenExpiration)
+	s.Require().NoError(err)
+	defer store.Close()
+
+	_, present, err := store.Get(*p.Resource)
+	s.NoError(err)
+	s.True(present)
+
+	// Nothing is stored in the sniffed datastore.
+	results, err := s.ds.Query(query.Query{KeysOnly: true})
+	s.Require().NoError(err)
+
+	entries, err := results.Rest()
+	s.NoError(err)
+	s.Empty(entries)
+}
+
 // TestSniffCancel tests whether running Sniff() with a cancelled context returns with a context error.
 func (s *SnifferTestSuite) TestSniffCancel() {
-	cfg := DefaultConfig()
+	cfg := s.config()
 	sniffy, e := New(cfg, s.ds, s.f, instr.New())
 	s.NoError(e)
 
@@ -69,6 +174,132 @@ func (s *SnifferTestSuite) TestSniffCancel() {
 	s.f.AssertExpectations(s.T())
 }
 
+// TestQueueBreaker tests whether providers are dropped after publishing fails, until publishing is retried.
+func (s *SnifferTestSuite) TestQueueBreaker() {
+	cfg := s.config()
+	cfg.RestartBackoff = 200 * time.Millisecond
+	cfg.SpoolDir = ""
+
+	sniffy, e := New(cfg, s.ds, s.f, instr.New())
+	s.NoError(e)
+
+	s.Equal(breaker.HalfOpen, sniffy.Health().Publishing.State)
+
+	qMock := &queue.Mock{}
+	qMock.On("Publish", mock.Anything, mock.Anything, uint8(9)).Return(errors.New("mock")).Once()
+	qMock.On("Publish", mock.Anything, mock.Anything, uint8(9)).Return(nil)
+
+	s.f.On("NewPublisher", mock.AnythingOfType("*context.cancelCtx")).Return(qMock, nil)
+
+	c := make(chan t.Provider)
+	go sniffy.queue(s.ctx, c)
+
+	// Publishing fails, opening the breaker
+	c <- t.MockProvider()
+	s.Eventually(func() bool {
+		return sniffy.Health().Publishing.State == breaker.Open
+	}, time.Second, time.Millisecond)
+
+	// Dropped while open
+	c <- t.MockProvider()
+	s.Eventually(func() bool {
+		return sniffy.Health().Dropped == 1
+	}, time.Second, time.Millisecond)
+	s.False(sniffy.Health().Healthy())
+
+	// Published after retrying
+	s.Eventually(func() bool {
+		return sniffy.Health().Publishing.State == breaker.HalfOpen
+	}, time.Second, time.Millisecond)
+
+	c <- t.MockProvider()
+	s.Eventually(func() bool {
+		return sniffy.Health().Healthy()
+	}, time.Second, time.Millisecond)
+
+	s.f.AssertNumberOfCalls(s.T(), "NewPublisher", 2)
+	qMock.AssertNumberOfCalls(s.T(), "Publish", 2)
+}
+
+// TestQueueSpool tests whether providers are spooled while publishing fails and published in order afterwards.
+func (s *SnifferTestSuite) TestQueueSpool() {
+	cfg := s.config()
+	cfg.RestartBackoff = 200 * time.Millisecond
+
+	sniffy, e := New(cfg, s.ds, s.f, instr.New())
+	s.NoError(e)
+
+	first, second, third := t.MockProvider(), t.MockProvider(), t.MockProvider()
+	first.ID, second.ID, third.ID = "QmFirst", "QmSecond", "QmThird"
+
+	published := make(chan string, 3)
+
+	qMock := &queue.Mock{}
+	qMock.On("Publish", mock.Anything, mock.Anything, uint8(9)).Return(errors.New("mock")).Once()
+	qMock.On("Publish", mock.Anything, mock.Anything, uint8(9)).Return(nil).Run(func(args mock.Arguments) {
+		published <- args.Get(1).(*t.AnnotatedResource).ID
+	})
+
+	s.f.On("NewPublisher", mock.AnythingOfType("*context.cancelCtx")).Return(qMock, nil)
+
+	// Pending providers are spooled before publishing starts
+	c := make(chan t.Provider, 1)
+	c <- first
+	go sniffy.queue(s.ctx, c)
+
+	// Publishing fails, opening the breaker and keeping the provider spooled
+	s.Eventually(func() bool {
+		h := sniffy.Health()
+		return h.Publishing.State == breaker.Open && h.Spooled == 1
+	}, time.Second, time.Millisecond)
+
+	// Spooled while open
+	c <- second
+	s.Eventually(func() bool {
+		return sniffy.Health().Spooled == 2
+	}, time.Second, time.Millisecond)
+
+	// Spooled providers are published before new providers
+	c <- third
+	s.Equal("QmFirst", <-published)
+	s.Equal("QmSecond", <-published)
+	s.Equal("QmThird", <-published)
+
+	s.Eventually(func() bool {
+		return sniffy.Health().Spooled == 0
+	}, time.Second, time.Millisecond)
+	s.Equal(uint64(0), sniffy.Health().Dropped)
+}
+
+// TestReplay tests whether recorded events are published by Replay().
+func (s *SnifferTestSuite) TestReplay() {
+	cfg := s.config()
+
+	sniffy, e := New(cfg, s.ds, s.f, instr.New())
+	s.NoError(e)
+
+	path := filepath.Join(s.dir, "recording.gz")
+	rec, err := recorder.Create(path)
+	s.Require().NoError(err)
+
+	c, _ := cid.Decode("QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp")
+	pid, _ := peer.Decode("QmeTtFXm42Jb2todcKR538j6qHYxXt6suUzpF3rtT9FPSd")
+	s.NoError(rec.Record(eventsource.EvtProviderPut{CID: c, PeerID: pid}, time.Now()))
+	s.NoError(rec.Close())
+
+	qMock := &queue.Mock{}
+	qMock.On("Publish", mock.Anything, mock.Anything, uint8(9)).Return(nil)
+	s.f.On("NewPublisher", mock.AnythingOfType("*context.cancelCtx")).Return(qMock, nil)
+
+	r, err := recorder.Open(path)
+	s.Require().NoError(err)
+	defer r.Close()
+
+	s.NoError(sniffy.Replay(s.ctx, r, 0))
+
+	qMock.AssertNumberOfCalls(s.T(), "Publish", 1)
+}
+
 func timeToVal(t time.Time) []byte {
 	// Ref: https://github.com/libp2p/go-libp2p-kad-dht/blob/master/providers/providers_manager.go#L239
 
@@ -119,7 +350,7 @@ func (s *SnifferTestSuite) TestHandleToPublish() {
 	value := timeToVal(now)
 
 	// Create sniffer
-	cfg := DefaultConfig()
+	cfg := s.config()
 	sniffy, e := New(cfg, s.ds, s.f, instr.New())
 	s.NoError(e)
 
@@ -183,7 +414,7 @@ func (s *SnifferTestSuite) TestHandleToPublish() {
 // 	}
 
 // 	// Create sniffer
-// 	cfg := DefaultConfig()
+// 	cfg := s.config()
 // 	s, e := New(cfg)
 // 	s.NotEmpty(s)
 // 	s.Empty(e)
@@ -0,0 +1,427 @@
+/*
+Package spool provides an on-disk FIFO queue of Providers, buffering them while they cannot be published.
+
+Providers are appended to segment files in a directory. Segments are removed once all their Providers have been
+read, or when the spool exceeds its maximum size, oldest first. Providers are read at least once: after a restart,
+the Providers of a partially read segment are read again.
+*/
+package spool
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/api/metric"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+const segmentExt = ".spool"
+
+// ErrEmpty is returned when reading from an empty Spool.
+var ErrEmpty = errors.New("spool empty")
+
+type record struct {
+	Protocol t.Protocol `json:"protocol"`
+	ID       string     `json:"id"`
+	Provider string     `json:"provider"`
+	Date     time.Time  `json:"date"`
+}
+
+type segment struct {
+	seq     uint64
+	size    int64
+	records int
+}
+
+// Spool is an on-disk FIFO queue of Providers. It is safe for concurrent use.
+type Spool struct {
+	mu          sync.Mutex
+	dir         string
+	maxSize     int64
+	segmentSize int64
+
+	segments []*segment // Oldest first; the last segment is written to.
+	size     int64
+	len      int
+
+	w *os.File
+	r *bufio.Reader
+	f *os.File // File being read, the first segment.
+
+	peeked *t.Provider
+
+	evicted        uint64
+	evictedCounter metric.Int64Counter
+}
+
+// Open opens or creates a Spool in dir, holding at most maxSize bytes in segments of segmentSize bytes.
+func Open(dir string, maxSize, segmentSize int64) (*Spool, error) {
+	if segmentSize <= 0 || segmentSize > maxSize/2 {
+		return nil, fmt.Errorf("segment size %d should be positive and at most half of max size %d", segmentSize, maxSize)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	s := &Spool{
+		dir:            dir,
+		maxSize:        maxSize,
+		segmentSize:    segmentSize,
+		evictedCounter: metric.Must(instr.New().Meter).NewInt64Counter("sniffer.spool.evicted"),
+	}
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	var seq uint64
+	if n := len(s.segments); n > 0 {
+		seq = s.segments[n-1].seq + 1
+	}
+
+	if err := s.create(seq); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *Spool) path(seq uint64) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%016d%s", seq, segmentExt))
+}
+
+// load reads existing segments, counting their records and truncating incomplete records.
+func (s *Spool) load() error {
+	files, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+
+	var seqs []uint64
+	for _, f := range files {
+		name := f.Name()
+		if !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		seqs = append(seqs, seq)
+	}
+
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+
+	for _, seq := range seqs {
+		seg, err := s.scan(seq)
+		if err != nil {
+			return err
+		}
+
+		if seg.records == 0 {
+			if err := os.Remove(s.path(seq)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		s.segments = append(s.segments, seg)
+		s.size += seg.size
+		s.len += seg.records
+	}
+
+	return nil
+}
+
+// scan counts the records in a segment, truncating it after the last complete record.
+func (s *Spool) scan(seq uint64) (*segment, error) {
+	f, err := os.OpenFile(s.path(seq), os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	seg := &segment{seq: seq}
+	r := bufio.NewReader(f)
+
+	for {
+		n, err := skipRecord(r)
+		if err != nil {
+			break
+		}
+
+		seg.size += n
+		seg.records++
+	}
+
+	// Remove partially written records.
+	if err := f.Truncate(seg.size); err != nil {
+		return nil, err
+	}
+
+	return seg, nil
+}
+
+// create starts writing to a new segment.
+func (s *Spool) create(seq uint64) error {
+	f, err := os.OpenFile(s.path(seq), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	if s.w != nil {
+		s.w.Close()
+	}
+
+	s.w = f
+	s.segments = append(s.segments, &segment{seq: seq})
+
+	return nil
+}
+
+func skipRecord(r *bufio.Reader) (int64, error) {
+	var l uint32
+	if err := binary.Read(r, binary.BigEndian, &l); err != nil {
+		return 0, err
+	}
+
+	if _, err := io.CopyN(ioutil.Discard, r, int64(l)); err != nil {
+		return 0, io.ErrUnexpectedEOF
+	}
+
+	return int64(4 + l), nil
+}
+
+func readRecord(r *bufio.Reader) (*t.Provider, error) {
+	var l uint32
+	if err := binary.Read(r, binary.BigEndian, &l); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, l)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+
+	var rec record
+	if err := json.Unmarshal(buf, &rec); err != nil {
+		return nil, err
+	}
+
+	return &t.Provider{
+		Resource: &t.Resource{
+			Protocol: rec.Protocol,
+			ID:       rec.ID,
+		},
+		Provider: rec.Provider,
+		Date:     rec.Date,
+	}, nil
+}
+
+// Write appends a Provider to the Spool, evicting the oldest segments when it grows beyond its maximum size.
+func (s *Spool) Write(p t.Provider) error {
+	buf, err := json.Marshal(record{
+		Protocol: p.Protocol,
+		ID:       p.ID,
+		Provider: p.Provider,
+		Date:     p.Date,
+	})
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	head := s.segments[len(s.segments)-1]
+	if head.size > 0 && head.size+int64(4+len(buf)) > s.segmentSize {
+		if err := s.create(head.seq + 1); err != nil {
+			return err
+		}
+		head = s.segments[len(s.segments)-1]
+
+		if err := s.removeRead(); err != nil {
+			return err
+		}
+	}
+
+	// Write length and record at once, so that records are written completely or truncated when loading.
+	rec := make([]byte, 4+len(buf))
+	binary.BigEndian.PutUint32(rec, uint32(len(buf)))
+	copy(rec[4:], buf)
+
+	if _, err := s.w.Write(rec); err != nil {
+		return err
+	}
+
+	head.size += int64(len(rec))
+	head.records++
+	s.size += int64(len(rec))
+	s.len++
+
+	for s.size > s.maxSize && len(s.segments) > 1 {
+		if err := s.evict(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// removeFirst removes the oldest segment.
+func (s *Spool) removeFirst() error {
+	seg := s.segments[0]
+
+	if s.f != nil {
+		s.f.Close()
+		s.f, s.r = nil, nil
+	}
+
+	s.segments = s.segments[1:]
+	s.size -= seg.size
+	s.len -= seg.records
+
+	return os.Remove(s.path(seg.seq))
+}
+
+// removeRead removes the oldest segments which have been read completely, unless written to.
+func (s *Spool) removeRead() error {
+	for len(s.segments) > 1 && s.segments[0].records == 0 {
+		if err := s.removeFirst(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// evict removes the oldest segment, including unread Providers.
+// When it is the only segment, a new segment is written to.
+func (s *Spool) evict() error {
+	seg := s.segments[0]
+	cnt := seg.records
+
+	s.peeked = nil
+
+	if len(s.segments) == 1 {
+		if err := s.create(seg.seq + 1); err != nil {
+			return err
+		}
+	}
+
+	if err := s.removeFirst(); err != nil {
+		return err
+	}
+
+	atomic.AddUint64(&s.evicted, uint64(cnt))
+	s.evictedCounter.Add(context.Background(), int64(cnt))
+
+	return nil
+}
+
+// Peek returns the oldest Provider in the Spool without removing it, or ErrEmpty.
+func (s *Spool) Peek() (t.Provider, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.peeked != nil {
+		return *s.peeked, nil
+	}
+
+	if s.len == 0 {
+		return t.Provider{}, ErrEmpty
+	}
+
+	if s.f == nil {
+		f, err := os.Open(s.path(s.segments[0].seq))
+		if err != nil {
+			return t.Provider{}, err
+		}
+
+		s.f, s.r = f, bufio.NewReader(f)
+	}
+
+	p, err := readRecord(s.r)
+	if err != nil {
+		// Skip the corrupted segment.
+		seq := s.segments[0].seq
+		if evictErr := s.evict(); evictErr != nil {
+			return t.Provider{}, evictErr
+		}
+
+		return t.Provider{}, fmt.Errorf("reading spool segment %d: %w", seq, err)
+	}
+
+	s.peeked = p
+
+	return *p, nil
+}
+
+// Pop removes the Provider returned by the last call to Peek.
+func (s *Spool) Pop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.peeked == nil {
+		return ErrEmpty
+	}
+
+	s.peeked = nil
+
+	s.segments[0].records--
+	s.len--
+
+	return s.removeRead()
+}
+
+// Len returns the amount of Providers in the Spool.
+func (s *Spool) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.len
+}
+
+// Size returns the size of the Spool in bytes.
+func (s *Spool) Size() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.size
+}
+
+// Evicted returns the total amount of Providers evicted because the Spool was full.
+func (s *Spool) Evicted() uint64 {
+	return atomic.LoadUint64(&s.evicted)
+}
+
+// Close closes the Spool's files.
+func (s *Spool) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.f != nil {
+		s.f.Close()
+	}
+
+	return s.w.Close()
+}
@@ -0,0 +1,178 @@
+package spool
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+type SpoolTestSuite struct {
+	suite.Suite
+	dir string
+}
+
+func (s *SpoolTestSuite) SetupTest() {
+	var err error
+	s.dir, err = ioutil.TempDir("", "spool")
+	s.Require().NoError(err)
+}
+
+func (s *SpoolTestSuite) TearDownTest() {
+	os.RemoveAll(s.dir)
+}
+
+func makeProvider(i int) t.Provider {
+	return t.Provider{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       fmt.Sprintf("Qm%d", i),
+		},
+		Provider: "QmeTtFXm42Jb2todcKR538j6qHYxXt6suUzpF3rtT9FPSd",
+		Date:     time.Unix(int64(i), 0).UTC(),
+	}
+}
+
+func (s *SpoolTestSuite) segments() []string {
+	files, err := filepath.Glob(filepath.Join(s.dir, "*"+segmentExt))
+	s.Require().NoError(err)
+	return files
+}
+
+// read pops n providers, asserting their order.
+func (s *SpoolTestSuite) read(sp *Spool, from, n int) {
+	for i := from; i < from+n; i++ {
+		p, err := sp.Peek()
+		s.Require().NoError(err)
+		s.Equal(makeProvider(i), p)
+		s.NoError(sp.Pop())
+	}
+}
+
+// TestFIFO tests whether providers are read in the order written.
+func (s *SpoolTestSuite) TestFIFO() {
+	sp, err := Open(s.dir, 1<<20, 1<<10)
+	s.Require().NoError(err)
+	defer sp.Close()
+
+	_, err = sp.Peek()
+	s.Equal(ErrEmpty, err)
+
+	for i := 0; i < 100; i++ {
+		s.NoError(sp.Write(makeProvider(i)))
+	}
+	s.Equal(100, sp.Len())
+	s.True(len(s.segments()) > 1)
+
+	s.read(sp, 0, 50)
+
+	// Interleaved writing and reading
+	s.NoError(sp.Write(makeProvider(100)))
+	s.read(sp, 50, 51)
+
+	s.Equal(0, sp.Len())
+	_, err = sp.Peek()
+	s.Equal(ErrEmpty, err)
+
+	// Read segments are removed
+	s.Len(s.segments(), 1)
+}
+
+// TestReopen tests whether unread providers are read after reopening.
+func (s *SpoolTestSuite) TestReopen() {
+	sp, err := Open(s.dir, 1<<20, 1<<10)
+	s.Require().NoError(err)
+
+	for i := 0; i < 50; i++ {
+		s.NoError(sp.Write(makeProvider(i)))
+	}
+	s.NoError(sp.Close())
+
+	sp, err = Open(s.dir, 1<<20, 1<<10)
+	s.Require().NoError(err)
+	defer sp.Close()
+
+	s.Equal(50, sp.Len())
+	s.read(sp, 0, 50)
+}
+
+// TestTruncated tests whether a partially written record is discarded.
+func (s *SpoolTestSuite) TestTruncated() {
+	sp, err := Open(s.dir, 1<<20, 1<<10)
+	s.Require().NoError(err)
+
+	s.NoError(sp.Write(makeProvider(0)))
+	s.NoError(sp.Close())
+
+	f, err := os.OpenFile(s.segments()[0], os.O_APPEND|os.O_WRONLY, 0644)
+	s.Require().NoError(err)
+	f.Write([]byte{0, 0, 1, 0, '{'})
+	f.Close()
+
+	sp, err = Open(s.dir, 1<<20, 1<<10)
+	s.Require().NoError(err)
+	defer sp.Close()
+
+	s.Equal(1, sp.Len())
+	s.read(sp, 0, 1)
+}
+
+// TestCorrupt tests whether a corrupted segment is skipped.
+func (s *SpoolTestSuite) TestCorrupt() {
+	sp, err := Open(s.dir, 1<<20, 1<<10)
+	s.Require().NoError(err)
+	defer sp.Close()
+
+	for i := 0; i < 100; i++ {
+		s.NoError(sp.Write(makeProvider(i)))
+	}
+
+	// Overwrite first record of first segment
+	f, err := os.OpenFile(s.segments()[0], os.O_WRONLY, 0644)
+	s.Require().NoError(err)
+	f.WriteAt([]byte("garbage"), 4)
+	f.Close()
+
+	_, err = sp.Peek()
+	s.Error(err)
+
+	// Continues with the next segment
+	p, err := sp.Peek()
+	s.NoError(err)
+	s.Equal(100-sp.Len(), int(sp.Evicted()))
+	s.Equal(makeProvider(100-sp.Len()), p)
+}
+
+// TestEvict tests whether the oldest providers are evicted when the spool is full.
+func (s *SpoolTestSuite) TestEvict() {
+	sp, err := Open(s.dir, 4<<10, 1<<10)
+	s.Require().NoError(err)
+	defer sp.Close()
+
+	const cnt = 1000
+	for i := 0; i < cnt; i++ {
+		s.NoError(sp.Write(makeProvider(i)))
+	}
+
+	s.True(sp.Size() <= 4<<10)
+	s.Equal(uint64(cnt-sp.Len()), sp.Evicted())
+
+	// The newest providers are retained
+	s.read(sp, cnt-sp.Len(), sp.Len())
+}
+
+// TestInvalidSize tests whether Open fails for segments which are too large.
+func (s *SpoolTestSuite) TestInvalidSize() {
+	_, err := Open(s.dir, 1<<10, 1<<10)
+	s.Error(err)
+}
+
+func TestSpoolTestSuite(t *testing.T) {
+	suite.Run(t, new(SpoolTestSuite))
+}
@@ -2,9 +2,11 @@ package streamfilter
 
 import (
 	"context"
+	"errors"
 
 	"go.opentelemetry.io/otel/api/trace"
 	"go.opentelemetry.io/otel/label"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/ipfs-search/ipfs-search/instr"
 	t "github.com/ipfs-search/ipfs-search/types"
@@ -12,20 +14,28 @@ import (
 	filters "github.com/ipfs-search/ipfs-search/components/sniffer/providerfilters"
 )
 
-// Filter filters a stream of Providers through filters.Filter.
+var errClosed = errors.New("incoming channel closed")
+
+// Filter filters a stream of Providers through filters.Filter, using multiple concurrent workers.
 type Filter struct {
-	f   filters.Filter
-	in  <-chan t.Provider
-	out chan<- t.Provider
+	f       filters.Filter
+	in      <-chan t.Provider
+	out     chan<- t.Provider
+	workers uint
 	*instr.Instrumentation
 }
 
-// New creates a new Filter based on a Filter, an incoming and an outgoing channel.
-func New(f filters.Filter, in <-chan t.Provider, out chan<- t.Provider) Filter {
+// New creates a new Filter based on a Filter, an incoming and an outgoing channel and the amount of workers.
+func New(f filters.Filter, in <-chan t.Provider, out chan<- t.Provider, workers uint) Filter {
+	if workers == 0 {
+		panic("at least one worker is required")
+	}
+
 	return Filter{
 		f:               f,
 		in:              in,
 		out:             out,
+		workers:         workers,
 		Instrumentation: instr.New(),
 	}
 }
@@ -35,7 +45,11 @@ func (f *Filter) iterate(ctx context.Context) error {
 	case <-ctx.Done():
 		// Context closed, return context error
 		return ctx.Err()
-	case p := <-f.in:
+	case p, ok := <-f.in:
+		if !ok {
+			return errClosed
+		}
+
 		return func() error {
 			ctx = trace.ContextWithRemoteSpanContext(ctx, p.SpanContext)
 			ctx, span := f.Tracer.Start(ctx, "providerfilter.Filter", trace.WithAttributes(
@@ -67,11 +81,27 @@ func (f *Filter) iterate(ctx context.Context) error {
 	}
 }
 
-// Filter filters a stream of providers, dropping those for which filter returns false
-func (f *Filter) Filter(ctx context.Context) error {
+func (f *Filter) work(ctx context.Context) error {
 	for {
 		if err := f.iterate(ctx); err != nil {
+			if err == errClosed {
+				return nil
+			}
+
 			return err
 		}
 	}
 }
+
+// Filter filters a stream of providers, dropping those for which filter returns false.
+// Providers are filtered concurrently; their order is not preserved. It returns nil once the incoming channel is
+// closed and all providers have been filtered.
+func (f *Filter) Filter(ctx context.Context) error {
+	errg, ctx := errgroup.WithContext(ctx)
+
+	for i := uint(0); i < f.workers; i++ {
+		errg.Go(func() error { return f.work(ctx) })
+	}
+
+	return errg.Wait()
+}
@@ -1 +1,108 @@
 package streamfilter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	filters "github.com/ipfs-search/ipfs-search/components/sniffer/providerfilters"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+type StreamFilterTestSuite struct {
+	suite.Suite
+	ctx    context.Context
+	cancel func()
+	in     chan t.Provider
+	out    chan t.Provider
+}
+
+func (s *StreamFilterTestSuite) SetupTest() {
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.in = make(chan t.Provider)
+	s.out = make(chan t.Provider)
+}
+
+func (s *StreamFilterTestSuite) TearDownTest() {
+	s.cancel()
+}
+
+// TestFilterContextCancel tests whether we're returning an error on context cancellation.
+func (s *StreamFilterTestSuite) TestFilterContextCancel() {
+	f := New(&filters.MockFilter{R: true}, s.in, s.out, 4)
+
+	s.cancel()
+
+	err := f.Filter(s.ctx)
+	s.Equal(context.Canceled, err)
+}
+
+// TestFilterClosed tests whether we're returning without error when the incoming channel is closed.
+func (s *StreamFilterTestSuite) TestFilterClosed() {
+	f := New(&filters.MockFilter{R: true}, s.in, s.out, 4)
+
+	close(s.in)
+
+	err := f.Filter(s.ctx)
+	s.NoError(err)
+}
+
+// TestFilterInclude tests whether all included providers are passed on by parallel workers.
+func (s *StreamFilterTestSuite) TestFilterInclude() {
+	const cnt = 100
+
+	mf := &filters.MockFilter{R: true}
+	f := New(mf, s.in, s.out, 4)
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- f.Filter(s.ctx)
+	}()
+
+	go func() {
+		for i := 0; i < cnt; i++ {
+			s.in <- t.MockProvider()
+		}
+	}()
+
+	for i := 0; i < cnt; i++ {
+		select {
+		case <-s.out:
+		case <-time.After(time.Second):
+			s.FailNow("timeout waiting for filtered provider")
+		}
+	}
+
+	s.cancel()
+	s.Equal(context.Canceled, <-errc)
+	s.Equal(cnt, mf.Calls)
+}
+
+// TestFilterExclude tests whether excluded providers are dropped.
+func (s *StreamFilterTestSuite) TestFilterExclude() {
+	mf := &filters.MockFilter{R: false}
+	f := New(mf, s.in, s.out, 2)
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- f.Filter(s.ctx)
+	}()
+
+	s.in <- t.MockProvider()
+	s.in <- t.MockProvider()
+
+	select {
+	case p := <-s.out:
+		s.Failf("unexpected provider", "%v", p)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	s.cancel()
+	s.Equal(context.Canceled, <-errc)
+}
+
+func TestStreamFilterTestSuite(t *testing.T) {
+	suite.Run(t, new(StreamFilterTestSuite))
+}
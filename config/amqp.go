@@ -7,9 +7,13 @@ import (
 
 // AMQP contains configuration pertaining to AMQP.
 type AMQP struct {
-	URL           string        `yaml:"url" env:"AMQP_URL"` // URL of AMQP server.
-	MaxReconnect  int           `yaml:"max_reconnect"`      // The maximum number of reconnection attempts after the server connection is lost.
-	ReconnectTime time.Duration `yaml:"reconnect_time"`     // The time to wait in between reconnect attempts.
+	URL            string        `yaml:"url" env:"AMQP_URL"` // URL of AMQP server.
+	MaxReconnect   int           `yaml:"max_reconnect"`      // The maximum number of reconnection attempts after the server connection is lost.
+	ReconnectTime  time.Duration `yaml:"reconnect_time"`     // The time to wait in between reconnect attempts.
+	DeliveryMode   string        `yaml:"delivery_mode"`      // "transient" or "persistent", surviving broker restarts.
+	ConfirmTimeout time.Duration `yaml:"confirm_timeout"`    // The time to wait for the broker to confirm a published message.
+	MaxUnconfirmed int           `yaml:"max_unconfirmed"`    // The maximum of published messages awaiting confirmation per channel.
+	Encoding       string        `yaml:"encoding"`           // "json" or "cbor", more compact; consumers accept both.
 }
 
 // AMQPConfig returns component-specific configuration from the canonical configuration.
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"time"
+
+	"github.com/ipfs-search/ipfs-search/utils/limit"
+)
+
+// Backend holds the limits for requests to a single backend.
+type Backend struct {
+	MaxConcurrent int           `yaml:"max_concurrent"` // Maximum of concurrent requests, and of idle connections.
+	MaxWait       time.Duration `yaml:"max_wait"`       // Time to wait for a request to be allowed before failing it.
+	Rate          float64       `yaml:"rate"`           // Average amount of requests per second.
+	Burst         int           `yaml:"burst"`          // Maximum amount of requests in excess of rate.
+}
+
+// LimitConfig returns the limits for the backend.
+func (b Backend) LimitConfig() *limit.BackendConfig {
+	cfg := limit.BackendConfig(b)
+	return &cfg
+}
+
+// Backends holds the limits for requests from crawlers to the backends they share, per process.
+type Backends struct {
+	IPFSAPI       Backend `yaml:"ipfs_api"`      // Stat and Ls calls.
+	Tika          Backend `yaml:"tika"`          // Metadata extraction, including ipfs-tika's gateway fetches.
+	ElasticSearch Backend `yaml:"elasticsearch"` // Index reads and writes.
+}
+
+// BackendsDefaults returns the default limits, which are generous as to only cap runaway load.
+func BackendsDefaults() Backends {
+	return Backends{
+		IPFSAPI:       Backend(*limit.DefaultBackendConfig(1000, 5000)),
+		Tika:          Backend(*limit.DefaultBackendConfig(100, 500)),
+		ElasticSearch: Backend(*limit.DefaultBackendConfig(100, 2000)),
+	}
+}
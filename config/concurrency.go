@@ -0,0 +1,26 @@
+package config
+
+import (
+	"github.com/ipfs-search/ipfs-search/utils/limit"
+)
+
+// Concurrency contains configuration pertaining to the concurrency of worker pools, limited to the amount of workers.
+type Concurrency struct {
+	Controller     string  `yaml:"controller"`      // "adaptive" to adjust to latencies and errors, or "fixed".
+	MinLimit       int     `yaml:"min_limit"`       // Lowest amount of active workers per pool.
+	Tolerance      float64 `yaml:"tolerance"`       // Factor by which latency may exceed its baseline before backing off.
+	ErrorTolerance float64 `yaml:"error_tolerance"` // Fraction by which the error rate may exceed its baseline before backing off.
+	Backoff        float64 `yaml:"backoff"`         // Factor by which active workers are reduced when backing off.
+	Smoothing      float64 `yaml:"smoothing"`       // Weight of every window of samples in the baselines.
+}
+
+// ConcurrencyConfig returns component-specific configuration from the canonical configuration.
+func (c *Config) ConcurrencyConfig() *limit.Config {
+	cfg := limit.Config(c.Concurrency)
+	return &cfg
+}
+
+// ConcurrencyDefaults returns the defaults for component configuration, based on the component-specific configuration.
+func ConcurrencyDefaults() Concurrency {
+	return Concurrency(*limit.DefaultConfig())
+}
@@ -40,14 +40,21 @@ type Config struct {
 	IPFS          `yaml:"ipfs"`
 	ElasticSearch `yaml:"elasticsearch"`
 	AMQP          `yaml:"amqp"`
+	MemoryQueue   `yaml:"memory_queue"`
+	RedisQueue    `yaml:"redis_queue"`
+	Dedup         `yaml:"dedup"`
 	Tika          `yaml:"tika"`
 
-	Instr   `yaml:"instrumentation"`
-	Crawler `yaml:"crawler"`
-	Sniffer `yaml:"sniffer"`
-	Indexes `yaml:"indexes"`
-	Queues  `yaml:"queues"`
-	Workers `yaml:"workers"`
+	Instr       `yaml:"instrumentation"`
+	Crawler     `yaml:"crawler"`
+	Prober      `yaml:"prober"`
+	Sniffer     `yaml:"sniffer"`
+	SnifferNode `yaml:"sniffer_node"`
+	Indexes     `yaml:"indexes"`
+	Queues      `yaml:"queues"`
+	Workers     `yaml:"workers"`
+	Concurrency `yaml:"concurrency"`
+	Backends    `yaml:"backends"`
 }
 
 // String renders config as YAML
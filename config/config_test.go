@@ -0,0 +1,76 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ConfigTestSuite struct {
+	suite.Suite
+
+	dir string
+}
+
+func (s *ConfigTestSuite) SetupTest() {
+	var err error
+
+	s.dir, err = ioutil.TempDir("", "config")
+	s.Require().NoError(err)
+}
+
+func (s *ConfigTestSuite) TearDownTest() {
+	os.RemoveAll(s.dir)
+}
+
+// get returns the configuration read from a file with contents yml.
+func (s *ConfigTestSuite) get(yml string) *Config {
+	f, err := ioutil.TempFile(s.dir, "*.yml")
+	s.Require().NoError(err)
+	defer f.Close()
+
+	_, err = f.WriteString(yml)
+	s.Require().NoError(err)
+
+	cfg, err := Get(f.Name())
+	s.Require().NoError(err)
+
+	return cfg
+}
+
+func (s *ConfigTestSuite) TestDefault() {
+	s.NoError(Default().Check())
+}
+
+// TestDisabled tests whether optional features can be disabled with their zero value.
+func (s *ConfigTestSuite) TestDisabled() {
+	cfg := s.get(`
+sniffer:
+  spool_dir: ""
+  debug_address: ""
+redis_queue:
+  max_len: 0
+`)
+
+	s.NoError(cfg.Check())
+
+	s.Equal("", cfg.SnifferConfig().SpoolDir)
+	s.Equal("", cfg.SnifferConfig().DebugAddress)
+	s.Equal(0, cfg.RedisQueueConfig().MaxLen)
+}
+
+// TestMissing tests whether required options can not be left empty.
+func (s *ConfigTestSuite) TestMissing() {
+	cfg := s.get(`
+elasticsearch:
+  url: ""
+`)
+
+	s.Error(cfg.Check())
+}
+
+func TestConfigTestSuite(t *testing.T) {
+	suite.Run(t, new(ConfigTestSuite))
+}
@@ -12,6 +12,8 @@ type Crawler struct {
 	StatTimeout        time.Duration `yaml:"stat_timeout"`         // Timeout for Stat() calls.
 	DirEntryTimeout    time.Duration `yaml:"direntry_timeout"`     // Timeout *between* directory entries.
 	MaxDirSize         uint          `yaml:"max_dirsize"`          // Maximum number of directory entries
+	CursorInterval     uint          `yaml:"cursor_interval"`      // Number of directory entries in between checkpoints of listing progress.
+	MaxProviders       uint          `yaml:"max_providers"`        // Maximum number of providers to store per item.
 }
 
 // CrawlerConfig returns component-specific configuration from the canonical central configuration.
@@ -0,0 +1,26 @@
+package config
+
+import (
+	"time"
+
+	"github.com/ipfs-search/ipfs-search/components/queue/dedup"
+)
+
+// Dedup contains configuration pertaining to deduplication of the hashes queue.
+type Dedup struct {
+	Backend    string        `yaml:"backend"`                   // Set of recently published resources: "memory", "redis" (shared) or "disabled".
+	Expiration time.Duration `yaml:"expiration"`                // Time after which a resource may be published again.
+	MaxLen     int           `yaml:"maxlen"`                    // Maximum amount of resources in the "memory" set.
+	RedisURL   string        `yaml:"redis_url" env:"REDIS_URL"` // URL of Redis server for the "redis" set.
+}
+
+// DedupConfig returns component-specific configuration from the canonical configuration.
+func (c *Config) DedupConfig() *dedup.Config {
+	cfg := dedup.Config(c.Dedup)
+	return &cfg
+}
+
+// DedupDefaults returns the defaults for component configuration, based on the component-specific configuration.
+func DedupDefaults() Dedup {
+	return Dedup(*dedup.DefaultConfig())
+}
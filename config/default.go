@@ -6,12 +6,19 @@ func Default() *Config {
         IPFSDefaults(),
         ElasticSearchDefaults(),
         AMQPDefaults(),
+        MemoryQueueDefaults(),
+        RedisQueueDefaults(),
+        DedupDefaults(),
         TikaDefaults(),
         InstrDefaults(),
         CrawlerDefaults(),
+        ProberDefaults(),
         SnifferDefaults(),
+        SnifferNodeDefaults(),
         IndexesDefaults(),
         QueuesDefaults(),
         WorkersDefaults(),
+        ConcurrencyDefaults(),
+        BackendsDefaults(),
     }
 }
@@ -7,9 +7,11 @@ type Index struct {
 
 // Indexes represents the various indexes we're using
 type Indexes struct {
-    Files       Index `yaml:"files"`
-    Directories Index `yaml:"directories"`
-    Invalids    Index `yaml:"invalids"`
+    Files          Index `yaml:"files"`
+    Directories    Index `yaml:"directories"`
+    DirectoryLinks Index `yaml:"directory_links"`
+    Invalids       Index `yaml:"invalids"`
+    Cursors        Index `yaml:"cursors"`
 }
 
 // IndexesDefaults returns the default indexes.
@@ -21,8 +23,14 @@ func IndexesDefaults() Indexes {
         Directories: Index{
             Name: "ipfs_directories",
         },
+        DirectoryLinks: Index{
+            Name: "ipfs_directory_links",
+        },
         Invalids: Index{
             Name: "ipfs_invalids",
         },
+        Cursors: Index{
+            Name: "ipfs_cursors",
+        },
     }
 }
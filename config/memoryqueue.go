@@ -0,0 +1,21 @@
+package config
+
+import (
+	"github.com/ipfs-search/ipfs-search/components/queue/memory"
+)
+
+// MemoryQueue contains configuration pertaining to the in-memory queue backend.
+type MemoryQueue struct {
+	Capacity int `yaml:"capacity"` // Maximum number of messages waiting in a queue, beyond which publishing blocks.
+}
+
+// MemoryQueueConfig returns component-specific configuration from the canonical configuration.
+func (c *Config) MemoryQueueConfig() *memory.Config {
+	cfg := memory.Config(c.MemoryQueue)
+	return &cfg
+}
+
+// MemoryQueueDefaults returns the defaults for component configuration, based on the component-specific configuration.
+func MemoryQueueDefaults() MemoryQueue {
+	return MemoryQueue(*memory.DefaultConfig())
+}
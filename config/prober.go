@@ -0,0 +1,26 @@
+package config
+
+import (
+	"github.com/ipfs-search/ipfs-search/components/prober"
+	"time"
+)
+
+// Prober contains configuration for a Prober.
+type Prober struct {
+	Interval     time.Duration `yaml:"interval"`      // Minimum time between probes of a document.
+	ScanInterval time.Duration `yaml:"scan_interval"` // Time to wait between scans for documents to probe.
+	ProbeTimeout time.Duration `yaml:"probe_timeout"` // Timeout for finding the providers of a document.
+	MaxProviders uint          `yaml:"max_providers"` // Maximum number of providers to find and store per document.
+	Workers      uint          `yaml:"workers"`       // Number of documents to probe concurrently.
+}
+
+// ProberConfig returns component-specific configuration from the canonical central configuration.
+func (c *Config) ProberConfig() *prober.Config {
+	cfg := prober.Config(c.Prober)
+	return &cfg
+}
+
+// ProberDefaults wraps the defaults from the component-specific configuration.
+func ProberDefaults() Prober {
+	return Prober(*prober.DefaultConfig())
+}
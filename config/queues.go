@@ -1,28 +1,42 @@
 package config
 
+import (
+	"time"
+
+	"github.com/ipfs-search/ipfs-search/components/queue"
+	"github.com/ipfs-search/ipfs-search/components/queue/amqp"
+)
+
 // Queue holds the configuration for a single Queue.
 type Queue struct {
-	Name string `yaml:"name` // Name of the Queue.
+	Name               string        `yaml:"name"`                                 // Name of the Queue.
+	MaxPriority        uint8         `yaml:"max_priority"`                         // Highest priority supported by the queue (AMQP only).
+	MessageTTL         time.Duration `yaml:"message_ttl" optional:"true"`          // Time after which messages expire; 0 for never (AMQP only).
+	MaxLength          int           `yaml:"max_length" optional:"true"`           // Maximum amount of messages in the queue; 0 for unlimited (AMQP only).
+	Overflow           string        `yaml:"overflow" optional:"true"`             // Behaviour at max_length: "drop-head" (default), "reject-publish" or "reject-publish-dlx" (AMQP only).
+	DeadLetterExchange string        `yaml:"dead_letter_exchange" optional:"true"` // Fanout exchange receiving rejected and expired messages, bound to a queue of the same name (AMQP only).
+}
+
+// AMQPConfig returns the declaration of the queue for AMQP.
+func (q Queue) AMQPConfig() *amqp.QueueConfig {
+	cfg := amqp.QueueConfig(q)
+	return &cfg
 }
 
 // Queues represents the various queues we're using
 type Queues struct {
-	Files       Queue `yaml:"files"`       // Resources known to be files.
-	Directories Queue `yaml:"directories"` // Resources known to be directories.
-	Hashes      Queue `yaml:"hashes"`      // Resources with unknown type.
+	Backend     string `yaml:"backend"`     // Queue implementation: "amqp", "redis" or "memory" (single process only).
+	Files       Queue  `yaml:"files"`       // Resources known to be files.
+	Directories Queue  `yaml:"directories"` // Resources known to be directories.
+	Hashes      Queue  `yaml:"hashes"`      // Resources with unknown type.
 }
 
 // QueuesDefaults returns the default queues.
 func QueuesDefaults() Queues {
 	return Queues{
-		Files: Queue{
-			Name: "files",
-		},
-		Directories: Queue{
-			Name: "directories",
-		},
-		Hashes: Queue{
-			Name: "hashes",
-		},
+		Backend:     queue.AMQPBackend,
+		Files:       Queue(*amqp.DefaultQueueConfig("files")),
+		Directories: Queue(*amqp.DefaultQueueConfig("directories")),
+		Hashes:      Queue(*amqp.DefaultQueueConfig("hashes")),
 	}
 }
@@ -0,0 +1,29 @@
+package config
+
+import (
+	"time"
+
+	"github.com/ipfs-search/ipfs-search/components/queue/redis"
+)
+
+// RedisQueue contains configuration pertaining to the Redis Streams queue backend.
+type RedisQueue struct {
+	URL          string        `yaml:"url" env:"REDIS_QUEUE_URL"` // URL of Redis server.
+	Group        string        `yaml:"group"`                     // Consumer group shared by crawlers.
+	Bands        int           `yaml:"bands"`                     // Number of streams per queue, approximating priorities.
+	MaxLen       int           `yaml:"max_len" optional:"true"`   // Approximate maximum length of a stream, dropping unconsumed items; 0 disables trimming.
+	PollInterval time.Duration `yaml:"poll_interval"`             // Time to wait in between polls of empty queues.
+	ClaimTimeout time.Duration `yaml:"claim_timeout"`             // Time after which unacknowledged messages are redelivered.
+	Encoding     string        `yaml:"encoding"`                  // "json" or "cbor", more compact; consumers accept both.
+}
+
+// RedisQueueConfig returns component-specific configuration from the canonical configuration.
+func (c *Config) RedisQueueConfig() *redis.Config {
+	cfg := redis.Config(c.RedisQueue)
+	return &cfg
+}
+
+// RedisQueueDefaults returns the defaults for component configuration, based on the component-specific configuration.
+func RedisQueueDefaults() RedisQueue {
+	return RedisQueue(*redis.DefaultConfig())
+}
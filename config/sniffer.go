@@ -1,25 +1,92 @@
 package config
 
 import (
+	"github.com/c2h5oh/datasize"
 	"github.com/ipfs-search/ipfs-search/components/sniffer"
+	filters "github.com/ipfs-search/ipfs-search/components/sniffer/providerfilters"
 	"time"
 )
 
+// Filter declares a provider filter in the sniffer's pipeline.
+type Filter struct {
+	Type   string         `yaml:"type"`             // Name under which the filter is registered, e.g. "cid".
+	Params filters.Params `yaml:"params,omitempty"` // Filter-specific parameters.
+}
+
 // Sniffer is configuration pertaining to the sniffer
 type Sniffer struct {
-	LastSeenExpiration time.Duration `yaml:"lastseen_expiration"`
-	LastSeenPruneLen   int           `yaml:"lastseen_prunelen"`
-	LoggerTimeout      time.Duration `yaml:"logger_timeout"`
-	BufferSize         uint          `yaml:"buffer_size"`
+	EventSource        string            `yaml:"event_source"`
+	LastSeenExpiration time.Duration     `yaml:"lastseen_expiration"`
+	LastSeenMaxLen     int               `yaml:"lastseen_maxlen"`
+	LastSeenBackend    string            `yaml:"lastseen_backend"`
+	LastSeenPath       string            `yaml:"lastseen_path"`
+	RedisURL           string            `yaml:"redis_url" env:"REDIS_URL"`
+	LoggerTimeout      time.Duration     `yaml:"logger_timeout"`
+	BufferSize         uint              `yaml:"buffer_size"`
+	FilterWorkers      uint              `yaml:"filter_workers"`
+	Filters            []Filter          `yaml:"filters"`
+	DebugAddress       string            `yaml:"debug_address" optional:"true"`
+	RestartBackoff     time.Duration     `yaml:"restart_backoff"`
+	MaxRestartBackoff  time.Duration     `yaml:"max_restart_backoff"`
+	SpoolDir           string            `yaml:"spool_dir" optional:"true"`
+	SpoolMaxSize       datasize.ByteSize `yaml:"spool_max_size"`
+	SpoolSegmentSize   datasize.ByteSize `yaml:"spool_segment_size"`
 }
 
 // SnifferConfig returns component-specific configuration from the canonical central configuration.
 func (c *Config) SnifferConfig() *sniffer.Config {
-	cfg := sniffer.Config(c.Sniffer)
-	return &cfg
+	s := c.Sniffer
+
+	specs := make([]filters.Spec, len(s.Filters))
+	for i, f := range s.Filters {
+		specs[i] = filters.Spec(f)
+	}
+
+	return &sniffer.Config{
+		EventSource:        s.EventSource,
+		LastSeenExpiration: s.LastSeenExpiration,
+		LastSeenMaxLen:     s.LastSeenMaxLen,
+		LastSeenBackend:    s.LastSeenBackend,
+		LastSeenPath:       s.LastSeenPath,
+		RedisURL:           s.RedisURL,
+		LoggerTimeout:      s.LoggerTimeout,
+		BufferSize:         s.BufferSize,
+		FilterWorkers:      s.FilterWorkers,
+		Filters:            specs,
+		DebugAddress:       s.DebugAddress,
+		RestartBackoff:     s.RestartBackoff,
+		MaxRestartBackoff:  s.MaxRestartBackoff,
+		SpoolDir:           s.SpoolDir,
+		SpoolMaxSize:       s.SpoolMaxSize,
+		SpoolSegmentSize:   s.SpoolSegmentSize,
+	}
 }
 
 // SnifferDefaults returns the defaults for component configuration, based on the component-specific configuration.
 func SnifferDefaults() Sniffer {
-	return Sniffer(*sniffer.DefaultConfig())
+	cfg := sniffer.DefaultConfig()
+
+	fs := make([]Filter, len(cfg.Filters))
+	for i, spec := range cfg.Filters {
+		fs[i] = Filter(spec)
+	}
+
+	return Sniffer{
+		EventSource:        cfg.EventSource,
+		LastSeenExpiration: cfg.LastSeenExpiration,
+		LastSeenMaxLen:     cfg.LastSeenMaxLen,
+		LastSeenBackend:    cfg.LastSeenBackend,
+		LastSeenPath:       cfg.LastSeenPath,
+		RedisURL:           cfg.RedisURL,
+		LoggerTimeout:      cfg.LoggerTimeout,
+		BufferSize:         cfg.BufferSize,
+		FilterWorkers:      cfg.FilterWorkers,
+		Filters:            fs,
+		DebugAddress:       cfg.DebugAddress,
+		RestartBackoff:     cfg.RestartBackoff,
+		MaxRestartBackoff:  cfg.MaxRestartBackoff,
+		SpoolDir:           cfg.SpoolDir,
+		SpoolMaxSize:       cfg.SpoolMaxSize,
+		SpoolSegmentSize:   cfg.SpoolSegmentSize,
+	}
 }
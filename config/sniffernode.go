@@ -0,0 +1,26 @@
+package config
+
+import (
+	"github.com/ipfs-search/ipfs-search/components/sniffer/node"
+	"time"
+)
+
+// SnifferNode is configuration pertaining to the DHT node embedded in the sniff command.
+type SnifferNode struct {
+	ListenAddresses []string      `yaml:"listen_addresses"`  // Multiaddresses to listen on.
+	BootstrapPeers  []string      `yaml:"bootstrap_peers"`   // Multiaddresses, including peer ID, of peers to join the DHT through.
+	ConnLowWater    int           `yaml:"conn_low_water"`    // Amount of connections to trim down to.
+	ConnHighWater   int           `yaml:"conn_high_water"`   // Amount of connections above which they are trimmed.
+	ConnGracePeriod time.Duration `yaml:"conn_grace_period"` // Time new connections are not subject to trimming.
+}
+
+// SnifferNodeConfig returns component-specific configuration from the canonical central configuration.
+func (c *Config) SnifferNodeConfig() *node.Config {
+	cfg := node.Config(c.SnifferNode)
+	return &cfg
+}
+
+// SnifferNodeDefaults wraps the defaults from the component-specific configuration.
+func SnifferNodeDefaults() SnifferNode {
+	return SnifferNode(*node.DefaultConfig())
+}
@@ -5,7 +5,8 @@ import (
 	"reflect"
 )
 
-// findZeroElements returns a slice of all (nested) struct fields with a zero value.
+// findZeroElements returns a slice of all (nested) struct fields with a zero value, except for fields tagged with
+// `optional:"true"`, for which the zero value disables a feature.
 func findZeroElements(s interface{}) []string {
 	var output []string
 
@@ -14,7 +15,12 @@ func findZeroElements(s interface{}) []string {
 	// Iterate over fields
 	for i := 0; i < v.NumField(); i++ {
 		f := v.Field(i)
-		name := v.Type().Field(i).Tag.Get("yaml")
+		tag := v.Type().Field(i).Tag
+		name := tag.Get("yaml")
+
+		if tag.Get("optional") == "true" {
+			continue
+		}
 
 		switch f.Kind() {
 		case reflect.Struct:
@@ -33,6 +39,11 @@ func findZeroElements(s interface{}) []string {
 			// 	v := f.MapIndex(e)
 			// 	findZeroElements(v.Interface())
 			// }
+		case reflect.Slice:
+			// Slice type, require non-zero length
+			if f.Len() == 0 {
+				output = append(output, name)
+			}
 		default:
 			if f.Interface() == reflect.Zero(f.Type()).Interface() {
 				output = append(output, name)
@@ -36,6 +36,50 @@ func main() {
 			Aliases: []string{"c"},
 			Usage:   "start crawler",
 			Action:  crawl,
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "sniff",
+					Usage: "Also run the sniffer with embedded DHT node in this process",
+				},
+			},
+		},
+		{
+			Name:    "probe",
+			Aliases: []string{"p"},
+			Usage:   "start availability prober",
+			Action:  probe,
+		},
+		{
+			Name:    "sniff",
+			Aliases: []string{"s"},
+			Usage:   "start sniffer with embedded DHT node",
+			Action:  sniff,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "record",
+					Usage: "Record sniffed events to `FILE`",
+				},
+			},
+		},
+		{
+			Name:    "sniffer",
+			Aliases: []string{},
+			Usage:   "sniffer tools",
+			Subcommands: []cli.Command{
+				{
+					Name:      "replay",
+					Usage:     "replay events recorded with `sniff --record` through the sniffer",
+					ArgsUsage: "FILE",
+					Action:    replaySniffed,
+					Flags: []cli.Flag{
+						cli.Float64Flag{
+							Name:  "speed",
+							Value: 1,
+							Usage: "Replay at `FACTOR` times the recorded rate, 0 for as fast as possible",
+						},
+					},
+				},
+			},
 		},
 		{
 			Name:    "config",
@@ -182,7 +226,75 @@ func crawl(c *cli.Context) error {
 		return cli.NewExitError(err.Error(), 1)
 	}
 
-	err = commands.Crawl(ctx, cfg)
+	err = commands.Crawl(ctx, cfg, c.Bool("sniff"))
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	return nil
+}
+
+func probe(c *cli.Context) error {
+	fmt.Println("Starting prober")
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Allow SIGTERM / Control-C quit through context
+	onSigTerm(cancel)
+
+	cfg, err := getConfig(c)
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	err = commands.Probe(ctx, cfg)
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	return nil
+}
+
+func sniff(c *cli.Context) error {
+	fmt.Println("Starting sniffer")
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Allow SIGTERM / Control-C quit through context
+	onSigTerm(cancel)
+
+	cfg, err := getConfig(c)
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	err = commands.Sniff(ctx, cfg, c.String("record"))
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	return nil
+}
+
+func replaySniffed(c *cli.Context) error {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Allow SIGTERM / Control-C quit through context
+	onSigTerm(cancel)
+
+	if c.NArg() != 1 {
+		return cli.NewExitError("Please supply one recording as argument.", 1)
+	}
+	path := c.Args().Get(0)
+
+	cfg, err := getConfig(c)
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	fmt.Printf("Replaying '%s'\n", path)
+
+	err = commands.ReplaySniffed(ctx, cfg, path, c.Float64("speed"))
 	if err != nil {
 		return cli.NewExitError(err.Error(), 1)
 	}
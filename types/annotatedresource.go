@@ -9,6 +9,7 @@ type AnnotatedResource struct {
 	*Resource
 	Reference `json:",omitempty"`
 	Stat      `json:",omitempty"`
+	Source    `json:",omitempty"`
 }
 
 // String returns the first reference or the URI.
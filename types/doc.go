@@ -1,2 +1,5 @@
 // Package types consists of common datatypes used by other components for internal representation, not for exporting or indexing.
+//
+// Fields carry integer CBOR keys, by which they are encoded in queued messages; keys are unique across types, as
+// embedded types share a map, and should never be reused.
 package types
@@ -26,4 +26,7 @@ var (
 
 	// ErrUnsupportedType is returned when the type of a resource is currently unsupported.
 	ErrUnsupportedType = WrappedError{ErrInvalidResource, "unsupported type"}
+
+	// ErrSymlink is returned for symlinks, which have no content of their own.
+	ErrSymlink = WrappedError{ErrInvalidResource, "symlink"}
 )
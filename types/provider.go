@@ -9,9 +9,9 @@ import (
 // Provider represents a Resource available from an identified provider at a particular moment.
 type Provider struct {
 	*Resource
-	Date        time.Time
-	Provider    string
-	SpanContext trace.SpanContext // SpanContext allows a Resource' processing to be traceable across the program
+	Date        time.Time         `cbor:"10,keyasint,omitempty"`
+	Provider    string            `cbor:"11,keyasint,omitempty"`
+	SpanContext trace.SpanContext `cbor:"-"` // SpanContext allows a Resource' processing to be traceable across the program
 }
 
 // String defaults to the URI
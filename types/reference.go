@@ -2,8 +2,9 @@ package types
 
 // Reference to indexed item
 type Reference struct {
-	Parent *Resource
-	Name   string
+	Parent *Resource `cbor:"3,keyasint,omitempty"`
+	Name   string    `cbor:"4,keyasint,omitempty"`
+	Target string    `json:",omitempty" cbor:"5,keyasint,omitempty"` // Target path of symbolic links, resolved relative to Parent when possible.
 }
 
 // String shows the name
@@ -6,8 +6,8 @@ import (
 
 // Resource represents a resource on the dweb.
 type Resource struct {
-	Protocol        // Protocol, e.g. IPFSProtocol
-	ID       string // Resource identifier (e.g. CID) for particular Protocol.
+	Protocol `cbor:"1,keyasint"` // Protocol, e.g. IPFSProtocol
+	ID       string              `cbor:"2,keyasint"` // Resource identifier (e.g. CID) for particular Protocol.
 }
 
 // URI returns a unique identifier for the resource.
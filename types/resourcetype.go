@@ -14,6 +14,8 @@ const (
 	DirectoryType
 	// PartialType represents *unreferenced* partial items.
 	PartialType
+	// SymlinkType is a symbolic link.
+	SymlinkType
 )
 
 func (t ResourceType) String() string {
@@ -28,6 +30,8 @@ func (t ResourceType) String() string {
 		return "directory"
 	case PartialType:
 		return "partial"
+	case SymlinkType:
+		return "symlink"
 	default:
 		panic("Invalid value for ResourceType.")
 	}
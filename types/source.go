@@ -0,0 +1,11 @@
+package types
+
+import (
+	"time"
+)
+
+// Source represents the provider a Resource was discovered from.
+type Source struct {
+	Provider     string    `json:",omitempty" cbor:"8,keyasint,omitempty"` // Peer ID of the provider.
+	LastProvided time.Time `cbor:"9,keyasint,omitempty"`                   // Time at which the provider was last seen providing the Resource.
+}
@@ -2,6 +2,6 @@ package types
 
 // Stat represents the type and size of a Resource.
 type Stat struct {
-	Type ResourceType
-	Size uint64
+	Type ResourceType `cbor:"6,keyasint,omitempty"`
+	Size uint64       `cbor:"7,keyasint,omitempty"`
 }
@@ -7,20 +7,30 @@ import (
 	"time"
 
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+
+	"github.com/ipfs-search/ipfs-search/utils/limit"
 )
 
-// GetHTTPClient initializes a HTTP client with OpenTelemetry transport for tracing.
-func GetHTTPClient(dialcontext func(ctx context.Context, network, address string) (net.Conn, error), maxConns int) *http.Client {
-	transport := otelhttp.NewTransport(&http.Transport{
+// GetHTTPClient initializes a HTTP client with OpenTelemetry transport for tracing, limiting requests to the given
+// backends.
+func GetHTTPClient(dialcontext func(ctx context.Context, network, address string) (net.Conn, error), maxConns int, backends ...*limit.Backend) *http.Client {
+	var transport http.RoundTripper = &http.Transport{
 		Proxy:               nil,
 		DialContext:         dialcontext,
 		ForceAttemptHTTP2:   false,
 		MaxIdleConns:        maxConns,
 		MaxIdleConnsPerHost: maxConns,
 		IdleConnTimeout:     90 * time.Second,
-	})
+	}
+
+	if len(backends) > 0 {
+		transport = &limit.Transport{
+			Base:     transport,
+			Backends: backends,
+		}
+	}
 
 	return &http.Client{
-		Transport: transport,
+		Transport: otelhttp.NewTransport(transport),
 	}
 }
@@ -0,0 +1,127 @@
+package limit
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// BackendConfig specifies the limits for calls to a backend.
+type BackendConfig struct {
+	MaxConcurrent int           // Maximum of concurrent calls.
+	MaxWait       time.Duration // Time to wait for a call to be allowed, before failing with ErrBulkheadFull.
+	Rate          float64       // Average amount of calls per second.
+	Burst         int           // Maximum amount of calls in excess of Rate.
+}
+
+// DefaultBackendConfig generates a default configuration for a backend allowing the given concurrency and rate.
+func DefaultBackendConfig(maxConcurrent int, rate float64) *BackendConfig {
+	return &BackendConfig{
+		MaxConcurrent: maxConcurrent,
+		MaxWait:       time.Minute,
+		Rate:          rate,
+		Burst:         maxConcurrent,
+	}
+}
+
+// Backend limits both the rate and concurrency of calls to a backend.
+type Backend struct {
+	name     string
+	rate     *Rate
+	bulkhead *Bulkhead
+}
+
+// NewBackend returns a Backend for the named backend, limiting calls according to cfg.
+func NewBackend(name string, cfg *BackendConfig) (*Backend, error) {
+	if cfg.MaxConcurrent < 1 || cfg.MaxWait <= 0 || cfg.Rate <= 0 || cfg.Burst < 1 {
+		return nil, fmt.Errorf("invalid limits for %s: %+v", name, cfg)
+	}
+
+	return &Backend{
+		name:     name,
+		rate:     NewRate(cfg.Rate, cfg.Burst),
+		bulkhead: NewBulkhead(cfg.MaxConcurrent, cfg.MaxWait),
+	}, nil
+}
+
+// String returns the name of the backend.
+func (b *Backend) String() string {
+	return b.name
+}
+
+// Acquire waits until a call is allowed, failing with ErrBulkheadFull when it is not within the maximum wait. Every
+// successful Acquire should be followed by Release.
+func (b *Backend) Acquire(ctx context.Context) error {
+	if err := b.rate.Wait(ctx); err != nil {
+		return err
+	}
+
+	if err := b.bulkhead.Acquire(ctx); err != nil {
+		if err == ErrBulkheadFull {
+			return fmt.Errorf("%w: %s", err, b.name)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// Release completes a call.
+func (b *Backend) Release() {
+	b.bulkhead.Release()
+}
+
+// Transport limits requests to the Backends, holding their capacity until response bodies are closed.
+type Transport struct {
+	Base     http.RoundTripper
+	Backends []*Backend
+}
+
+// release releases the first n Backends.
+func (t *Transport) release(n int) {
+	for _, b := range t.Backends[:n] {
+		b.Release()
+	}
+}
+
+// RoundTrip performs a request once all Backends allow it.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for i, b := range t.Backends {
+		if err := b.Acquire(req.Context()); err != nil {
+			t.release(i)
+			return nil, err
+		}
+	}
+
+	resp, err := t.Base.RoundTrip(req)
+	if err != nil {
+		t.release(len(t.Backends))
+		return nil, err
+	}
+
+	resp.Body = &body{
+		ReadCloser: resp.Body,
+		release:    func() { t.release(len(t.Backends)) },
+	}
+
+	return resp, nil
+}
+
+// body releases capacity once closed.
+type body struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *body) Close() error {
+	defer b.once.Do(b.release)
+	return b.ReadCloser.Close()
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ http.RoundTripper = &Transport{}
@@ -0,0 +1,117 @@
+package limit
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type BackendTestSuite struct {
+	suite.Suite
+	ctx    context.Context
+	cancel func()
+}
+
+func (s *BackendTestSuite) SetupTest() {
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+}
+
+func (s *BackendTestSuite) TearDownTest() {
+	s.cancel()
+}
+
+func (s *BackendTestSuite) TestBulkhead() {
+	b := NewBulkhead(1, 10*time.Millisecond)
+
+	s.NoError(b.Acquire(s.ctx))
+	s.Equal(ErrBulkheadFull, b.Acquire(s.ctx))
+
+	b.Release()
+	s.NoError(b.Acquire(s.ctx))
+}
+
+func (s *BackendTestSuite) TestRate() {
+	now := time.Now()
+
+	r := NewRate(10, 2)
+	r.now = func() time.Time { return now }
+	r.last = now
+
+	// Burst.
+	s.Zero(r.reserve())
+	s.Zero(r.reserve())
+
+	s.Equal(100*time.Millisecond, r.reserve())
+	s.Equal(200*time.Millisecond, r.reserve())
+
+	now = now.Add(time.Second)
+	s.Zero(r.reserve())
+}
+
+func (s *BackendTestSuite) TestRateCancel() {
+	r := NewRate(1, 1)
+	s.NoError(r.Wait(s.ctx))
+
+	ctx, cancel := context.WithTimeout(s.ctx, 10*time.Millisecond)
+	defer cancel()
+	s.Equal(context.DeadlineExceeded, r.Wait(ctx))
+
+	// The cancelled token has been returned.
+	s.InDelta(0, r.tokens, 0.1)
+}
+
+func (s *BackendTestSuite) TestInvalid() {
+	cfg := DefaultBackendConfig(10, 10)
+	cfg.Burst = 0
+
+	_, err := NewBackend("tika", cfg)
+	s.Error(err)
+}
+
+// TestTransport tests whether capacity is held until response bodies are closed.
+func (s *BackendTestSuite) TestTransport() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	cfg := DefaultBackendConfig(1, 1000)
+	cfg.MaxWait = 10 * time.Millisecond
+
+	backend, err := NewBackend("tika", cfg)
+	s.Require().NoError(err)
+
+	client := &http.Client{
+		Transport: &Transport{
+			Base:     http.DefaultTransport,
+			Backends: []*Backend{backend},
+		},
+	}
+
+	resp, err := client.Get(server.URL)
+	s.Require().NoError(err)
+
+	_, err = client.Get(server.URL)
+	s.True(errors.Is(err, ErrBulkheadFull))
+	s.Contains(err.Error(), "tika")
+
+	body, err := ioutil.ReadAll(resp.Body)
+	s.NoError(err)
+	s.Equal("ok", string(body))
+	s.NoError(resp.Body.Close())
+	s.NoError(resp.Body.Close())
+
+	resp, err = client.Get(server.URL)
+	s.Require().NoError(err)
+	s.NoError(resp.Body.Close())
+}
+
+func TestBackendTestSuite(t *testing.T) {
+	suite.Run(t, new(BackendTestSuite))
+}
@@ -0,0 +1,56 @@
+package limit
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrBulkheadFull is returned when no capacity becomes available within the maximum wait of a Bulkhead.
+var ErrBulkheadFull = errors.New("bulkhead full")
+
+// Bulkhead limits concurrent calls, so that a stalled backend holds no more than its share of callers. It is safe for
+// concurrent use.
+type Bulkhead struct {
+	slots   chan struct{}
+	maxWait time.Duration
+}
+
+// NewBulkhead returns a Bulkhead allowing max concurrent calls, failing calls which waited longer than maxWait.
+func NewBulkhead(max int, maxWait time.Duration) *Bulkhead {
+	if max < 1 {
+		panic("max should be at least 1")
+	}
+
+	return &Bulkhead{
+		slots:   make(chan struct{}, max),
+		maxWait: maxWait,
+	}
+}
+
+// Acquire waits for capacity, until the context is closed or returning ErrBulkheadFull after the maximum wait. Every
+// successful Acquire should be followed by Release.
+func (b *Bulkhead) Acquire(ctx context.Context) error {
+	select {
+	case b.slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	timer := time.NewTimer(b.maxWait)
+	defer timer.Stop()
+
+	select {
+	case b.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return ErrBulkheadFull
+	}
+}
+
+// Release completes a call.
+func (b *Bulkhead) Release() {
+	<-b.slots
+}
@@ -0,0 +1,52 @@
+package limit
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Controllers adjusting the limit of concurrent operations.
+const (
+	FixedController    = "fixed"    // Keep the limit at its maximum.
+	AdaptiveController = "adaptive" // Adjust the limit to observed latencies and error rates.
+)
+
+// ErrUnknownController is returned when an unknown controller is configured.
+var ErrUnknownController = errors.New("unknown concurrency controller")
+
+// Config specifies the configuration for Limiters.
+type Config struct {
+	Controller     string  // FixedController or AdaptiveController.
+	MinLimit       int     // Lowest limit the adaptive controller backs off to.
+	Tolerance      float64 // Factor by which latency may exceed its baseline before backing off.
+	ErrorTolerance float64 // Fraction by which the error rate may exceed its baseline before backing off.
+	Backoff        float64 // Factor by which the limit is multiplied when backing off.
+	Smoothing      float64 // Weight of every window of samples in the baselines.
+}
+
+// DefaultConfig generates a default configuration for Limiters.
+func DefaultConfig() *Config {
+	return &Config{
+		Controller:     AdaptiveController,
+		MinLimit:       1,
+		Tolerance:      2,
+		ErrorTolerance: 0.1,
+		Backoff:        0.75,
+		Smoothing:      0.05,
+	}
+}
+
+func (c *Config) validate() error {
+	switch c.Controller {
+	case FixedController, AdaptiveController:
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownController, c.Controller)
+	}
+
+	if c.MinLimit < 1 || c.Tolerance < 1 || c.ErrorTolerance < 0 ||
+		c.Backoff <= 0 || c.Backoff >= 1 || c.Smoothing <= 0 || c.Smoothing > 1 {
+		return fmt.Errorf("invalid concurrency configuration: %+v", c)
+	}
+
+	return nil
+}
@@ -0,0 +1,164 @@
+/*
+Package limit limits the amount of concurrent operations, adapting the limit to the load on the services they depend
+on.
+
+The adaptive controller collects the latency and outcome of the backend calls made by operations, as passed to Observe,
+in windows of as many samples as the current limit. Windows are compared with baselines, which are moving averages of previous windows: when the mean latency
+exceeds its baseline by a factor of Tolerance, or the error rate exceeds its baseline by ErrorTolerance, the limit is
+multiplied by Backoff. Otherwise, it is increased by one, up to its maximum (AIMD).
+*/
+package limit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// minWindow is the least amount of samples in a window, preventing low limits from reacting to single operations.
+const minWindow = 10
+
+// Limiter limits concurrent operations. It is safe for concurrent use.
+type Limiter struct {
+	cfg      *Config
+	max      int
+	onChange func(limit int)
+	notifyMu sync.Mutex // Serializes calls to onChange.
+
+	mu        sync.Mutex
+	limit     float64
+	inflight  int
+	available chan struct{} // Closed and replaced when an operation completes.
+
+	// Current window.
+	samples int
+	latency time.Duration
+	errors  int
+
+	// Baselines, set after the first window.
+	primed      bool
+	baseLatency float64
+	baseErrRate float64
+}
+
+// New returns a Limiter allowing at most max concurrent operations, calling onChange (when not nil) after adjusting
+// its limit.
+func New(cfg *Config, max int, onChange func(limit int)) (*Limiter, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	if max < cfg.MinLimit {
+		max = cfg.MinLimit
+	}
+
+	return &Limiter{
+		cfg:       cfg,
+		max:       max,
+		onChange:  onChange,
+		limit:     float64(max),
+		available: make(chan struct{}),
+	}, nil
+}
+
+// Limit returns the current limit.
+func (l *Limiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return int(l.limit)
+}
+
+// Acquire waits until an operation is allowed, until the context is closed. Every successful Acquire should be
+// followed by Release.
+func (l *Limiter) Acquire(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		if l.inflight < int(l.limit) {
+			l.inflight++
+			l.mu.Unlock()
+
+			return nil
+		}
+		available := l.available
+		l.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-available:
+		}
+	}
+}
+
+// Release completes an operation.
+func (l *Limiter) Release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inflight--
+	close(l.available)
+	l.available = make(chan struct{})
+}
+
+// Observe takes the latency and error of a backend call into account. Calls failing on the resource rather than the
+// backend, e.g. as it is not found, should not be observed with their error.
+func (l *Limiter) Observe(latency time.Duration, err error) {
+	l.mu.Lock()
+
+	l.samples++
+	l.latency += latency
+	if err != nil {
+		l.errors++
+	}
+
+	changed := false
+	if l.samples >= int(l.limit) && l.samples >= minWindow {
+		changed = l.adjust()
+	}
+
+	l.mu.Unlock()
+
+	if changed && l.onChange != nil {
+		l.notifyMu.Lock()
+		defer l.notifyMu.Unlock()
+
+		l.onChange(l.Limit())
+	}
+}
+
+// adjust adjusts the limit at the end of a window, returning whether it changed; callers should hold the lock.
+func (l *Limiter) adjust() bool {
+	n := float64(l.samples)
+	latency := float64(l.latency) / n
+	errRate := float64(l.errors) / n
+
+	l.samples, l.latency, l.errors = 0, 0, 0
+
+	if l.cfg.Controller == FixedController {
+		return false
+	}
+
+	if !l.primed {
+		l.baseLatency, l.baseErrRate = latency, errRate
+		l.primed = true
+
+		return false
+	}
+
+	old := int(l.limit)
+
+	if latency > l.cfg.Tolerance*l.baseLatency || errRate > l.baseErrRate+l.cfg.ErrorTolerance {
+		l.limit = math.Max(float64(l.cfg.MinLimit), math.Floor(l.limit*l.cfg.Backoff))
+	} else {
+		l.limit = math.Min(float64(l.max), l.limit+1)
+	}
+
+	// Baselines follow lasting changes in latency and errors, e.g. from a different workload.
+	s := l.cfg.Smoothing
+	l.baseLatency = (1-s)*l.baseLatency + s*latency
+	l.baseErrRate = (1-s)*l.baseErrRate + s*errRate
+
+	return int(l.limit) != old
+}
@@ -0,0 +1,175 @@
+package limit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type LimiterTestSuite struct {
+	suite.Suite
+	ctx     context.Context
+	cancel  func()
+	cfg     *Config
+	changes []int
+}
+
+func (s *LimiterTestSuite) SetupTest() {
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.cfg = DefaultConfig()
+	s.changes = nil
+}
+
+func (s *LimiterTestSuite) TearDownTest() {
+	s.cancel()
+}
+
+func (s *LimiterTestSuite) newLimiter(max int) *Limiter {
+	l, err := New(s.cfg, max, func(limit int) {
+		s.changes = append(s.changes, limit)
+	})
+	s.Require().NoError(err)
+
+	return l
+}
+
+// window completes a full window of operations with the given latency and error.
+func (s *LimiterTestSuite) window(l *Limiter, latency time.Duration, err error) {
+	n := l.Limit()
+	if n < minWindow {
+		n = minWindow
+	}
+
+	for i := 0; i < n; i++ {
+		s.Require().NoError(l.Acquire(s.ctx))
+		l.Observe(latency, err)
+		l.Release()
+	}
+}
+
+func (s *LimiterTestSuite) TestAcquire() {
+	l := s.newLimiter(2)
+
+	s.NoError(l.Acquire(s.ctx))
+	s.NoError(l.Acquire(s.ctx))
+
+	ctx, cancel := context.WithTimeout(s.ctx, 10*time.Millisecond)
+	defer cancel()
+	s.Equal(context.DeadlineExceeded, l.Acquire(ctx))
+
+	acquired := make(chan error)
+	go func() { acquired <- l.Acquire(s.ctx) }()
+
+	l.Release()
+	s.NoError(<-acquired)
+}
+
+func (s *LimiterTestSuite) TestLatencyBackoff() {
+	l := s.newLimiter(100)
+
+	s.window(l, time.Second, nil)
+	s.Equal(100, l.Limit())
+
+	s.window(l, 3*time.Second, nil)
+	s.Equal(75, l.Limit())
+	s.Equal([]int{75}, s.changes)
+}
+
+func (s *LimiterTestSuite) TestErrorBackoff() {
+	l := s.newLimiter(100)
+	err := errors.New("timeout")
+
+	s.window(l, time.Second, nil)
+
+	// Errors at the baseline rate are tolerated.
+	for i := 0; i < 100; i++ {
+		s.Require().NoError(l.Acquire(s.ctx))
+
+		if i%20 == 0 {
+			l.Observe(time.Second, err)
+		} else {
+			l.Observe(time.Second, nil)
+		}
+
+		l.Release()
+	}
+	s.Equal(100, l.Limit())
+
+	s.window(l, time.Second, err)
+	s.Equal(75, l.Limit())
+}
+
+func (s *LimiterTestSuite) TestIncrease() {
+	l := s.newLimiter(100)
+
+	s.window(l, time.Second, nil)
+	s.window(l, 3*time.Second, nil)
+	s.window(l, time.Second, nil)
+	s.window(l, time.Second, nil)
+
+	s.Equal(77, l.Limit())
+	s.Equal([]int{75, 76, 77}, s.changes)
+}
+
+func (s *LimiterTestSuite) TestMinLimit() {
+	s.cfg.MinLimit = 5
+	l := s.newLimiter(10)
+
+	s.window(l, time.Second, nil)
+
+	for i := 0; i < 10; i++ {
+		s.window(l, time.Hour, nil)
+	}
+
+	s.Equal(5, l.Limit())
+}
+
+func (s *LimiterTestSuite) TestFixed() {
+	s.cfg.Controller = FixedController
+	l := s.newLimiter(10)
+
+	s.window(l, time.Second, nil)
+	s.window(l, time.Hour, errors.New("timeout"))
+
+	s.Equal(10, l.Limit())
+	s.Empty(s.changes)
+}
+
+// TestObserveContext tests whether backend calls are observed by the Observer of their context.
+func (s *LimiterTestSuite) TestObserveContext() {
+	l := s.newLimiter(100)
+	ctx := WithObserver(s.ctx, l)
+
+	// Without an Observer, calls are not taken into account.
+	for i := 0; i < 100; i++ {
+		Observe(s.ctx, time.Now().Add(-time.Hour), nil)
+	}
+
+	for i := 0; i < 100; i++ {
+		Observe(ctx, time.Now(), nil)
+	}
+
+	for i := 0; i < 100; i++ {
+		Observe(ctx, time.Now().Add(-time.Hour), nil)
+	}
+
+	s.Equal(75, l.Limit())
+}
+
+func (s *LimiterTestSuite) TestInvalid() {
+	s.cfg.Controller = "gradient"
+	_, err := New(s.cfg, 10, nil)
+	s.True(errors.Is(err, ErrUnknownController))
+
+	s.cfg = DefaultConfig()
+	s.cfg.Backoff = 1
+	_, err = New(s.cfg, 10, nil)
+	s.Error(err)
+}
+
+func TestLimiterTestSuite(t *testing.T) {
+	suite.Run(t, new(LimiterTestSuite))
+}
@@ -0,0 +1,28 @@
+package limit
+
+import (
+	"context"
+	"time"
+)
+
+// Observer takes the latency and outcome of backend calls into account.
+type Observer interface {
+	Observe(latency time.Duration, err error)
+}
+
+type observerKey struct{}
+
+// WithObserver returns a copy of ctx in which backend calls are observed by o.
+func WithObserver(ctx context.Context, o Observer) context.Context {
+	return context.WithValue(ctx, observerKey{}, o)
+}
+
+// Observe passes the latency since start and the error of a backend call to the Observer of ctx, if any.
+func Observe(ctx context.Context, start time.Time, err error) {
+	if o, ok := ctx.Value(observerKey{}).(Observer); ok {
+		o.Observe(time.Since(start), err)
+	}
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ Observer = &Limiter{}
@@ -0,0 +1,78 @@
+package limit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Rate limits the rate of calls with a token bucket, allowing bursts. It is safe for concurrent use.
+type Rate struct {
+	perSecond float64
+	burst     float64
+	now       func() time.Time
+
+	mu     sync.Mutex
+	tokens float64 // Negative when calls are waiting.
+	last   time.Time
+}
+
+// NewRate returns a Rate allowing perSecond calls on average, in bursts of at most burst calls.
+func NewRate(perSecond float64, burst int) *Rate {
+	if perSecond <= 0 || burst < 1 {
+		panic("rate should be positive and burst at least 1")
+	}
+
+	return &Rate{
+		perSecond: perSecond,
+		burst:     float64(burst),
+		now:       time.Now,
+		tokens:    float64(burst),
+		last:      time.Now(),
+	}
+}
+
+// reserve takes a token, returning the time to wait before it is available.
+func (r *Rate) reserve() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	r.tokens = math.Min(r.burst, r.tokens+now.Sub(r.last).Seconds()*r.perSecond)
+	r.last = now
+
+	r.tokens--
+	if r.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-r.tokens / r.perSecond * float64(time.Second))
+}
+
+// cancel returns a token which has not been used.
+func (r *Rate) cancel() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tokens++
+}
+
+// Wait waits until a call is allowed, or until the context is closed.
+func (r *Rate) Wait(ctx context.Context) error {
+	delay := r.reserve()
+	if delay == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		r.cancel()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
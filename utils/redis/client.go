@@ -0,0 +1,218 @@
+// Package redis provides a minimal client for servers speaking the Redis protocol (RESP).
+package redis
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNil is returned by helper methods when the server returns a null reply.
+var ErrNil = errors.New("redis: nil reply")
+
+// DialContextFunc dials a network address.
+type DialContextFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+// Client is a minimal client for the Redis protocol. Commands are sent sequentially over a single connection,
+// which is (re)established lazily. It is concurrency-safe.
+type Client struct {
+	addr     string
+	password string
+	db       int
+	dial     DialContextFunc
+
+	mu   sync.Mutex
+	conn net.Conn
+	rw   *bufio.ReadWriter
+}
+
+// New returns a Client for a URL of the form redis://[:password@]host:port[/db].
+func New(rawURL string, dial DialContextFunc) (*Client, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Scheme != "redis" {
+		return nil, fmt.Errorf("unsupported scheme in redis URL: %s", rawURL)
+	}
+
+	c := &Client{
+		addr: u.Host,
+		dial: dial,
+	}
+
+	if c.dial == nil {
+		var d net.Dialer
+		c.dial = d.DialContext
+	}
+
+	if !strings.Contains(c.addr, ":") {
+		c.addr = net.JoinHostPort(c.addr, "6379")
+	}
+
+	if u.User != nil {
+		c.password, _ = u.User.Password()
+	}
+
+	if db := strings.TrimPrefix(u.Path, "/"); db != "" {
+		if c.db, err = strconv.Atoi(db); err != nil {
+			return nil, fmt.Errorf("invalid database in redis URL: %s", rawURL)
+		}
+	}
+
+	return c, nil
+}
+
+func (c *Client) roundTrip(args ...string) (interface{}, error) {
+	if err := WriteCommand(c.rw.Writer, args...); err != nil {
+		return nil, err
+	}
+
+	return ReadReply(c.rw.Reader)
+}
+
+// connect establishes a connection, authenticating and selecting the database when required.
+func (c *Client) connect(ctx context.Context) error {
+	conn, err := c.dial(ctx, "tcp", c.addr)
+	if err != nil {
+		return err
+	}
+
+	c.conn = conn
+	c.rw = bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
+
+	// Bound the setup round trips by the context as well.
+	if err := c.setDeadline(ctx); err != nil {
+		c.reset()
+		return err
+	}
+
+	var setup [][]string
+
+	if c.password != "" {
+		setup = append(setup, []string{"AUTH", c.password})
+	}
+
+	if c.db != 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(c.db)})
+	}
+
+	for _, args := range setup {
+		reply, err := c.roundTrip(args...)
+		if e, ok := reply.(Error); ok && err == nil {
+			err = e
+		}
+
+		if err != nil {
+			c.reset()
+			return fmt.Errorf("redis %s: %w", args[0], err)
+		}
+	}
+
+	return nil
+}
+
+// setDeadline sets the deadline of the connection to that of the context, if any.
+func (c *Client) setDeadline(ctx context.Context) error {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Time{}
+	}
+
+	return c.conn.SetDeadline(deadline)
+}
+
+func (c *Client) reset() {
+	if c.conn != nil {
+		c.conn.Close()
+	}
+
+	c.conn = nil
+	c.rw = nil
+}
+
+// Do sends a command and returns its reply (see ReadReply). Error replies are returned as error.
+func (c *Client) Do(ctx context.Context, args ...string) (interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		if err := c.connect(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := c.setDeadline(ctx); err != nil {
+		c.reset()
+		return nil, err
+	}
+
+	reply, err := c.roundTrip(args...)
+	if err != nil {
+		// Connection state is unknown; reconnect on next command.
+		c.reset()
+		return nil, err
+	}
+
+	if err, ok := reply.(Error); ok {
+		return nil, err
+	}
+
+	return reply, nil
+}
+
+// String sends a command and returns its reply as string, or ErrNil on null replies.
+func (c *Client) String(ctx context.Context, args ...string) (string, error) {
+	reply, err := c.Do(ctx, args...)
+	if err != nil {
+		return "", err
+	}
+
+	switch r := reply.(type) {
+	case nil:
+		return "", ErrNil
+	case string:
+		return r, nil
+	case int64:
+		return strconv.FormatInt(r, 10), nil
+	default:
+		return "", fmt.Errorf("%w: unexpected reply %T", errProtocol, reply)
+	}
+}
+
+// Int sends a command and returns its reply as integer.
+func (c *Client) Int(ctx context.Context, args ...string) (int64, error) {
+	reply, err := c.Do(ctx, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	switch r := reply.(type) {
+	case nil:
+		return 0, ErrNil
+	case int64:
+		return r, nil
+	case string:
+		return strconv.ParseInt(r, 10, 64)
+	default:
+		return 0, fmt.Errorf("%w: unexpected reply %T", errProtocol, reply)
+	}
+}
+
+// Close closes the connection, if any.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.reset()
+
+	return nil
+}
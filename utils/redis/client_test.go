@@ -0,0 +1,132 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/utils/redis/redistest"
+)
+
+type ClientTestSuite struct {
+	suite.Suite
+
+	ctx    context.Context
+	server *redistest.Server
+	c      *Client
+}
+
+func (s *ClientTestSuite) SetupTest() {
+	var err error
+
+	s.ctx = context.Background()
+
+	s.server, err = redistest.NewServer()
+	s.Require().NoError(err)
+
+	s.c, err = New(s.server.URL(), nil)
+	s.Require().NoError(err)
+}
+
+func (s *ClientTestSuite) TearDownTest() {
+	s.c.Close()
+	s.server.Close()
+}
+
+func (s *ClientTestSuite) TestSetGet() {
+	reply, err := s.c.String(s.ctx, "SET", "key", "value with\r\nnewline")
+	s.NoError(err)
+	s.Equal("OK", reply)
+
+	value, err := s.c.String(s.ctx, "GET", "key")
+	s.NoError(err)
+	s.Equal("value with\r\nnewline", value)
+}
+
+func (s *ClientTestSuite) TestNil() {
+	_, err := s.c.String(s.ctx, "GET", "missing")
+	s.True(errors.Is(err, ErrNil))
+}
+
+func (s *ClientTestSuite) TestInt() {
+	s.c.Do(s.ctx, "SET", "key", "value")
+
+	n, err := s.c.Int(s.ctx, "EXISTS", "key", "missing")
+	s.NoError(err)
+	s.Equal(int64(1), n)
+}
+
+func (s *ClientTestSuite) TestErrorReply() {
+	_, err := s.c.Do(s.ctx, "BOGUS")
+
+	var redisErr Error
+	s.True(errors.As(err, &redisErr))
+}
+
+func (s *ClientTestSuite) TestReconnect() {
+	_, err := s.c.Do(s.ctx, "PING")
+	s.NoError(err)
+
+	// Restarting the server breaks the connection
+	s.server.Close()
+	s.server, err = redistest.NewServer()
+	s.Require().NoError(err)
+
+	s.c.addr = s.server.URL()[len("redis://"):]
+
+	// First command fails on the broken connection, the next reconnects.
+	if _, err = s.c.Do(s.ctx, "PING"); err != nil {
+		_, err = s.c.Do(s.ctx, "PING")
+	}
+
+	s.NoError(err)
+}
+
+func (s *ClientTestSuite) TestSetupDeadline() {
+	// Server accepting connections without ever replying.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	s.Require().NoError(err)
+	defer l.Close()
+
+	go func() {
+		conn, err := l.Accept()
+		if err == nil {
+			defer conn.Close()
+			time.Sleep(time.Second)
+		}
+	}()
+
+	c, err := New("redis://:secret@"+l.Addr().String(), nil)
+	s.Require().NoError(err)
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(s.ctx, 50*time.Millisecond)
+	defer cancel()
+
+	started := time.Now()
+	_, err = c.Do(ctx, "PING")
+
+	// AUTH is bound by the context's deadline.
+	s.Error(err)
+	s.Less(int64(time.Since(started)), int64(500*time.Millisecond))
+}
+
+func (s *ClientTestSuite) TestURL() {
+	c, err := New("redis://:secret@example.com/2", nil)
+
+	s.NoError(err)
+	s.Equal("example.com:6379", c.addr)
+	s.Equal("secret", c.password)
+	s.Equal(2, c.db)
+
+	_, err = New("http://example.com", nil)
+	s.Error(err)
+}
+
+func TestClientTestSuite(t *testing.T) {
+	suite.Run(t, new(ClientTestSuite))
+}
@@ -0,0 +1,277 @@
+// Package redistest provides an in-process stand-in for a Redis server, for use in tests.
+package redistest
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type entry struct {
+	value   string
+	expires time.Time
+}
+
+// Server is a minimal in-memory server implementing a subset of the Redis protocol:
+// PING, AUTH, SELECT, GET, SET (with PX and NX), DEL and EXISTS, as well as the stream commands listed in streams.go.
+type Server struct {
+	listener net.Listener
+
+	mu      sync.Mutex
+	data    map[string]entry
+	streams map[string]*stream
+	conns   map[net.Conn]struct{}
+	now     func() time.Time
+}
+
+// NewServer starts a Server on a random local port.
+func NewServer() (*Server, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		listener: l,
+		data:     make(map[string]entry),
+		streams:  make(map[string]*stream),
+		conns:    make(map[net.Conn]struct{}),
+		now:      time.Now,
+	}
+
+	go s.serve()
+
+	return s, nil
+}
+
+// URL returns the redis:// URL of the server.
+func (s *Server) URL() string {
+	return fmt.Sprintf("redis://%s", s.listener.Addr())
+}
+
+// Close stops the server, closing open connections.
+func (s *Server) Close() error {
+	err := s.listener.Close()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for conn := range s.conns {
+		conn.Close()
+	}
+
+	return err
+}
+
+// Len returns the number of non-expired keys.
+func (s *Server) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := 0
+	for k := range s.data {
+		if _, ok := s.get(k); ok {
+			n++
+		}
+	}
+
+	return n
+}
+
+func (s *Server) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+
+		go s.handle(conn)
+	}
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]string, n)
+	for i := range args {
+		header, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+
+		size, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(header, "$")))
+		if err != nil {
+			return nil, err
+		}
+
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+
+		args[i] = string(buf[:size])
+	}
+
+	return args, nil
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer func() {
+		conn.Close()
+
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+	}()
+
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+
+		w.WriteString(s.exec(args))
+
+		if err := w.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// get returns the value for a key, expiring it if necessary; the lock must be held.
+func (s *Server) get(key string) (string, bool) {
+	e, ok := s.data[key]
+	if !ok {
+		return "", false
+	}
+
+	if !e.expires.IsZero() && !s.now().Before(e.expires) {
+		delete(s.data, key)
+		return "", false
+	}
+
+	return e.value, true
+}
+
+func bulk(v string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(v), v)
+}
+
+func integer(n int) string {
+	return fmt.Sprintf(":%d\r\n", n)
+}
+
+const (
+	ok        = "+OK\r\n"
+	null      = "$-1\r\n"
+	syntaxErr = "-ERR syntax error\r\n"
+)
+
+func (s *Server) exec(args []string) string {
+	if len(args) == 0 {
+		return "-ERR empty command\r\n"
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch cmd := strings.ToUpper(args[0]); cmd {
+	case "PING":
+		return "+PONG\r\n"
+
+	case "AUTH", "SELECT":
+		return ok
+
+	case "GET":
+		if len(args) != 2 {
+			return syntaxErr
+		}
+
+		if v, found := s.get(args[1]); found {
+			return bulk(v)
+		}
+
+		return null
+
+	case "SET":
+		return s.set(args[1:])
+
+	case "DEL", "EXISTS":
+		n := 0
+		for _, k := range args[1:] {
+			if _, found := s.get(k); found {
+				n++
+				if cmd == "DEL" {
+					delete(s.data, k)
+				}
+			}
+		}
+
+		return integer(n)
+
+	default:
+		if reply, found := s.execStream(cmd, args[1:]); found {
+			return reply
+		}
+
+		return fmt.Sprintf("-ERR unknown command '%s'\r\n", args[0])
+	}
+}
+
+func (s *Server) set(args []string) string {
+	if len(args) < 2 {
+		return syntaxErr
+	}
+
+	e := entry{value: args[1]}
+	nx := false
+
+	for i := 2; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case "NX":
+			nx = true
+		case "PX":
+			if i+1 >= len(args) {
+				return syntaxErr
+			}
+
+			ms, err := strconv.Atoi(args[i+1])
+			if err != nil || ms <= 0 {
+				return "-ERR invalid expire time in 'set' command\r\n"
+			}
+
+			e.expires = s.now().Add(time.Duration(ms) * time.Millisecond)
+			i++
+		default:
+			return syntaxErr
+		}
+	}
+
+	if _, found := s.get(args[0]); found && nx {
+		return null
+	}
+
+	s.data[args[0]] = e
+
+	return ok
+}
@@ -0,0 +1,330 @@
+package redistest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Stream commands: XADD (MAXLEN is accepted but ignored, as approximate trimming allows), XLEN,
+// XGROUP CREATE (with MKSTREAM), XREADGROUP (new entries only, BLOCK is ignored), XACK and XAUTOCLAIM.
+
+type streamEntry struct {
+	id     string
+	fields []string
+}
+
+type pendingEntry struct {
+	consumer  string
+	delivered time.Time
+}
+
+type group struct {
+	next    int // Index of the next entry to be delivered.
+	pending map[string]*pendingEntry
+}
+
+type stream struct {
+	entries []streamEntry
+	seq     int
+	groups  map[string]*group
+}
+
+func newStream() *stream {
+	return &stream{
+		groups: make(map[string]*group),
+	}
+}
+
+// StreamLen returns the amount of entries in a stream.
+func (s *Server) StreamLen(key string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if st, ok := s.streams[key]; ok {
+		return len(st.entries)
+	}
+
+	return 0
+}
+
+// Pending returns the amount of entries delivered to a group's consumers which have not been acknowledged.
+func (s *Server) Pending(key, group string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if st, ok := s.streams[key]; ok {
+		if g, ok := st.groups[group]; ok {
+			return len(g.pending)
+		}
+	}
+
+	return 0
+}
+
+func array(elems ...string) string {
+	return fmt.Sprintf("*%d\r\n%s", len(elems), strings.Join(elems, ""))
+}
+
+func (e *streamEntry) encode() string {
+	fields := make([]string, len(e.fields))
+	for i, f := range e.fields {
+		fields[i] = bulk(f)
+	}
+
+	return array(bulk(e.id), array(fields...))
+}
+
+// execStream executes stream commands, returning whether cmd is one; the lock must be held.
+func (s *Server) execStream(cmd string, args []string) (string, bool) {
+	switch cmd {
+	case "XADD":
+		return s.xadd(args), true
+	case "XLEN":
+		if len(args) != 1 {
+			return syntaxErr, true
+		}
+
+		n := 0
+		if st, ok := s.streams[args[0]]; ok {
+			n = len(st.entries)
+		}
+
+		return integer(n), true
+	case "XGROUP":
+		return s.xgroup(args), true
+	case "XREADGROUP":
+		return s.xreadgroup(args), true
+	case "XACK":
+		return s.xack(args), true
+	case "XAUTOCLAIM":
+		return s.xautoclaim(args), true
+	default:
+		return "", false
+	}
+}
+
+func (s *Server) xadd(args []string) string {
+	if len(args) < 1 {
+		return syntaxErr
+	}
+
+	key, args := args[0], args[1:]
+
+	if len(args) > 0 && strings.ToUpper(args[0]) == "MAXLEN" {
+		args = args[1:]
+		if len(args) > 0 && (args[0] == "~" || args[0] == "=") {
+			args = args[1:]
+		}
+		if len(args) == 0 {
+			return syntaxErr
+		}
+		args = args[1:]
+	}
+
+	if len(args) < 3 || args[0] != "*" || len(args[1:])%2 != 0 {
+		return syntaxErr
+	}
+
+	st, ok := s.streams[key]
+	if !ok {
+		st = newStream()
+		s.streams[key] = st
+	}
+
+	st.seq++
+	e := streamEntry{
+		id:     fmt.Sprintf("%d-0", st.seq),
+		fields: append([]string(nil), args[1:]...),
+	}
+	st.entries = append(st.entries, e)
+
+	return bulk(e.id)
+}
+
+func (s *Server) xgroup(args []string) string {
+	if len(args) < 4 || strings.ToUpper(args[0]) != "CREATE" {
+		return syntaxErr
+	}
+
+	key, name, id := args[1], args[2], args[3]
+
+	st, exists := s.streams[key]
+	if !exists {
+		if len(args) < 5 || strings.ToUpper(args[4]) != "MKSTREAM" {
+			return "-ERR The XGROUP subcommand requires the key to exist\r\n"
+		}
+
+		st = newStream()
+		s.streams[key] = st
+	}
+
+	if _, found := st.groups[name]; found {
+		return "-BUSYGROUP Consumer Group name already exists\r\n"
+	}
+
+	g := &group{pending: make(map[string]*pendingEntry)}
+
+	switch id {
+	case "0":
+	case "$":
+		g.next = len(st.entries)
+	default:
+		return "-ERR only 0 and $ are supported\r\n"
+	}
+
+	st.groups[name] = g
+
+	return ok
+}
+
+// parseCount parses an optional COUNT argument at args[i], returning the count and the index after it.
+func parseCount(args []string, i int) (int, int, bool) {
+	if i+1 < len(args) && strings.ToUpper(args[i]) == "COUNT" {
+		n, err := strconv.Atoi(args[i+1])
+		if err != nil || n < 1 {
+			return 0, i, false
+		}
+
+		return n, i + 2, true
+	}
+
+	return -1, i, true
+}
+
+func (s *Server) xreadgroup(args []string) string {
+	if len(args) < 3 || strings.ToUpper(args[0]) != "GROUP" {
+		return syntaxErr
+	}
+
+	name, consumer := args[1], args[2]
+
+	count, i, valid := parseCount(args, 3)
+	if !valid {
+		return syntaxErr
+	}
+
+	if i+1 < len(args) && strings.ToUpper(args[i]) == "BLOCK" {
+		i += 2
+	}
+
+	if i >= len(args) || strings.ToUpper(args[i]) != "STREAMS" {
+		return syntaxErr
+	}
+
+	streams := args[i+1:]
+	if len(streams) == 0 || len(streams)%2 != 0 {
+		return syntaxErr
+	}
+
+	keys, ids := streams[:len(streams)/2], streams[len(streams)/2:]
+
+	var results []string
+
+	for j, key := range keys {
+		if ids[j] != ">" {
+			return "-ERR only > is supported\r\n"
+		}
+
+		st, ok := s.streams[key]
+		if !ok {
+			return fmt.Sprintf("-NOGROUP No such key '%s' or consumer group '%s'\r\n", key, name)
+		}
+
+		g, ok := st.groups[name]
+		if !ok {
+			return fmt.Sprintf("-NOGROUP No such key '%s' or consumer group '%s'\r\n", key, name)
+		}
+
+		var entries []string
+		for ; g.next < len(st.entries) && (count < 0 || len(entries) < count); g.next++ {
+			e := st.entries[g.next]
+			g.pending[e.id] = &pendingEntry{consumer: consumer, delivered: s.now()}
+			entries = append(entries, e.encode())
+		}
+
+		if len(entries) > 0 {
+			results = append(results, array(bulk(key), array(entries...)))
+		}
+	}
+
+	if len(results) == 0 {
+		return "*-1\r\n"
+	}
+
+	return array(results...)
+}
+
+func (s *Server) xack(args []string) string {
+	if len(args) < 3 {
+		return syntaxErr
+	}
+
+	n := 0
+
+	if st, ok := s.streams[args[0]]; ok {
+		if g, ok := st.groups[args[1]]; ok {
+			for _, id := range args[2:] {
+				if _, pending := g.pending[id]; pending {
+					delete(g.pending, id)
+					n++
+				}
+			}
+		}
+	}
+
+	return integer(n)
+}
+
+func (s *Server) xautoclaim(args []string) string {
+	if len(args) < 5 {
+		return syntaxErr
+	}
+
+	key, name, consumer := args[0], args[1], args[2]
+
+	minIdle, err := strconv.Atoi(args[3])
+	if err != nil || minIdle < 0 {
+		return syntaxErr
+	}
+
+	count, _, valid := parseCount(args, 5)
+	if !valid {
+		return syntaxErr
+	}
+	if count < 0 {
+		count = 100
+	}
+
+	st, ok := s.streams[key]
+	if !ok {
+		return fmt.Sprintf("-NOGROUP No such key '%s' or consumer group '%s'\r\n", key, name)
+	}
+
+	g, ok := st.groups[name]
+	if !ok {
+		return fmt.Sprintf("-NOGROUP No such key '%s' or consumer group '%s'\r\n", key, name)
+	}
+
+	var claimed []string
+
+	// Entries are claimed in stream order.
+	for _, e := range st.entries {
+		if len(claimed) >= count {
+			break
+		}
+
+		p, pending := g.pending[e.id]
+		if !pending || s.now().Sub(p.delivered) < time.Duration(minIdle)*time.Millisecond {
+			continue
+		}
+
+		p.consumer = consumer
+		p.delivered = s.now()
+
+		claimed = append(claimed, e.encode())
+	}
+
+	return array(bulk("0-0"), array(claimed...), array())
+}
@@ -0,0 +1,115 @@
+package redis
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Error is an error reply from the server.
+type Error string
+
+func (e Error) Error() string {
+	return string(e)
+}
+
+var errProtocol = errors.New("redis protocol error")
+
+// WriteCommand writes a command as an array of bulk strings.
+func WriteCommand(w *bufio.Writer, args ...string) error {
+	if _, err := fmt.Fprintf(w, "*%d\r\n", len(args)); err != nil {
+		return err
+	}
+
+	for _, arg := range args {
+		if _, err := fmt.Fprintf(w, "$%d\r\n%s\r\n", len(arg), arg); err != nil {
+			return err
+		}
+	}
+
+	return w.Flush()
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return "", fmt.Errorf("%w: malformed line %q", errProtocol, line)
+	}
+
+	return line[:len(line)-2], nil
+}
+
+// ReadReply reads a single reply, returning a string for simple and bulk strings, an int64 for integers,
+// []interface{} for arrays, nil for null replies and an Error for error replies.
+func ReadReply(r *bufio.Reader) (interface{}, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if line == "" {
+		return nil, fmt.Errorf("%w: empty line", errProtocol)
+	}
+
+	payload := line[1:]
+
+	switch line[0] {
+	case '+':
+		return payload, nil
+
+	case '-':
+		return Error(payload), nil
+
+	case ':':
+		n, err := strconv.ParseInt(payload, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errProtocol, err)
+		}
+		return n, nil
+
+	case '$':
+		n, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errProtocol, err)
+		}
+
+		if n < 0 {
+			return nil, nil
+		}
+
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+
+		return string(buf[:n]), nil
+
+	case '*':
+		n, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errProtocol, err)
+		}
+
+		if n < 0 {
+			return nil, nil
+		}
+
+		values := make([]interface{}, n)
+		for i := range values {
+			if values[i], err = ReadReply(r); err != nil {
+				return nil, err
+			}
+		}
+
+		return values, nil
+
+	default:
+		return nil, fmt.Errorf("%w: unexpected reply type %q", errProtocol, line[0])
+	}
+}